@@ -0,0 +1,606 @@
+package grabitsh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type AnsibleInventory struct {
+	File string `json:"file"`
+	// Group name to its hosts and child groups
+	Hosts    map[string][]string `json:"hosts"`
+	Children map[string][]string `json:"children,omitempty"`
+}
+
+type AnsiblePlay struct {
+	Name   string   `json:"name,omitempty"`
+	Hosts  string   `json:"hosts"`
+	Roles  []string `json:"roles,omitempty"`
+	Tasks  int      `json:"tasks"`
+	Become bool     `json:"become,omitempty"`
+}
+
+type AnsiblePlaybook struct {
+	File    string        `json:"file"`
+	Plays   []AnsiblePlay `json:"plays,omitempty"`
+	Imports []string      `json:"imports,omitempty"`
+}
+
+type AnsibleRole struct {
+	Name         string   `json:"name"`
+	Dir          string   `json:"dir"`
+	Tasks        []string `json:"tasks,omitempty"`
+	Modules      []string `json:"modules,omitempty"`
+	Handlers     []string `json:"handlers,omitempty"`
+	Defaults     []string `json:"defaults,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+type AnsibleRequirement struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	File    string `json:"file"`
+}
+
+type AnsibleSecret struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Key  string `json:"key"`
+}
+
+type AnsibleProject struct {
+	Config       []string             `json:"config,omitempty"`
+	Inventories  []AnsibleInventory   `json:"inventories,omitempty"`
+	Playbooks    []AnsiblePlaybook    `json:"playbooks,omitempty"`
+	Roles        []*AnsibleRole       `json:"roles,omitempty"`
+	Requirements []AnsibleRequirement `json:"requirements,omitempty"`
+	VaultFiles   []string             `json:"vault_files,omitempty"`
+	Secrets      []AnsibleSecret      `json:"secrets,omitempty"`
+	Errors       []string             `json:"errors,omitempty"`
+}
+
+var (
+	ansibleRolePathRegex = regexp.MustCompile(`(?:^|/)roles/([^/]+)/(tasks|handlers|defaults|vars|meta)/[^/]+\.ya?ml$`)
+	ansibleVarsDirRegex  = regexp.MustCompile(`(?:^|/)(group_vars|host_vars)/`)
+	ansibleInventoryDirs = regexp.MustCompile(`(?:^|/)(inventory|inventories)/`)
+
+	// Task keywords, anything else on a task is the module being called
+	ansibleTaskKeywords = map[string]bool{
+		"name": true, "when": true, "become": true, "become_user": true, "become_method": true, "tags": true, "register": true,
+		"loop": true, "loop_control": true, "notify": true, "vars": true, "ignore_errors": true, "changed_when": true,
+		"failed_when": true, "delegate_to": true, "run_once": true, "no_log": true, "block": true, "rescue": true,
+		"always": true, "environment": true, "until": true, "retries": true, "delay": true, "args": true, "check_mode": true,
+		"diff": true, "listen": true, "any_errors_fatal": true, "collections": true, "connection": true, "throttle": true,
+		"timeout": true, "async": true, "poll": true, "local_action": true, "module_defaults": true,
+	}
+)
+
+func isAnsibleVault(content []byte) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(content)), "$ANSIBLE_VAULT;")
+}
+
+// Load every Ansible artifact in the repository, or nil when the repository does not use Ansible
+func loadAnsibleProject() *AnsibleProject {
+	project := &AnsibleProject{}
+	roles := make(map[string]*AnsibleRole)
+	inventoryPaths := make(map[string]bool)
+	var varsFiles []string
+
+	files := findRepositoryFiles(func(name string) bool { return true })
+	for _, file := range files {
+		if filepath.Base(file) == "ansible.cfg" {
+			project.loadConfig(file, inventoryPaths)
+		}
+	}
+
+	for _, file := range files {
+		slashed := filepath.ToSlash(file)
+		name := path.Base(slashed)
+		isYAML := isYAMLFileName(name)
+
+		if match := ansibleRolePathRegex.FindStringSubmatch(slashed); match != nil {
+			rooted := "/" + slashed
+			dir := rooted[1 : strings.LastIndex(rooted, "/roles/"+match[1]+"/")+len("/roles/")+len(match[1])]
+			role := roles[dir]
+			if role == nil {
+				role = &AnsibleRole{Name: match[1], Dir: dir}
+				roles[dir] = role
+			}
+			if err := role.addFile(file, match[2]); err != nil {
+				project.Errors = append(project.Errors, err.Error())
+			}
+			if match[2] == "defaults" || match[2] == "vars" {
+				varsFiles = append(varsFiles, file)
+			}
+			continue
+		}
+
+		switch {
+		case ansibleVarsDirRegex.MatchString(slashed):
+			varsFiles = append(varsFiles, file)
+		case name == "requirements.yml" || name == "requirements.yaml":
+			project.loadRequirements(file)
+		case inventoryPaths[file] || isAnsibleInventoryName(slashed):
+			if inventory, secrets, err := parseAnsibleInventory(file); err == nil && len(inventory.Hosts)+len(inventory.Children) > 0 {
+				project.Inventories = append(project.Inventories, inventory)
+				project.Secrets = append(project.Secrets, secrets...)
+			}
+		case isYAML:
+			project.loadPlaybook(file)
+		}
+	}
+
+	for _, file := range varsFiles {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if isAnsibleVault(content) {
+			project.VaultFiles = append(project.VaultFiles, file)
+			continue
+		}
+		var vars interface{}
+		if yaml.Unmarshal(content, &vars) == nil {
+			project.Secrets = append(project.Secrets, ansibleSecrets(file, string(content), yamlMap(normalizeYAML(vars)))...)
+		}
+	}
+
+	sort.Strings(project.VaultFiles)
+	for _, dir := range sortedKeys(roles) {
+		project.Roles = append(project.Roles, roles[dir])
+	}
+	if len(project.Config) == 0 && len(project.Roles) == 0 && len(project.Playbooks) == 0 && len(project.Requirements) == 0 {
+		return nil
+	}
+	return project
+}
+
+// ansible.cfg settings worth reporting; the inventory path also marks its files as inventories
+func (project *AnsibleProject) loadConfig(file string, inventoryPaths map[string]bool) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		project.Errors = append(project.Errors, err.Error())
+		return
+	}
+	var settings []string
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, found := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || strings.HasPrefix(key, "#") || strings.HasPrefix(key, ";") {
+			continue
+		}
+		switch key {
+		case "inventory", "hostfile":
+			for _, entry := range strings.Split(value, ",") {
+				target := filepath.Join(filepath.Dir(file), strings.TrimSpace(entry))
+				if dirExists(target) {
+					_ = filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+						if err == nil && !info.IsDir() {
+							inventoryPaths[path] = true
+						}
+						return nil
+					})
+				} else {
+					inventoryPaths[target] = true
+				}
+			}
+			settings = append(settings, key+" = "+value)
+		case "roles_path", "collections_path", "collections_paths", "remote_user", "become", "vault_password_file", "vault_identity_list":
+			settings = append(settings, key+" = "+value)
+		}
+	}
+	project.Config = append(project.Config, fmt.Sprintf("%s (%s)", file, strings.Join(settings, ", ")))
+}
+
+func isAnsibleInventoryName(slashed string) bool {
+	name := path.Base(slashed)
+	if name == "hosts" || name == "inventory" || name == "hosts.ini" || name == "inventory.ini" || name == "hosts.yml" || name == "hosts.yaml" || name == "inventory.yml" || name == "inventory.yaml" {
+		return true
+	}
+	return ansibleInventoryDirs.MatchString(slashed) && (isYAMLFileName(name) || path.Ext(name) == ".ini" || path.Ext(name) == "")
+}
+
+func (project *AnsibleProject) loadRequirements(file string) {
+	config, err := readStructuredFile(file)
+	var items []interface{}
+	if err != nil || config == nil {
+		// The legacy format is a bare list of roles
+		content, readErr := os.ReadFile(file)
+		var list interface{}
+		if readErr != nil || yaml.Unmarshal(content, &list) != nil {
+			project.Errors = append(project.Errors, fmt.Sprintf("error parsing %s", file))
+			return
+		}
+		items = yamlList(normalizeYAML(list))
+		config = map[string]interface{}{"roles": items}
+	}
+	for _, kind := range []string{"collections", "roles"} {
+		for _, item := range yamlList(config[kind]) {
+			requirement := AnsibleRequirement{Kind: strings.TrimSuffix(kind, "s"), File: file, Name: yamlString(item)}
+			if settings := yamlMap(item); settings != nil {
+				requirement.Name = orDefault(yamlString(settings["name"]), yamlString(settings["src"]))
+				requirement.Version = yamlString(settings["version"])
+			}
+			project.Requirements = append(project.Requirements, requirement)
+		}
+	}
+}
+
+// A playbook is a YAML list of plays, each with hosts or an import of another playbook
+func (project *AnsibleProject) loadPlaybook(file string) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	if isAnsibleVault(content) {
+		project.VaultFiles = append(project.VaultFiles, file)
+		return
+	}
+	var document interface{}
+	if yaml.Unmarshal(content, &document) != nil {
+		return
+	}
+	items := yamlList(normalizeYAML(document))
+	if len(items) == 0 {
+		return
+	}
+
+	playbook := AnsiblePlaybook{File: file}
+	for _, item := range items {
+		play := yamlMap(item)
+		if play == nil {
+			return
+		}
+		if imported := orFirst(play, "import_playbook", "ansible.builtin.import_playbook"); imported != nil {
+			playbook.Imports = append(playbook.Imports, yamlString(imported))
+			continue
+		}
+		if play["hosts"] == nil {
+			return
+		}
+		entry := AnsiblePlay{Name: yamlString(play["name"]), Hosts: strings.Join(yamlKeysOrItems(play["hosts"]), ","), Become: play["become"] == true}
+		for _, role := range yamlList(play["roles"]) {
+			name := yamlString(role)
+			if settings := yamlMap(role); settings != nil {
+				name = orDefault(yamlString(settings["role"]), yamlString(settings["name"]))
+			}
+			entry.Roles = append(entry.Roles, name)
+		}
+		for _, section := range []string{"pre_tasks", "tasks", "post_tasks"} {
+			entry.Tasks += len(ansibleTasks(play[section]))
+		}
+		project.Secrets = append(project.Secrets, ansibleSecrets(file, string(content), yamlMap(play["vars"]))...)
+		playbook.Plays = append(playbook.Plays, entry)
+	}
+	project.Playbooks = append(project.Playbooks, playbook)
+}
+
+// Flatten a task list (including blocks) into task maps
+func ansibleTasks(value interface{}) []map[string]interface{} {
+	var tasks []map[string]interface{}
+	for _, item := range yamlList(value) {
+		task := yamlMap(item)
+		if task == nil {
+			continue
+		}
+		if task["block"] != nil {
+			for _, section := range []string{"block", "rescue", "always"} {
+				tasks = append(tasks, ansibleTasks(task[section])...)
+			}
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func ansibleTaskModule(task map[string]interface{}) string {
+	for _, key := range sortedKeys(task) {
+		if !ansibleTaskKeywords[key] && !strings.HasPrefix(key, "with_") {
+			return strings.TrimPrefix(key, "ansible.builtin.")
+		}
+	}
+	return ""
+}
+
+func (role *AnsibleRole) addFile(file, section string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if isAnsibleVault(content) {
+		return nil
+	}
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("error parsing %s: %w", file, err)
+	}
+	document = normalizeYAML(document)
+
+	switch section {
+	case "tasks":
+		for _, task := range ansibleTasks(document) {
+			module := ansibleTaskModule(task)
+			role.Tasks = append(role.Tasks, orDefault(yamlString(task["name"]), module))
+			if module != "" {
+				role.Modules = appendUnique(role.Modules, module)
+			}
+		}
+	case "handlers":
+		for _, handler := range ansibleTasks(document) {
+			role.Handlers = append(role.Handlers, orDefault(yamlString(handler["name"]), ansibleTaskModule(handler)))
+		}
+	case "defaults":
+		role.Defaults = append(role.Defaults, yamlKeysOrItems(yamlMap(document))...)
+	case "meta":
+		for _, dependency := range yamlList(yamlMap(document)["dependencies"]) {
+			name := yamlString(dependency)
+			if settings := yamlMap(dependency); settings != nil {
+				name = orDefault(yamlString(settings["role"]), yamlString(settings["name"]))
+			}
+			role.Dependencies = append(role.Dependencies, name)
+		}
+	}
+	return nil
+}
+
+func parseAnsibleInventory(file string) (AnsibleInventory, []AnsibleSecret, error) {
+	inventory := AnsibleInventory{File: file, Hosts: map[string][]string{}, Children: map[string][]string{}}
+	var secrets []AnsibleSecret
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return inventory, nil, err
+	}
+	if isAnsibleVault(content) {
+		return inventory, nil, fmt.Errorf("%s is vault encrypted", file)
+	}
+
+	if isYAMLFileName(filepath.Base(file)) {
+		var document interface{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return inventory, nil, err
+		}
+		var walk func(group string, settings map[string]interface{})
+		walk = func(group string, settings map[string]interface{}) {
+			hosts := yamlMap(settings["hosts"])
+			for _, host := range sortedKeys(hosts) {
+				inventory.Hosts[group] = append(inventory.Hosts[group], host)
+				secrets = append(secrets, ansibleSecrets(file, string(content), yamlMap(hosts[host]))...)
+			}
+			secrets = append(secrets, ansibleSecrets(file, string(content), yamlMap(settings["vars"]))...)
+			children := yamlMap(settings["children"])
+			for _, child := range sortedKeys(children) {
+				inventory.Children[group] = append(inventory.Children[group], child)
+				walk(child, yamlMap(children[child]))
+			}
+		}
+		groups := yamlMap(normalizeYAML(document))
+		for _, group := range sortedKeys(groups) {
+			walk(group, yamlMap(groups[group]))
+		}
+		delete(inventory.Children, "all")
+		return inventory, secrets, nil
+	}
+
+	// INI format: [group], [group:children] and [group:vars] sections with host lines of key=value vars
+	group, kind := "ungrouped", ""
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group, kind, _ = strings.Cut(strings.Trim(line, "[]"), ":")
+			continue
+		}
+		fields := strings.Fields(line)
+		switch kind {
+		case "children":
+			inventory.Children[group] = append(inventory.Children[group], fields[0])
+		case "vars":
+			secrets = append(secrets, ansibleINISecrets(file, lineNumber, fields)...)
+		default:
+			inventory.Hosts[group] = append(inventory.Hosts[group], fields[0])
+			secrets = append(secrets, ansibleINISecrets(file, lineNumber, fields[1:])...)
+		}
+	}
+	return inventory, secrets, scanner.Err()
+}
+
+func ansibleINISecrets(file string, line int, fields []string) []AnsibleSecret {
+	var secrets []AnsibleSecret
+	for _, field := range fields {
+		key, value, found := strings.Cut(field, "=")
+		if found && isAnsibleSecretVariable(key, value) {
+			secrets = append(secrets, AnsibleSecret{File: file, Line: line, Key: key})
+		}
+	}
+	return secrets
+}
+
+// Variables named like credentials that hold a literal value rather than a template, lookup or inline vault
+func ansibleSecrets(file, content string, vars map[string]interface{}) []AnsibleSecret {
+	var secrets []AnsibleSecret
+	for _, key := range sortedKeys(vars) {
+		value, isString := vars[key].(string)
+		if !isString || !isAnsibleSecretVariable(key, value) {
+			continue
+		}
+		secret := AnsibleSecret{File: file, Key: key}
+		keyRegex := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(key) + `\s*:`)
+		for i, line := range strings.Split(content, "\n") {
+			if keyRegex.MatchString(line) {
+				secret.Line = i + 1
+				break
+			}
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets
+}
+
+func isAnsibleSecretVariable(key, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Contains(value, "{{") || strings.HasPrefix(value, "$ANSIBLE_VAULT;") {
+		return false
+	}
+	key = strings.ToLower(key)
+	return terraformCredentialAttribute(key) || strings.HasSuffix(key, "_pass")
+}
+
+// Host patterns targeted by plays that match no inventory group or host
+func (project *AnsibleProject) targetedGroups() (targeted []string, undefined []string) {
+	known := map[string]bool{"all": true, "ungrouped": true, "localhost": true}
+	for _, inventory := range project.Inventories {
+		for group, hosts := range inventory.Hosts {
+			known[group] = true
+			for _, host := range hosts {
+				known[host] = true
+			}
+		}
+		for group, children := range inventory.Children {
+			known[group] = true
+			for _, child := range children {
+				known[child] = true
+			}
+		}
+	}
+	for _, playbook := range project.Playbooks {
+		for _, play := range playbook.Plays {
+			for _, pattern := range strings.FieldsFunc(play.Hosts, func(r rune) bool { return r == ':' || r == ',' }) {
+				pattern = strings.TrimLeft(strings.TrimSpace(pattern), "!&")
+				if pattern == "" || strings.Contains(pattern, "{{") {
+					continue
+				}
+				targeted = appendUnique(targeted, pattern)
+				if len(project.Inventories) > 0 && !known[pattern] && !strings.ContainsAny(pattern, "*?[~") {
+					undefined = appendUnique(undefined, pattern)
+				}
+			}
+		}
+	}
+	sort.Strings(targeted)
+	sort.Strings(undefined)
+	return targeted, undefined
+}
+
+func ansibleFindings() []Finding {
+	project := loadAnsibleProject()
+	if project == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, secret := range project.Secrets {
+		findings = append(findings, newFinding("ansible/plaintext-secret", "Secret stored in plaintext outside ansible-vault",
+			categorySecurity, "high", fmt.Sprintf("variable %s holds a plaintext value; encrypt it with ansible-vault", secret.Key),
+			secret.File, secret.Line, secret.Key))
+	}
+	return findings
+}
+
+func writeAnsibleProject(output io.StringWriter, project *AnsibleProject) {
+	if project == nil {
+		return
+	}
+	output.WriteString("Ansible:\n")
+	for _, config := range project.Config {
+		output.WriteString("  Config: " + config + "\n")
+	}
+
+	if len(project.Inventories) > 0 {
+		output.WriteString("  Inventories:\n")
+		for _, inventory := range project.Inventories {
+			var groups []string
+			for _, group := range sortedKeys(inventory.Hosts) {
+				groups = append(groups, fmt.Sprintf("%s (%d hosts)", group, len(inventory.Hosts[group])))
+			}
+			for _, group := range sortedKeys(inventory.Children) {
+				groups = append(groups, fmt.Sprintf("%s (children: %s)", group, strings.Join(inventory.Children[group], ", ")))
+			}
+			output.WriteString(fmt.Sprintf("    - %s: %s\n", inventory.File, strings.Join(groups, ", ")))
+		}
+	}
+
+	if len(project.Playbooks) > 0 {
+		output.WriteString("  Playbooks:\n")
+		for _, playbook := range project.Playbooks {
+			output.WriteString("    - " + playbook.File + "\n")
+			for _, imported := range playbook.Imports {
+				output.WriteString("        imports " + imported + "\n")
+			}
+			for _, play := range playbook.Plays {
+				label := "(unnamed)"
+				if play.Name != "" {
+					label = fmt.Sprintf("%q", play.Name)
+				}
+				line := fmt.Sprintf("        play %s -> hosts %s", label, play.Hosts)
+				if len(play.Roles) > 0 {
+					line += ", roles: " + strings.Join(play.Roles, ", ")
+				}
+				line += fmt.Sprintf(", %d tasks", play.Tasks)
+				if play.Become {
+					line += ", become"
+				}
+				output.WriteString(line + "\n")
+			}
+		}
+		targeted, undefined := project.targetedGroups()
+		output.WriteString("  Targeted host groups: " + strings.Join(targeted, ", ") + "\n")
+		if len(undefined) > 0 {
+			output.WriteString("  Not defined in any inventory: " + strings.Join(undefined, ", ") + "\n")
+		}
+	}
+
+	if len(project.Roles) > 0 {
+		output.WriteString(fmt.Sprintf("  Roles (%d):\n", len(project.Roles)))
+		for _, role := range project.Roles {
+			output.WriteString(fmt.Sprintf("    - %s (%s): %d tasks", role.Name, role.Dir, len(role.Tasks)))
+			if len(role.Modules) > 0 {
+				output.WriteString(" using " + strings.Join(role.Modules, ", "))
+			}
+			output.WriteString("\n")
+			if len(role.Handlers) > 0 {
+				output.WriteString("        handlers: " + strings.Join(role.Handlers, ", ") + "\n")
+			}
+			if len(role.Defaults) > 0 {
+				output.WriteString("        defaults: " + strings.Join(role.Defaults, ", ") + "\n")
+			}
+			if len(role.Dependencies) > 0 {
+				output.WriteString("        depends on: " + strings.Join(role.Dependencies, ", ") + "\n")
+			}
+		}
+	}
+
+	if len(project.Requirements) > 0 {
+		output.WriteString("  Requirements:\n")
+		for _, requirement := range project.Requirements {
+			output.WriteString(fmt.Sprintf("    - %s %s %s (%s)\n", requirement.Kind, requirement.Name, orDefault(requirement.Version, "any version"), requirement.File))
+		}
+	}
+
+	if len(project.VaultFiles) > 0 {
+		output.WriteString("  Vault-encrypted files: " + strings.Join(project.VaultFiles, ", ") + "\n")
+	}
+	if len(project.Secrets) > 0 {
+		// Values are never echoed, not even in part
+		output.WriteString("  Plaintext secrets (not vault-encrypted):\n")
+		for _, secret := range project.Secrets {
+			output.WriteString(fmt.Sprintf("    - %s:%d %s = ********\n", secret.File, secret.Line, secret.Key))
+		}
+	}
+	for _, parseError := range project.Errors {
+		output.WriteString("  Error: " + parseError + "\n")
+	}
+	output.WriteString("\n")
+}
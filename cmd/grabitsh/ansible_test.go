@@ -0,0 +1,44 @@
+package grabitsh
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAnsibleProjectMasksSecrets(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"hosts.ini": "[web]\nweb1 ansible_password=hunter2\n\n[web:vars]\ndb_password=plaintextvalue\n",
+	})
+	inventory, secrets, err := parseAnsibleInventory(filepath.Join(dir, "hosts.ini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets = append(secrets, ansibleSecrets("group_vars/all.yml", "api_token: abcdefghijkl\n",
+		map[string]interface{}{"api_token": "abcdefghijkl"})...)
+	project := &AnsibleProject{
+		Inventories: []AnsibleInventory{inventory},
+		Playbooks: []AnsiblePlaybook{{File: "site.yml", Plays: []AnsiblePlay{
+			{Hosts: "web", Tasks: 1}, {Name: "Deploy", Hosts: "web", Tasks: 2},
+		}}},
+		Secrets: secrets,
+	}
+	if len(secrets) != 3 {
+		t.Fatalf("found %d secrets, want 3", len(secrets))
+	}
+
+	var output strings.Builder
+	writeAnsibleProject(&output, project)
+	report := output.String()
+	for _, leaked := range []string{"hunter", "plai", "abcd"} {
+		if strings.Contains(report, leaked) {
+			t.Errorf("report reveals %q:\n%s", leaked, report)
+		}
+	}
+	for _, want := range []string{"ansible_password = ********", "db_password = ********", "api_token = ********",
+		`play (unnamed) -> hosts web`, `play "Deploy" -> hosts web`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -1,10 +1,24 @@
 package grabitsh
 
+import "strings"
+
 func detectArchitecture() string {
+	projects := detectSubProjects()
 	if dirExists("services") || dirExists("microservices") {
+		var services []SubProject
+		for _, project := range projects {
+			if strings.HasPrefix(project.Path, "services/") || strings.HasPrefix(project.Path, "microservices/") {
+				services = append(services, project)
+			}
+		}
+		if len(services) > 0 {
+			return "Microservices (" + summarizeSubProjects(services) + ")"
+		}
 		return "Microservices"
-	} else if fileExists("serverless.yml") || fileExists("serverless.yaml") {
-		return "Serverless"
+	} else if frameworks := serverlessFrameworks(); len(frameworks) > 0 {
+		return "Serverless (" + strings.Join(frameworks, ", ") + ")"
+	} else if len(projects) > 1 {
+		return "Multi-project monorepo (" + summarizeSubProjects(projects) + ")"
 	} else if dirExists("app") && dirExists("config") && dirExists("db") {
 		return "Monolithic (Rails-like)"
 	} else if fileExists("package.json") && fileExists("server.js") {
@@ -0,0 +1,350 @@
+package grabitsh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+type ComposeService struct {
+	Name           string   `json:"name"`
+	Image          string   `json:"image,omitempty"`
+	Build          string   `json:"build,omitempty"`
+	Ports          []string `json:"ports,omitempty"`
+	Volumes        []string `json:"volumes,omitempty"`
+	Networks       []string `json:"networks,omitempty"`
+	DependsOn      []string `json:"depends_on,omitempty"`
+	Environment    []string `json:"environment,omitempty"`
+	Healthcheck    string   `json:"healthcheck,omitempty"`
+	Profiles       []string `json:"profiles,omitempty"`
+	BackingService string   `json:"backing_service,omitempty"`
+	Files          []string `json:"files"`
+}
+
+type ComposeProject struct {
+	Dir      string            `json:"dir"`
+	Files    []string          `json:"files"`
+	Services []*ComposeService `json:"services"`
+	Networks []string          `json:"networks,omitempty"`
+	Volumes  []string          `json:"volumes,omitempty"`
+}
+
+var (
+	composeFileRegex = regexp.MustCompile(`^(docker-)?compose(\.[A-Za-z0-9_-]+)*\.ya?ml$`)
+
+	// Image name fragments that identify well-known backing services
+	backingServicePatterns = []struct {
+		fragment string
+		service  string
+	}{
+		{"postgis", "PostgreSQL"}, {"postgres", "PostgreSQL"}, {"mysql", "MySQL"}, {"mariadb", "MariaDB"},
+		{"mongo", "MongoDB"}, {"redis", "Redis"}, {"valkey", "Valkey"}, {"memcached", "Memcached"},
+		{"kafka", "Kafka"}, {"redpanda", "Redpanda"}, {"zookeeper", "ZooKeeper"}, {"rabbitmq", "RabbitMQ"},
+		{"nats", "NATS"}, {"elasticsearch", "Elasticsearch"}, {"opensearch", "OpenSearch"}, {"minio", "MinIO"},
+		{"localstack", "LocalStack"}, {"mailhog", "MailHog"}, {"mailpit", "Mailpit"}, {"keycloak", "Keycloak"},
+		{"clickhouse", "ClickHouse"}, {"cassandra", "Cassandra"}, {"consul", "Consul"}, {"vault", "Vault"},
+		{"jaeger", "Jaeger"}, {"prometheus", "Prometheus"}, {"grafana", "Grafana"}, {"mssql", "SQL Server"},
+	}
+)
+
+func isComposeFileName(name string) bool {
+	return composeFileRegex.MatchString(name)
+}
+
+// Base files are loaded first, then overrides, then any other variants (e.g. compose.prod.yaml)
+func composeFileRank(name string) int {
+	switch {
+	case name == "compose.yaml" || name == "compose.yml" || name == "docker-compose.yaml" || name == "docker-compose.yml":
+		return 0
+	case strings.Contains(name, ".override."):
+		return 1
+	}
+	return 2
+}
+
+func findComposeProjects() []*ComposeProject {
+	byDir := make(map[string][]string)
+	for _, path := range findRepositoryFiles(isComposeFileName) {
+		dir := filepath.ToSlash(filepath.Dir(path))
+		byDir[dir] = append(byDir[dir], path)
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var projects []*ComposeProject
+	for _, dir := range dirs {
+		files := byDir[dir]
+		sort.Slice(files, func(i, j int) bool {
+			ri, rj := composeFileRank(filepath.Base(files[i])), composeFileRank(filepath.Base(files[j]))
+			if ri != rj {
+				return ri < rj
+			}
+			return files[i] < files[j]
+		})
+		projects = append(projects, &ComposeProject{Dir: dir, Files: files})
+	}
+	return projects
+}
+
+// Parse and merge every compose file of the project into a single service model
+func loadComposeProject(project *ComposeProject) error {
+	services := make(map[string]*ComposeService)
+
+	for _, file := range project.Files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var raw interface{}
+		if err := yaml.Unmarshal(content, &raw); err != nil {
+			return fmt.Errorf("error parsing %s: %w", file, err)
+		}
+		document := yamlMap(normalizeYAML(raw))
+
+		for name, definition := range yamlMap(document["services"]) {
+			service, ok := services[name]
+			if !ok {
+				service = &ComposeService{Name: name}
+				services[name] = service
+			}
+			mergeComposeService(service, yamlMap(definition))
+			service.Files = appendUnique(service.Files, file)
+		}
+		for _, network := range yamlKeysOrItems(document["networks"]) {
+			project.Networks = appendUnique(project.Networks, network)
+		}
+		for _, volume := range yamlKeysOrItems(document["volumes"]) {
+			project.Volumes = appendUnique(project.Volumes, volume)
+		}
+	}
+
+	project.Services = nil
+	for _, service := range services {
+		service.BackingService = inferBackingService(service)
+		project.Services = append(project.Services, service)
+	}
+	sort.Slice(project.Services, func(i, j int) bool { return project.Services[i].Name < project.Services[j].Name })
+	return nil
+}
+
+func mergeComposeService(service *ComposeService, definition map[string]interface{}) {
+	if image := yamlString(definition["image"]); image != "" {
+		service.Image = image
+	}
+
+	switch build := definition["build"].(type) {
+	case string:
+		service.Build = build
+	case map[string]interface{}:
+		service.Build = yamlString(build["context"])
+		if dockerfile := yamlString(build["dockerfile"]); dockerfile != "" {
+			service.Build += " (" + dockerfile + ")"
+		}
+	}
+
+	for _, port := range yamlList(definition["ports"]) {
+		if long, ok := port.(map[string]interface{}); ok {
+			mapping := yamlString(long["target"])
+			if published := yamlString(long["published"]); published != "" {
+				mapping = published + ":" + mapping
+			}
+			if protocol := yamlString(long["protocol"]); protocol != "" {
+				mapping += "/" + protocol
+			}
+			service.Ports = appendUnique(service.Ports, mapping)
+		} else {
+			service.Ports = appendUnique(service.Ports, yamlString(port))
+		}
+	}
+
+	for _, volume := range yamlList(definition["volumes"]) {
+		if long, ok := volume.(map[string]interface{}); ok {
+			service.Volumes = appendUnique(service.Volumes, yamlString(long["source"])+":"+yamlString(long["target"]))
+		} else {
+			service.Volumes = appendUnique(service.Volumes, yamlString(volume))
+		}
+	}
+
+	for _, network := range yamlKeysOrItems(definition["networks"]) {
+		service.Networks = appendUnique(service.Networks, network)
+	}
+	for _, dependency := range yamlKeysOrItems(definition["depends_on"]) {
+		service.DependsOn = appendUnique(service.DependsOn, dependency)
+	}
+	for _, profile := range yamlKeysOrItems(definition["profiles"]) {
+		service.Profiles = appendUnique(service.Profiles, profile)
+	}
+
+	// Only variable names are kept, values may contain credentials
+	for _, variable := range yamlKeysOrItems(definition["environment"]) {
+		name := strings.SplitN(variable, "=", 2)[0]
+		service.Environment = appendUnique(service.Environment, name)
+	}
+
+	if healthcheck := yamlMap(definition["healthcheck"]); healthcheck != nil {
+		if disabled, _ := healthcheck["disable"].(bool); disabled {
+			service.Healthcheck = "disabled"
+		} else if test, ok := healthcheck["test"].([]interface{}); ok {
+			var parts []string
+			for _, part := range test {
+				parts = append(parts, yamlString(part))
+			}
+			service.Healthcheck = strings.Join(parts, " ")
+		} else {
+			service.Healthcheck = yamlString(healthcheck["test"])
+		}
+	}
+}
+
+func inferBackingService(service *ComposeService) string {
+	candidates := []string{strings.ToLower(service.Image)}
+	if service.Image == "" {
+		candidates = append(candidates, strings.ToLower(service.Name))
+	}
+	for _, candidate := range candidates {
+		// Ignore the registry and tag, only the repository name identifies the software
+		name := candidate
+		if slash := strings.LastIndex(name, "/"); slash != -1 {
+			name = name[slash+1:]
+		}
+		name = strings.SplitN(name, ":", 2)[0]
+		for _, pattern := range backingServicePatterns {
+			if strings.Contains(name, pattern.fragment) {
+				return pattern.service
+			}
+		}
+	}
+	return ""
+}
+
+func writeComposeProject(output io.StringWriter, project *ComposeProject) {
+	output.WriteString(fmt.Sprintf("Compose project: %s\n", project.Dir))
+	output.WriteString(fmt.Sprintf("  Files (merged in order): %s\n", strings.Join(project.Files, ", ")))
+
+	var table strings.Builder
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "  SERVICE\tIMAGE / BUILD\tPORTS\tDEPENDS ON\tHEALTHCHECK\tBACKING SERVICE")
+	for _, service := range project.Services {
+		source := service.Image
+		if service.Build != "" {
+			source = "build: " + service.Build
+		}
+		healthcheck := "no"
+		if service.Healthcheck != "" {
+			healthcheck = "yes"
+			if service.Healthcheck == "disabled" {
+				healthcheck = "disabled"
+			}
+		}
+		fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\t%s\t%s\n", service.Name, orDash(source), orDash(strings.Join(service.Ports, ", ")),
+			orDash(strings.Join(service.DependsOn, ", ")), healthcheck, orDash(service.BackingService))
+	}
+	writer.Flush()
+	output.WriteString(table.String())
+
+	for _, service := range project.Services {
+		var details []string
+		if len(service.Environment) > 0 {
+			details = append(details, "env: "+strings.Join(service.Environment, ", "))
+		}
+		if len(service.Volumes) > 0 {
+			details = append(details, "volumes: "+strings.Join(service.Volumes, ", "))
+		}
+		if len(service.Networks) > 0 {
+			details = append(details, "networks: "+strings.Join(service.Networks, ", "))
+		}
+		if len(service.Profiles) > 0 {
+			details = append(details, "profiles: "+strings.Join(service.Profiles, ", "))
+		}
+		if len(details) > 0 {
+			output.WriteString(fmt.Sprintf("  %s: %s\n", service.Name, strings.Join(details, "; ")))
+		}
+	}
+
+	output.WriteString("\n  Mermaid diagram:\n")
+	output.WriteString(indentLines(renderComposeMermaid(project), "    "))
+	output.WriteString("\n  DOT diagram:\n")
+	output.WriteString(indentLines(renderComposeDOT(project), "    "))
+}
+
+func renderComposeMermaid(project *ComposeProject) string {
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	for _, service := range project.Services {
+		label := service.Name
+		if service.Image != "" {
+			label += "<br/>" + service.Image
+		} else if service.Build != "" {
+			label += "<br/>build: " + service.Build
+		}
+		label = strings.ReplaceAll(label, `"`, "'")
+		if service.BackingService != "" {
+			sb.WriteString(fmt.Sprintf("  %s[(\"%s\")]\n", mermaidID(service.Name), label))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", mermaidID(service.Name), label))
+		}
+	}
+	for _, service := range project.Services {
+		for _, dependency := range service.DependsOn {
+			sb.WriteString(fmt.Sprintf("  %s --> %s\n", mermaidID(service.Name), mermaidID(dependency)))
+		}
+	}
+	return sb.String()
+}
+
+func renderComposeDOT(project *ComposeProject) string {
+	var sb strings.Builder
+	sb.WriteString("digraph compose {\n  rankdir=LR;\n")
+	for _, service := range project.Services {
+		label := service.Name
+		if service.Image != "" {
+			label += "\\n" + service.Image
+		} else if service.Build != "" {
+			label += "\\nbuild: " + service.Build
+		}
+		shape := "box"
+		if service.BackingService != "" {
+			shape = "cylinder"
+		}
+		sb.WriteString(fmt.Sprintf("  %q [label=\"%s\", shape=%s];\n", service.Name, strings.ReplaceAll(label, `"`, `\"`), shape))
+	}
+	for _, service := range project.Services {
+		for _, dependency := range service.DependsOn {
+			sb.WriteString(fmt.Sprintf("  %q -> %q;\n", service.Name, dependency))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+var mermaidUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func mermaidID(name string) string {
+	return mermaidUnsafeChars.ReplaceAllString(name, "_")
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func indentLines(text, indent string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
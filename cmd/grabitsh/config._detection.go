@@ -40,8 +40,12 @@ func DetectImportantFiles(buffer *bytes.Buffer) {
 	checkAndParseIfExists(".npmrc", parseBasicTextFile, buffer)
 	checkAndParseIfExists(".nvmrc", parseBasicTextFile, buffer)
 	checkAndParseIfExists(".yarnrc", parseBasicTextFile, buffer)
-	checkAndParseIfExists("lerna.json", parseJSONFile, buffer)
-	checkAndParseIfExists("nx.json", parseJSONFile, buffer)
+	// lerna.json, nx.json, turbo.json and pnpm-workspace.yaml are summarised together as the workspace
+	if workspace, err := loadNodeWorkspace(); err != nil {
+		buffer.WriteString(fmt.Sprintf("Error detecting Node.js workspace: %v\n", err))
+	} else if workspace != nil {
+		buffer.WriteString(fmt.Sprintf("\nNode.js workspace: %s, %d packages\n", workspace.describe(), len(workspace.Packages)))
+	}
 
 	// 3. CI/CD and DevOps
 	checkAndParseIfExists(".travis.yml", parseYAMLFile, buffer)
@@ -56,20 +60,16 @@ func DetectImportantFiles(buffer *bytes.Buffer) {
 	checkAndParseIfExists(".snyk", parseBasicTextFile, buffer)
 
 	// 4. Docker and Containerization
-	checkAndParseIfExists("docker-compose.yml", parseYAMLFile, buffer)
-	checkAndParseIfExists("docker-compose.override.yml", parseYAMLFile, buffer)
-	checkAndParseIfExists("Dockerfile", parseDockerfile, buffer)
 	checkAndParseIfExists(".dockerignore", parseBasicTextFile, buffer)
 	checkAndParseIfExists("docker/", parseDockerDir, buffer)
 
 	// 5. Kubernetes
-	checkAndParseIfExists("k8s/", parseK8sFiles, buffer)
-	checkAndParseIfExists("helm/", parseHelmFiles, buffer)
+	writeK8sInventory(buffer, collectK8sObjects())
 	checkAndParseIfExists("values.yaml", parseYAMLFile, buffer)
-	checkAndParseIfExists("kustomization.yaml", parseYAMLFile, buffer)
+	kustomizations, _ := buildKustomizations()
+	writeKustomizeReport(buffer, kustomizations)
 
 	// 6. Cloud Providers
-	checkAndParseIfExists("serverless.yml", parseYAMLFile, buffer)
 	checkAndParseIfExists(".aws/", parseDirectoryContents, buffer)
 	checkAndParseIfExists("firebase.json", parseJSONFile, buffer)
 	checkAndParseIfExists("vercel.json", parseJSONFile, buffer)
@@ -78,7 +78,6 @@ func DetectImportantFiles(buffer *bytes.Buffer) {
 	// 7. Infrastructure as Code
 	checkAndParseIfExists("main.tf", parseBasicTextFile, buffer)
 	checkAndParseIfExists("Vagrantfile", parseBasicTextFile, buffer)
-	checkAndParseIfExists("Pulumi.yaml", parseYAMLFile, buffer)
 
 	// 8. Language-Specific
 	checkAndParseIfExistsWithError("Gemfile", parseGemfile, buffer)
@@ -0,0 +1,82 @@
+package grabitsh
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+const grabitConfigFile = ".grabitsh.yaml"
+
+// GrabitConfig holds repository-level settings read from .grabitsh.yaml
+type GrabitConfig struct {
+	SensitiveFiles SensitiveFilesConfig `yaml:"sensitive_files"`
+	Licenses       LicensePolicyConfig  `yaml:"licenses"`
+	Registries     RegistriesConfig     `yaml:"registries"`
+	Layering       []LayeringRule       `yaml:"layering"`
+}
+
+type SensitiveFilesConfig struct {
+	// Extra glob patterns flagged in addition to the built-in list
+	Patterns []string `yaml:"patterns"`
+	// Glob patterns that are never flagged
+	Exclude []string `yaml:"exclude"`
+	// Use only the configured patterns, dropping the built-in list
+	ReplaceDefaults bool `yaml:"replace_defaults"`
+}
+
+type LicensePolicyConfig struct {
+	// SPDX identifier globs dependencies may use; empty allows anything not denied
+	Allow []string `yaml:"allow"`
+	// SPDX identifier globs that are never acceptable, e.g. AGPL-*
+	Deny []string `yaml:"deny"`
+	// Package name globs skipped by the policy
+	Exclude []string `yaml:"exclude"`
+	// Report dependencies whose license could not be determined
+	FailOnUnknown bool `yaml:"fail_on_unknown"`
+}
+
+// Registry endpoints for the outdated report: an http(s) URL, or a file:// URL or directory laid out like the registry
+type RegistriesConfig struct {
+	// npm registry, e.g. https://registry.npmjs.org or a Verdaccio mirror
+	Npm string `yaml:"npm"`
+	// Go module proxy speaking the GOPROXY protocol
+	Go string `yaml:"go"`
+	// PyPI simple index (PEP 503 or PEP 691)
+	PyPI string `yaml:"pypi"`
+	// crates.io index, sparse over HTTP or a checkout of the git index
+	Crates string `yaml:"crates"`
+	// Request timeout in seconds
+	Timeout int `yaml:"timeout"`
+}
+
+// A Go layering rule. Patterns are import paths, absolute or relative to the package's module,
+// where a trailing "/..." also matches every package below.
+type LayeringRule struct {
+	// Packages the rule applies to, e.g. internal/domain/...
+	Package string `yaml:"package"`
+	// Imports these packages may never use, internal, standard library or external
+	MustNotImport []string `yaml:"must_not_import"`
+	// When set, the only repository packages these packages may import besides their own layer
+	MayOnlyImport []string `yaml:"may_only_import"`
+	// Shown with every violation
+	Reason string `yaml:"reason"`
+}
+
+func loadGrabitConfig() (GrabitConfig, error) {
+	var config GrabitConfig
+
+	content, err := os.ReadFile(grabitConfigFile)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("error reading %s: %w", grabitConfigFile, err)
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("error parsing %s: %w", grabitConfigFile, err)
+	}
+
+	return config, nil
+}
@@ -1,26 +1,34 @@
 package grabitsh
 
+import (
+	"fmt"
+)
+
+var depManagement = map[string]string{
+	"package-lock.json":   "npm",
+	"npm-shrinkwrap.json": "npm",
+	"yarn.lock":           "Yarn",
+	"pnpm-lock.yaml":      "pnpm",
+	"Gemfile.lock":        "Bundler (Ruby)",
+	"poetry.lock":         "Poetry (Python)",
+	"go.mod":              "Go Modules",
+	"composer.lock":       "Composer (PHP)",
+	"Pipfile.lock":        "Pipenv (Python)",
+	"pom.xml":             "Maven (Java)",
+	"gradle.lockfile":     "Gradle (Java)",
+	"requirements.txt":    "pip (Python)",
+	"Cargo.lock":          "Cargo (Rust)",
+}
+
+// Package managers in use, one entry per lockfile or manifest the dependency parsers read
 func analyzeDependencyManagement() []string {
 	var tools []string
 
-	depManagement := map[string]string{
-		"package-lock.json": "npm",
-		"yarn.lock":         "Yarn",
-		"Gemfile.lock":      "Bundler (Ruby)",
-		"poetry.lock":       "Poetry (Python)",
-		"go.sum":            "Go Modules",
-		"composer.lock":     "Composer (PHP)",
-		"Pipfile.lock":      "Pipenv (Python)",
-		"pom.xml":           "Maven (Java)",
-		"build.gradle":      "Gradle (Java)",
-		"requirements.txt":  "pip (Python)",
-		"Cargo.lock":        "Cargo (Rust)",
+	for _, path := range findLockfiles() {
+		tools = append(tools, fmt.Sprintf("%s (%s)", depManagement[lockfileName(path)], path))
 	}
-
-	for file, tool := range depManagement {
-		if fileExists(file) {
-			tools = append(tools, tool)
-		}
+	if (fileExists("build.gradle") || fileExists("build.gradle.kts")) && !fileExists("gradle.lockfile") {
+		tools = append(tools, "Gradle (Java, no dependency lockfile)")
 	}
 
 	return tools
@@ -0,0 +1,479 @@
+package grabitsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	depgraphFormat     string
+	depgraphWorkspaces bool
+)
+
+var depgraphCmd = &cobra.Command{
+	Use:   "depgraph",
+	Short: "Render the dependency graph from the repository's lockfiles and explain duplicate versions",
+	Run:   runDepgraph,
+}
+
+func init() {
+	depgraphCmd.Flags().StringVar(&depgraphFormat, "format", "text", "Graph format: text (summary), dot, mermaid or json")
+	depgraphCmd.Flags().BoolVar(&depgraphWorkspaces, "workspaces", false, "Graph the Node.js workspace packages and their internal dependencies instead")
+}
+
+// Packages reported in the deepest chains and heaviest transitive lists
+const depgraphReportLimit = 10
+
+func runDepgraph(cmd *cobra.Command, args []string) {
+	var dependencies []Dependency
+	if depgraphWorkspaces {
+		workspace, err := loadNodeWorkspace()
+		if err != nil {
+			color.Red("Failed to detect Node.js workspace: %v", err)
+			return
+		}
+		if workspace == nil {
+			color.Red("No Node.js workspace found.")
+			return
+		}
+		dependencies = workspace.dependencies()
+	} else {
+		var errs []error
+		dependencies, errs = collectDependencies()
+		for _, err := range errs {
+			color.Yellow("Skipping lockfile: %v", err)
+		}
+	}
+	graph := buildDependencyGraph(dependencies)
+
+	var output strings.Builder
+	switch depgraphFormat {
+	case "text":
+		writeDependencyGraphSummary(&output, graph)
+	case "dot":
+		renderDependencyGraphDOT(&output, graph)
+	case "mermaid":
+		renderDependencyGraphMermaid(&output, graph)
+	case "json":
+		content, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			color.Red("Failed to render dependency graph: %v", err)
+			return
+		}
+		output.Write(content)
+		output.WriteString("\n")
+	default:
+		color.Red("Invalid format. Choose text, dot, mermaid or json.")
+		return
+	}
+	finalizeOutput(output.String())
+}
+
+type graphNode struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Ecosystem string   `json:"ecosystem"`
+	Scope     string   `json:"scope,omitempty"`
+	Direct    bool     `json:"direct"`
+	Sources   []string `json:"sources"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Another version of the same package is also in the graph
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Number of distinct packages this one pulls in, directly or transitively
+	TransitiveCount int `json:"transitive_count"`
+	// Shortest chain from a direct dependency, direct dependencies have depth 1
+	Depth int `json:"depth"`
+	// A workspace package's manifest, the parent of the dependencies it declares
+	Workspace bool `json:"workspace,omitempty"`
+}
+
+func (node *graphNode) label() string {
+	if node.Version == "" {
+		return node.Name
+	}
+	return node.Name + "@" + node.Version
+}
+
+type duplicateVersion struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	// Packages that require this version, "(project)" for a direct dependency
+	RequiredBy []string `json:"required_by"`
+}
+
+type duplicatePackage struct {
+	Ecosystem string             `json:"ecosystem"`
+	Name      string             `json:"name"`
+	Versions  []duplicateVersion `json:"versions"`
+}
+
+type heavyPackage struct {
+	ID              string   `json:"id"`
+	TransitiveCount int      `json:"transitive_count"`
+	PulledInBy      []string `json:"pulled_in_by"`
+}
+
+type dependencyGraph struct {
+	Name          string             `json:"name"`
+	Nodes         []*graphNode       `json:"nodes"`
+	Direct        []string           `json:"direct"`
+	Duplicates    []duplicatePackage `json:"duplicates,omitempty"`
+	DeepestChains [][]string         `json:"deepest_chains,omitempty"`
+	Heavy         []heavyPackage     `json:"heavy,omitempty"`
+
+	byID map[string]*graphNode
+}
+
+// Resolve every dependency's requirements to purls within its own lockfile, preferring the version
+// the lockfile pins for that dependent and falling back to the first package with the required name
+func dependencyEdges(dependencies []Dependency) map[string][]string {
+	byVersion := make(map[string]string)
+	byName := make(map[string]string)
+	for _, dep := range dependencies {
+		byVersion[dep.Source+"\x00"+dep.Name+"@"+dep.Version] = dependencyPURL(dep)
+		if _, ok := byName[dep.Source+"\x00"+dep.Name]; !ok {
+			byName[dep.Source+"\x00"+dep.Name] = dependencyPURL(dep)
+		}
+	}
+
+	edges := make(map[string][]string)
+	for _, dep := range dependencies {
+		purl := dependencyPURL(dep)
+		for _, required := range dep.Requires {
+			target, ok := byVersion[dep.Source+"\x00"+required+"@"+dep.RequiredVersions[required]]
+			if !ok {
+				target, ok = byName[dep.Source+"\x00"+required]
+			}
+			if ok && target != purl {
+				edges[purl] = appendUnique(edges[purl], target)
+			}
+		}
+	}
+	for purl := range edges {
+		sort.Strings(edges[purl])
+	}
+	return edges
+}
+
+func buildDependencyGraph(dependencies []Dependency) *dependencyGraph {
+	graph := &dependencyGraph{Name: sbomProjectName(), byID: make(map[string]*graphNode)}
+	for _, dep := range dependencies {
+		id := dependencyPURL(dep)
+		node, ok := graph.byID[id]
+		if !ok {
+			node = &graphNode{ID: id, Name: dep.Name, Version: dep.Version, Ecosystem: dep.Ecosystem}
+			graph.byID[id] = node
+			graph.Nodes = append(graph.Nodes, node)
+		}
+		if dep.Scope == scopeProd || node.Scope == "" {
+			node.Scope = dep.Scope
+		}
+		node.Direct = node.Direct || graph.addDeclarations(dep, id)
+		node.Sources = appendUnique(node.Sources, dep.Source)
+	}
+	for id, targets := range dependencyEdges(dependencies) {
+		graph.byID[id].DependsOn = targets
+	}
+	for _, node := range graph.Nodes {
+		if node.Workspace {
+			sort.Strings(node.DependsOn)
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	for _, node := range graph.Nodes {
+		if node.Direct {
+			graph.Direct = append(graph.Direct, node.ID)
+		}
+	}
+
+	graph.findDuplicates()
+	graph.findDeepestChains()
+	graph.findHeavyPackages()
+	return graph
+}
+
+// Workspace packages sit between the project and the dependencies they declare. Reports whether
+// the dependency hangs off the project itself.
+func (graph *dependencyGraph) addDeclarations(dep Dependency, id string) bool {
+	if len(dep.DeclaredBy) == 0 {
+		return dep.Direct
+	}
+	rootManifest := filepath.ToSlash(filepath.Join(filepath.Dir(dep.Source), "package.json"))
+	direct := false
+	for _, manifest := range dep.DeclaredBy {
+		if manifest == rootManifest {
+			direct = true
+			continue
+		}
+		parent, ok := graph.byID[manifest]
+		if !ok {
+			parent = &graphNode{ID: manifest, Name: manifest, Ecosystem: dep.Ecosystem, Direct: true, Workspace: true}
+			graph.byID[manifest] = parent
+			graph.Nodes = append(graph.Nodes, parent)
+		}
+		parent.Sources = appendUnique(parent.Sources, dep.Source)
+		parent.DependsOn = appendUnique(parent.DependsOn, id)
+	}
+	return direct
+}
+
+func (graph *dependencyGraph) findDuplicates() {
+	requiredBy := make(map[string][]string)
+	for _, node := range graph.Nodes {
+		for _, target := range node.DependsOn {
+			requiredBy[target] = append(requiredBy[target], node.label())
+		}
+	}
+
+	versions := make(map[string][]*graphNode)
+	for _, node := range graph.Nodes {
+		key := node.Ecosystem + "\x00" + node.Name
+		versions[key] = append(versions[key], node)
+	}
+	for _, key := range sortedKeys(versions) {
+		nodes := versions[key]
+		if len(nodes) < 2 {
+			continue
+		}
+		duplicate := duplicatePackage{Ecosystem: nodes[0].Ecosystem, Name: nodes[0].Name}
+		for _, node := range nodes {
+			node.Duplicate = true
+			version := duplicateVersion{ID: node.ID, Version: node.Version, RequiredBy: requiredBy[node.ID]}
+			if node.Direct {
+				version.RequiredBy = append([]string{"(project)"}, version.RequiredBy...)
+			}
+			duplicate.Versions = append(duplicate.Versions, version)
+		}
+		graph.Duplicates = append(graph.Duplicates, duplicate)
+	}
+	// The packages with the most copies matter most
+	sort.SliceStable(graph.Duplicates, func(i, j int) bool {
+		return len(graph.Duplicates[i].Versions) > len(graph.Duplicates[j].Versions)
+	})
+}
+
+// Breadth-first from the direct dependencies, so each package's chain is the shortest way it gets pulled in
+func (graph *dependencyGraph) findDeepestChains() {
+	parent := make(map[string]string)
+	var queue []*graphNode
+	for _, id := range graph.Direct {
+		graph.byID[id].Depth = 1
+		queue = append(queue, graph.byID[id])
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, target := range node.DependsOn {
+			if next := graph.byID[target]; next.Depth == 0 {
+				next.Depth = node.Depth + 1
+				parent[target] = node.ID
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// Only chains that end in a leaf, the rest are prefixes of a longer chain
+	isParent := make(map[string]bool)
+	for _, id := range parent {
+		isParent[id] = true
+	}
+	deepest := make([]*graphNode, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		if node.Depth > 2 && !isParent[node.ID] {
+			deepest = append(deepest, node)
+		}
+	}
+	sort.SliceStable(deepest, func(i, j int) bool { return deepest[i].Depth > deepest[j].Depth })
+	for _, node := range deepest {
+		if len(graph.DeepestChains) == depgraphReportLimit {
+			break
+		}
+		var chain []string
+		for id := node.ID; id != ""; id = parent[id] {
+			chain = append([]string{graph.byID[id].label()}, chain...)
+		}
+		graph.DeepestChains = append(graph.DeepestChains, chain)
+	}
+}
+
+func (graph *dependencyGraph) reachable(id string) map[string]bool {
+	seen := make(map[string]bool)
+	stack := append([]string(nil), graph.byID[id].DependsOn...)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[current] || current == id {
+			continue
+		}
+		seen[current] = true
+		stack = append(stack, graph.byID[current].DependsOn...)
+	}
+	return seen
+}
+
+// Transitive packages that pull in the most of the tree, and the direct dependencies responsible for them
+func (graph *dependencyGraph) findHeavyPackages() {
+	for _, node := range graph.Nodes {
+		node.TransitiveCount = len(graph.reachable(node.ID))
+	}
+	fromDirect := make(map[string]map[string]bool)
+	for _, id := range graph.Direct {
+		fromDirect[id] = graph.reachable(id)
+	}
+
+	// Dependencies a workspace package declares are direct for that package
+	declared := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		if node.Workspace {
+			for _, target := range node.DependsOn {
+				declared[target] = true
+			}
+		}
+	}
+	var transitive []*graphNode
+	for _, node := range graph.Nodes {
+		if !node.Direct && !declared[node.ID] && node.TransitiveCount > 0 {
+			transitive = append(transitive, node)
+		}
+	}
+	sort.SliceStable(transitive, func(i, j int) bool { return transitive[i].TransitiveCount > transitive[j].TransitiveCount })
+	for _, node := range transitive {
+		if len(graph.Heavy) == depgraphReportLimit {
+			break
+		}
+		heavy := heavyPackage{ID: node.ID, TransitiveCount: node.TransitiveCount}
+		for _, id := range graph.Direct {
+			if fromDirect[id][node.ID] {
+				heavy.PulledInBy = append(heavy.PulledInBy, graph.byID[id].label())
+			}
+		}
+		graph.Heavy = append(graph.Heavy, heavy)
+	}
+}
+
+func writeDependencyGraphSummary(output io.StringWriter, graph *dependencyGraph) {
+	edges := 0
+	for _, node := range graph.Nodes {
+		edges += len(node.DependsOn)
+	}
+	output.WriteString(fmt.Sprintf("Dependency graph: %d packages, %d direct, %d edges\n", len(graph.Nodes), len(graph.Direct), edges))
+
+	if len(graph.Duplicates) > 0 {
+		output.WriteString(fmt.Sprintf("Packages present in multiple versions (%d):\n", len(graph.Duplicates)))
+		for _, duplicate := range graph.Duplicates {
+			output.WriteString(fmt.Sprintf("  %s (%s): %d versions\n", duplicate.Name, duplicate.Ecosystem, len(duplicate.Versions)))
+			for _, version := range duplicate.Versions {
+				output.WriteString(fmt.Sprintf("    %s <- %s\n", version.Version, orDefault(strings.Join(version.RequiredBy, ", "), "(unresolved)")))
+			}
+		}
+	}
+	if len(graph.DeepestChains) > 0 {
+		output.WriteString("Deepest transitive chains:\n")
+		for _, chain := range graph.DeepestChains {
+			output.WriteString(fmt.Sprintf("  [%d] %s\n", len(chain), strings.Join(chain, " -> ")))
+		}
+	}
+	if len(graph.Heavy) > 0 {
+		output.WriteString("Heaviest transitive packages:\n")
+		for _, heavy := range graph.Heavy {
+			output.WriteString(fmt.Sprintf("  %s: %d transitive packages, via %s\n", graph.byID[heavy.ID].label(), heavy.TransitiveCount,
+				orDefault(strings.Join(heavy.PulledInBy, ", "), "(no direct dependency)")))
+		}
+	}
+}
+
+// Duplicated packages are filled red, direct dependencies drawn bold and dev-only packages dashed
+func renderDependencyGraphDOT(output io.StringWriter, graph *dependencyGraph) {
+	output.WriteString("digraph dependencies {\n")
+	output.WriteString("  rankdir=LR;\n")
+	output.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	output.WriteString(fmt.Sprintf("  %q [shape=doubleoctagon];\n", graph.Name))
+	for _, node := range graph.Nodes {
+		var attributes []string
+		attributes = append(attributes, fmt.Sprintf("label=%q", node.Name+"\n"+node.Version))
+		if node.Workspace {
+			attributes = append(attributes, "shape=folder")
+		}
+		var styles []string
+		if node.Duplicate {
+			styles = append(styles, "filled")
+			attributes = append(attributes, `fillcolor="#f8d7da"`, `color="#c0392b"`)
+		}
+		if node.Direct {
+			styles = append(styles, "bold")
+		}
+		if node.Scope == scopeDev {
+			styles = append(styles, "dashed")
+		}
+		if len(styles) > 0 {
+			attributes = append(attributes, fmt.Sprintf("style=%q", strings.Join(styles, ",")))
+		}
+		output.WriteString(fmt.Sprintf("  %q [%s];\n", node.ID, strings.Join(attributes, ", ")))
+	}
+	for _, id := range graph.Direct {
+		output.WriteString(fmt.Sprintf("  %q -> %q;\n", graph.Name, id))
+	}
+	for _, node := range graph.Nodes {
+		for _, target := range node.DependsOn {
+			output.WriteString(fmt.Sprintf("  %q -> %q;\n", node.ID, target))
+		}
+	}
+	output.WriteString("}\n")
+}
+
+func renderDependencyGraphMermaid(output io.StringWriter, graph *dependencyGraph) {
+	// Mermaid node IDs cannot contain purl punctuation, so nodes are numbered
+	ids := make(map[string]string)
+	for i, node := range graph.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i+1)
+	}
+	escape := strings.NewReplacer(`"`, "#quot;")
+
+	output.WriteString("graph LR\n")
+	output.WriteString(fmt.Sprintf("  root([\"%s\"])\n", escape.Replace(graph.Name)))
+	var duplicates, direct, dev []string
+	for _, node := range graph.Nodes {
+		output.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", ids[node.ID], escape.Replace(node.label())))
+		if node.Duplicate {
+			duplicates = append(duplicates, ids[node.ID])
+		}
+		if node.Direct {
+			direct = append(direct, ids[node.ID])
+		}
+		if node.Scope == scopeDev {
+			dev = append(dev, ids[node.ID])
+		}
+	}
+	for _, id := range graph.Direct {
+		output.WriteString(fmt.Sprintf("  root --> %s\n", ids[id]))
+	}
+	for _, node := range graph.Nodes {
+		for _, target := range node.DependsOn {
+			output.WriteString(fmt.Sprintf("  %s --> %s\n", ids[node.ID], ids[target]))
+		}
+	}
+
+	classes := []struct {
+		name, style string
+		nodes       []string
+	}{
+		{"direct", "stroke-width:3px", direct},
+		{"dev", "stroke-dasharray:5 5", dev},
+		{"duplicate", "fill:#f8d7da,stroke:#c0392b", duplicates},
+	}
+	for _, class := range classes {
+		if len(class.nodes) == 0 {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("  classDef %s %s\n", class.name, class.style))
+		output.WriteString(fmt.Sprintf("  class %s %s\n", strings.Join(class.nodes, ","), class.name))
+	}
+}
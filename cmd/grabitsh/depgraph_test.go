@@ -0,0 +1,67 @@
+package grabitsh
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// The graph parents of every package, keyed by label, with "(project)" for the root
+func dependencyGraphParents(graph *dependencyGraph) map[string][]string {
+	parents := make(map[string][]string)
+	for _, id := range graph.Direct {
+		parents[graph.byID[id].label()] = append(parents[graph.byID[id].label()], "(project)")
+	}
+	for _, node := range graph.Nodes {
+		for _, target := range node.DependsOn {
+			parents[graph.byID[target].label()] = append(parents[graph.byID[target].label()], node.label())
+		}
+	}
+	return parents
+}
+
+func TestDependencyGraphWorkspaceParents(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockfile string
+		files    map[string]string
+		parser   lockfileParser
+	}{
+		{"package-lock", "package-lock.json", map[string]string{
+			"package-lock.json": `{"lockfileVersion": 3, "packages": {
+				"": {"workspaces": ["packages/*"], "dependencies": {"lodash": "^4.0.0"}},
+				"packages/web": {"name": "web", "dependencies": {"react": "^17.0.0", "lodash": "^4.0.0"}},
+				"node_modules/web": {"resolved": "packages/web", "link": true},
+				"node_modules/lodash": {"version": "4.17.21"},
+				"node_modules/react": {"version": "18.2.0"},
+				"packages/web/node_modules/react": {"version": "17.0.2"}}}`,
+		}, parsePackageLock},
+		{"pnpm", "pnpm-lock.yaml", map[string]string{
+			"pnpm-lock.yaml": "lockfileVersion: '9.0'\nimporters:\n  .:\n    dependencies:\n      lodash:\n        specifier: ^4.0.0\n        version: 4.17.21\n" +
+				"  packages/web:\n    dependencies:\n      lodash:\n        specifier: ^4.0.0\n        version: 4.17.21\n      react:\n        specifier: ^17.0.0\n        version: 17.0.2\n" +
+				"packages:\n  lodash@4.17.21: {}\n  react@17.0.2: {}\n  react@18.2.0: {}\n",
+		}, parsePnpmLock},
+		{"yarn classic", "yarn.lock", map[string]string{
+			"package.json":              `{"workspaces": ["packages/*"], "dependencies": {"lodash": "^4.0.0"}}`,
+			"packages/web/package.json": `{"name": "web", "dependencies": {"react": "^17.0.0", "lodash": "^4.0.0"}}`,
+			"yarn.lock": "lodash@^4.0.0:\n  version \"4.17.21\"\n\nreact@^17.0.0:\n  version \"17.0.2\"\n\n" +
+				"react@^18.0.0:\n  version \"18.2.0\"\n",
+		}, parseYarnLock},
+	}
+	for _, test := range tests {
+		dir := writeTestFiles(t, test.files)
+		deps, err := test.parser(filepath.Join(dir, test.lockfile))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		web := filepath.ToSlash(filepath.Join(dir, "packages/web/package.json"))
+		want := map[string][]string{
+			"lodash@4.17.21": {"(project)", web},
+			"react@17.0.2":   {web},
+			web:              {"(project)"},
+		}
+		if got := dependencyGraphParents(buildDependencyGraph(deps)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: parents = %v, want %v", test.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package grabitsh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type DockerInstruction struct {
+	Command string `json:"command"`
+	Args    string `json:"args"`
+	Line    int    `json:"line"`
+}
+
+type DockerImageRef struct {
+	Raw    string `json:"raw"`
+	Name   string `json:"name"`
+	Tag    string `json:"tag,omitempty"`
+	Digest string `json:"digest,omitempty"`
+	// digest, tag, latest, stage (an earlier build stage), scratch or unresolved (uses an ARG)
+	Pinning string `json:"pinning"`
+}
+
+type DockerStage struct {
+	Index        int                 `json:"index"`
+	Name         string              `json:"name,omitempty"`
+	Base         DockerImageRef      `json:"base"`
+	Line         int                 `json:"line"`
+	Instructions []DockerInstruction `json:"instructions"`
+}
+
+type DockerfileAnalysis struct {
+	Path         string        `json:"path"`
+	Stages       []DockerStage `json:"stages"`
+	FinalUser    string        `json:"final_user"`
+	RunsAsRoot   bool          `json:"runs_as_root"`
+	ExposedPorts []string      `json:"exposed_ports"`
+	Healthcheck  string        `json:"healthcheck"`
+	Findings     []Finding     `json:"findings"`
+}
+
+var (
+	dockerSecretNameRegex = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api_?key|access_?key|private_?key|credential)`)
+	// Heredoc delimiters are words, so shell redirections such as <<2 or <<< do not start one
+	dockerHeredocRegex = regexp.MustCompile(`(?:^|[^<])<<-?["']?([A-Za-z_]\w*)["']?`)
+	// Options may come before the verb, as in apt-get -y install
+	dockerAptInstallRegex = regexp.MustCompile(`\bapt(?:-get)?(?:\s+-\S+)*\s+install\b`)
+)
+
+// Files named like a Dockerfile that contain at least one FROM instruction
+func findDockerfiles() []string {
+	var dockerfiles []string
+	for _, path := range findRepositoryFiles(isDockerfileName) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, instruction := range parseDockerInstructions(string(content)) {
+			if instruction.Command == "FROM" {
+				dockerfiles = append(dockerfiles, path)
+				break
+			}
+		}
+	}
+	return dockerfiles
+}
+
+func isDockerfileName(name string) bool {
+	if strings.HasSuffix(name, ".dockerignore") {
+		return false
+	}
+	return name == "Dockerfile" || strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+}
+
+// Split a Dockerfile into instructions, joining continuation lines and skipping heredoc bodies
+func parseDockerInstructions(content string) []DockerInstruction {
+	var instructions []DockerInstruction
+	escape := `\`
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	var current strings.Builder
+	startLine := 0
+	heredoc := ""
+	directivesAllowed := true
+
+	flush := func() {
+		text := strings.TrimSpace(current.String())
+		current.Reset()
+		if text == "" {
+			return
+		}
+		command, args, _ := strings.Cut(text, " ")
+		instructions = append(instructions, DockerInstruction{
+			Command: strings.ToUpper(command),
+			Args:    strings.TrimSpace(args),
+			Line:    startLine,
+		})
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if heredoc != "" {
+			if trimmed == heredoc {
+				heredoc = ""
+				flush()
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			if directivesAllowed {
+				if directive := strings.TrimSpace(strings.TrimPrefix(trimmed, "#")); strings.HasPrefix(strings.ToLower(directive), "escape=") {
+					escape = strings.TrimSpace(directive[len("escape="):])
+				}
+			}
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+		directivesAllowed = false
+
+		if current.Len() == 0 {
+			startLine = lineNumber
+		}
+		if strings.HasSuffix(trimmed, escape) {
+			current.WriteString(strings.TrimSuffix(trimmed, escape))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(trimmed)
+
+		if match := dockerHeredocRegex.FindStringSubmatch(trimmed); match != nil {
+			heredoc = match[1]
+			continue
+		}
+		flush()
+	}
+	flush()
+
+	return instructions
+}
+
+func analyzeDockerfile(path string) (DockerfileAnalysis, error) {
+	analysis := DockerfileAnalysis{Path: path}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return analysis, err
+	}
+	instructions := parseDockerInstructions(string(content))
+
+	// ARGs declared before the first FROM can be used in FROM lines
+	globalArgs := make(map[string]string)
+	stageNames := make(map[string]bool)
+	var stage *DockerStage
+
+	for _, instruction := range instructions {
+		if instruction.Command == "FROM" {
+			analysis.Stages = append(analysis.Stages, parseDockerFrom(instruction, len(analysis.Stages), globalArgs, stageNames))
+			stage = &analysis.Stages[len(analysis.Stages)-1]
+			if stage.Name != "" {
+				stageNames[strings.ToLower(stage.Name)] = true
+			}
+			continue
+		}
+		if stage == nil {
+			if instruction.Command == "ARG" {
+				name, value, _ := strings.Cut(instruction.Args, "=")
+				globalArgs[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"'`)
+			}
+			continue
+		}
+		stage.Instructions = append(stage.Instructions, instruction)
+	}
+
+	if len(analysis.Stages) == 0 {
+		return analysis, nil
+	}
+
+	final := analysis.Stages[len(analysis.Stages)-1]
+	analysis.FinalUser = "root (no USER instruction)"
+	analysis.RunsAsRoot = true
+	if user := dockerStageUser(analysis.Stages); user != "" {
+		name := strings.SplitN(user, ":", 2)[0]
+		analysis.FinalUser = user
+		analysis.RunsAsRoot = name == "root" || name == "0"
+	}
+	analysis.Healthcheck = "none"
+	for _, instruction := range final.Instructions {
+		switch instruction.Command {
+		case "EXPOSE":
+			analysis.ExposedPorts = append(analysis.ExposedPorts, strings.Fields(instruction.Args)...)
+		case "HEALTHCHECK":
+			if strings.EqualFold(strings.TrimSpace(instruction.Args), "NONE") {
+				analysis.Healthcheck = "disabled"
+			} else {
+				analysis.Healthcheck = instruction.Args
+			}
+		}
+	}
+
+	analysis.Findings = lintDockerfile(analysis)
+	return analysis, nil
+}
+
+// The USER in effect at the end of the final stage, following FROM lines that build on an earlier stage
+func dockerStageUser(stages []DockerStage) string {
+	stageUsers := make(map[string]string)
+	var user string
+	for _, stage := range stages {
+		user = ""
+		if stage.Base.Pinning == "stage" {
+			user = stageUsers[strings.ToLower(stage.Base.Raw)]
+		}
+		for _, instruction := range stage.Instructions {
+			if instruction.Command == "USER" {
+				user = instruction.Args
+			}
+		}
+		if stage.Name != "" {
+			stageUsers[strings.ToLower(stage.Name)] = user
+		}
+	}
+	return user
+}
+
+func parseDockerFrom(instruction DockerInstruction, index int, args map[string]string, stageNames map[string]bool) DockerStage {
+	stage := DockerStage{Index: index, Line: instruction.Line}
+
+	var fields []string
+	for _, field := range strings.Fields(instruction.Args) {
+		if !strings.HasPrefix(field, "--") {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+		stage.Name = fields[2]
+	}
+	if len(fields) > 0 {
+		stage.Base = parseDockerImageRef(expandDockerArgs(fields[0], args), stageNames)
+	}
+	return stage
+}
+
+func expandDockerArgs(value string, args map[string]string) string {
+	return os.Expand(value, func(name string) string {
+		// ${VAR:-default} falls back to the default when the ARG has no value
+		name, fallback, _ := strings.Cut(name, ":-")
+		if v, ok := args[name]; ok && v != "" {
+			return v
+		}
+		if fallback != "" {
+			return fallback
+		}
+		return "${" + name + "}"
+	})
+}
+
+func parseDockerImageRef(raw string, stageNames map[string]bool) DockerImageRef {
+	ref := DockerImageRef{Raw: raw, Name: raw}
+
+	switch {
+	case strings.Contains(raw, "${"):
+		ref.Pinning = "unresolved"
+		return ref
+	case strings.EqualFold(raw, "scratch"):
+		ref.Pinning = "scratch"
+		return ref
+	case stageNames[strings.ToLower(raw)]:
+		ref.Pinning = "stage"
+		return ref
+	}
+
+	name := raw
+	if at := strings.Index(name, "@"); at != -1 {
+		ref.Digest = name[at+1:]
+		name = name[:at]
+	}
+	// A colon after the last slash separates the tag; earlier colons belong to a registry port
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		ref.Tag = name[colon+1:]
+		name = name[:colon]
+	}
+	ref.Name = name
+
+	switch {
+	case ref.Digest != "":
+		ref.Pinning = "digest"
+	case ref.Tag == "" || ref.Tag == "latest":
+		ref.Pinning = "latest"
+	default:
+		ref.Pinning = "tag"
+	}
+	return ref
+}
+
+func lintDockerfile(analysis DockerfileAnalysis) []Finding {
+	var findings []Finding
+	add := func(ruleID, description, category, severity, message string, line int) {
+		findings = append(findings, newFinding("dockerfile/"+ruleID, description, category, severity, message, analysis.Path, line, message))
+	}
+
+	for _, stage := range analysis.Stages {
+		if stage.Base.Pinning == "latest" {
+			add("unpinned-base-image", "Base image uses the latest tag", categorySecurity, "low",
+				fmt.Sprintf("Base image %s is not pinned to a tag or digest", stage.Base.Raw), stage.Line)
+		}
+
+		for _, instruction := range stage.Instructions {
+			switch instruction.Command {
+			case "ADD":
+				add("add-instead-of-copy", "ADD used where COPY is sufficient", categoryQuality, "low",
+					"Use COPY for local files and curl/wget for remote URLs instead of ADD", instruction.Line)
+			case "ENV", "ARG":
+				for _, name := range dockerVariableNames(instruction) {
+					if !dockerSecretNameRegex.MatchString(name) {
+						continue
+					}
+					ruleID := "secret-in-env"
+					if instruction.Command == "ARG" {
+						ruleID = "secret-in-arg"
+					}
+					add(ruleID, "Secret passed through "+instruction.Command, categorySecurity, "high",
+						fmt.Sprintf("%s %s is stored in the image metadata; use build secrets (RUN --mount=type=secret) instead", instruction.Command, name), instruction.Line)
+				}
+			case "RUN":
+				if dockerAptInstallRegex.MatchString(instruction.Args) && !strings.Contains(instruction.Args, "/var/lib/apt/lists") {
+					add("apt-no-cleanup", "apt-get install without cleaning the package lists", categoryQuality, "low",
+						"apt-get install without rm -rf /var/lib/apt/lists/* increases the image size", instruction.Line)
+				}
+			}
+		}
+	}
+
+	if len(analysis.Stages) > 0 {
+		final := analysis.Stages[len(analysis.Stages)-1]
+		if analysis.RunsAsRoot {
+			add("root-user", "Container runs as root", categorySecurity, "medium",
+				"The final stage runs as root; add a USER instruction with an unprivileged user", final.Line)
+		}
+		if analysis.Healthcheck == "none" {
+			add("missing-healthcheck", "No HEALTHCHECK instruction", categoryQuality, "info",
+				"The final image defines no HEALTHCHECK", final.Line)
+		}
+	}
+
+	return findings
+}
+
+// Variable names declared by an ENV or ARG instruction, in both "KEY=value" and legacy "KEY value" forms.
+// Build argument values end up in the image history even when supplied with --build-arg.
+func dockerVariableNames(instruction DockerInstruction) []string {
+	args := strings.TrimSpace(instruction.Args)
+	if !strings.Contains(args, "=") {
+		if fields := strings.Fields(args); len(fields) > 0 {
+			if instruction.Command == "ARG" {
+				return fields
+			}
+			return fields[:1]
+		}
+		return nil
+	}
+
+	var names []string
+	for _, field := range strings.Fields(args) {
+		name, _, _ := strings.Cut(field, "=")
+		if name != "" && (instruction.Command == "ARG" || strings.Contains(field, "=")) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func writeDockerfileAnalysis(output io.StringWriter, analysis DockerfileAnalysis) {
+	output.WriteString(fmt.Sprintf("Dockerfile: %s\n", analysis.Path))
+	output.WriteString(fmt.Sprintf("  Stages: %d\n", len(analysis.Stages)))
+	for i, stage := range analysis.Stages {
+		label := fmt.Sprintf("stage %d", stage.Index)
+		if stage.Name != "" {
+			label = stage.Name
+		}
+		if i == len(analysis.Stages)-1 {
+			label += " (final)"
+		}
+		output.WriteString(fmt.Sprintf("    - %s: FROM %s [%s]\n", label, stage.Base.Raw, stage.Base.Pinning))
+	}
+	if len(analysis.Stages) == 0 {
+		return
+	}
+	output.WriteString(fmt.Sprintf("  Final user: %s\n", analysis.FinalUser))
+	if len(analysis.ExposedPorts) > 0 {
+		output.WriteString(fmt.Sprintf("  Exposed ports: %s\n", strings.Join(analysis.ExposedPorts, ", ")))
+	}
+	output.WriteString(fmt.Sprintf("  Healthcheck: %s\n", analysis.Healthcheck))
+	if len(analysis.Findings) > 0 {
+		output.WriteString("  Issues:\n")
+		for _, finding := range analysis.Findings {
+			output.WriteString(fmt.Sprintf("    - [%s] line %d: %s\n", finding.Severity, finding.Line, finding.Message))
+		}
+	}
+}
+
+func dockerfileFindings() []Finding {
+	var findings []Finding
+	for _, path := range findDockerfiles() {
+		if analysis, err := analyzeDockerfile(path); err == nil {
+			findings = append(findings, analysis.Findings...)
+		}
+	}
+	return findings
+}
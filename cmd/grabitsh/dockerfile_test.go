@@ -0,0 +1,102 @@
+package grabitsh
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsDockerfileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Dockerfile", true},
+		{"Dockerfile.dev", true},
+		{"api.Dockerfile", true},
+		{"dockerfile.go", false},
+		{"dockerfile", false},
+		{"Dockerfile.dockerignore", false},
+		{"docker-compose.yml", false},
+	}
+	for _, test := range tests {
+		if got := isDockerfileName(test.name); got != test.want {
+			t.Errorf("isDockerfileName(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseDockerInstructionsHeredocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		commands []string
+	}{
+		{"heredoc body skipped", "FROM alpine\nRUN <<EOF\napk add curl\nEOF\nUSER app\n", []string{"FROM", "RUN", "USER"}},
+		{"quoted dash heredoc", "FROM alpine\nCOPY <<-'END' /etc/x\nvalue\nEND\nUSER app\n", []string{"FROM", "COPY", "USER"}},
+		{"fd redirection", "FROM alpine\nRUN cat <<2\nUSER app\n", []string{"FROM", "RUN", "USER"}},
+		{"here-string", "FROM alpine\nRUN cat <<<word\nUSER app\n", []string{"FROM", "RUN", "USER"}},
+	}
+	for _, test := range tests {
+		instructions := parseDockerInstructions(test.content)
+		var commands []string
+		for _, instruction := range instructions {
+			commands = append(commands, instruction.Command)
+		}
+		if len(commands) != len(test.commands) {
+			t.Errorf("%s: got instructions %v, want %v", test.name, commands, test.commands)
+			continue
+		}
+		for i := range commands {
+			if commands[i] != test.commands[i] {
+				t.Errorf("%s: got instructions %v, want %v", test.name, commands, test.commands)
+				break
+			}
+		}
+	}
+}
+
+func TestDockerAptInstallRegex(t *testing.T) {
+	tests := []struct {
+		run  string
+		want bool
+	}{
+		{"apt-get install -y curl", true},
+		{"apt-get -y install curl", true},
+		{"apt-get -q --no-install-recommends install curl", true},
+		{"apt install curl", true},
+		{"apt-get update", false},
+		{"npm install", false},
+	}
+	for _, test := range tests {
+		if got := dockerAptInstallRegex.MatchString(test.run); got != test.want {
+			t.Errorf("dockerAptInstallRegex.MatchString(%q) = %v, want %v", test.run, got, test.want)
+		}
+	}
+}
+
+func TestAnalyzeDockerfileFinalUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		user    string
+		root    bool
+	}{
+		{"no user", "FROM alpine\nCMD [\"sh\"]\n", "root (no USER instruction)", true},
+		{"final stage user", "FROM alpine\nUSER app:app\n", "app:app", false},
+		{"explicit root", "FROM alpine\nUSER app\nUSER 0\n", "0", true},
+		{"inherited from stage", "FROM alpine AS base\nUSER app\nFROM base\nCMD [\"sh\"]\n", "app", false},
+		{"inherited through stages", "FROM alpine AS base\nUSER app\nFROM base AS mid\nRUN true\nFROM mid\n", "app", false},
+		{"overridden after inherit", "FROM alpine AS base\nUSER app\nFROM BASE\nUSER root\n", "root", true},
+		{"not inherited from image", "FROM alpine AS build\nUSER app\nFROM alpine\n", "root (no USER instruction)", true},
+	}
+	for _, test := range tests {
+		dir := writeTestFiles(t, map[string]string{"Dockerfile": test.content})
+		analysis, err := analyzeDockerfile(filepath.Join(dir, "Dockerfile"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if analysis.FinalUser != test.user || analysis.RunsAsRoot != test.root {
+			t.Errorf("%s: FinalUser = %q, RunsAsRoot = %v, want %q, %v", test.name, analysis.FinalUser, analysis.RunsAsRoot, test.user, test.root)
+		}
+	}
+}
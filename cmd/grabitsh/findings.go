@@ -0,0 +1,229 @@
+package grabitsh
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	categorySecurity = "security"
+	categoryQuality  = "quality"
+	categoryLicense  = "license"
+
+	largeFileThreshold = 5 * 1024 * 1024
+)
+
+// Finding is the common shape of every security and quality issue grabitsh reports
+type Finding struct {
+	RuleID          string `json:"rule_id"`
+	RuleDescription string `json:"rule_description"`
+	Category        string `json:"category"`
+	// critical, high, medium, low or info
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+func newFinding(ruleID, ruleDescription, category, severity, message, file string, line int, identity string) Finding {
+	sum := sha256.Sum256([]byte(ruleID + "\x00" + file + "\x00" + identity))
+	return Finding{
+		RuleID:          ruleID,
+		RuleDescription: ruleDescription,
+		Category:        category,
+		Severity:        severity,
+		Message:         message,
+		File:            file,
+		Line:            line,
+		Fingerprint:     hex.EncodeToString(sum[:])[:16],
+	}
+}
+
+// Gather findings from every security and quality check
+func collectFindings() []Finding {
+	var findings []Finding
+
+	config, _ := loadGrabitConfig()
+
+	secrets := secretFindings(scanWorkingTreeForSecrets())
+	findings = append(findings, secrets...)
+	findings = append(findings, sensitiveFileFindings(detectSensitiveFiles(config.SensitiveFiles))...)
+	dependencies, _ := collectDependencies()
+	findings = append(findings, vulnerabilityFindings(dependencies)...)
+	findings = append(findings, dockerfileFindings()...)
+	findings = append(findings, kubernetesFindings()...)
+	// A literal provider credential is usually caught by the secret scanner as well; keep the secret finding
+	findings = append(findings, withoutCoveredLocations(terraformFindings(), secrets, "terraform/hardcoded-credentials")...)
+	findings = append(findings, ansibleFindings()...)
+	findings = append(findings, licenseFindings(config.Licenses, dependencies)...)
+	goProject, goGraph, _ := loadGoAnalysis()
+	findings = append(findings, goImportGraphFindings(goProject, goGraph)...)
+	findings = append(findings, qualityFindings()...)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].RuleID != findings[j].RuleID {
+			return findings[i].RuleID < findings[j].RuleID
+		}
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// Drop findings of the given rule whose file and line are already reported in covered
+func withoutCoveredLocations(findings, covered []Finding, ruleID string) []Finding {
+	locations := make(map[string]bool)
+	for _, finding := range covered {
+		locations[fmt.Sprintf("%s:%d", finding.File, finding.Line)] = true
+	}
+	var kept []Finding
+	for _, finding := range findings {
+		if finding.RuleID == ruleID && locations[fmt.Sprintf("%s:%d", finding.File, finding.Line)] {
+			continue
+		}
+		kept = append(kept, finding)
+	}
+	return kept
+}
+
+func secretFindings(result SecretScanResult) []Finding {
+	var findings []Finding
+	for _, secret := range result.Findings {
+		finding := newFinding("secret/"+secret.RuleID, secret.Description, categorySecurity, secret.Severity,
+			fmt.Sprintf("%s detected: %s", secret.Description, secret.Secret), secret.File, secret.Line, "")
+		// Reuse the secret fingerprint so allowlist entries and SARIF results line up
+		finding.Fingerprint = secret.Fingerprint
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+func sensitiveFileFindings(files []SensitiveFile) []Finding {
+	var findings []Finding
+	for _, file := range files {
+		severity := "low"
+		message := fmt.Sprintf("Sensitive file (%s) present but ignored by git", file.Category)
+		switch file.Status {
+		case "tracked":
+			severity = "high"
+			message = fmt.Sprintf("Sensitive file (%s) is committed to the repository", file.Category)
+		case "untracked", "unknown":
+			severity = "medium"
+			message = fmt.Sprintf("Sensitive file (%s) is not ignored and could be committed", file.Category)
+		}
+		ruleID := "sensitive-file/" + strings.ReplaceAll(file.Category, " ", "-")
+		findings = append(findings, newFinding(ruleID, "Sensitive file: "+file.Category, categorySecurity, severity, message, file.Path, 0, ""))
+	}
+	return findings
+}
+
+func vulnerabilityFindings(dependencies []Dependency) []Finding {
+	matches, _, err := matchVulnerabilities(dependencies)
+	if err != nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, match := range matches {
+		dep := match.Dependency
+		message := fmt.Sprintf("%s %s@%s is affected by %s", dep.Ecosystem, dep.Name, dep.Version, strings.Join(append([]string{match.ID}, match.Aliases...), " / "))
+		if match.Summary != "" {
+			message += ": " + match.Summary
+		}
+		if len(match.FixedVersions) > 0 {
+			message += fmt.Sprintf(" (fixed in %s)", strings.Join(match.FixedVersions, ", "))
+		}
+		description := match.Summary
+		if description == "" {
+			description = "Vulnerable dependency " + dep.Name
+		}
+		line := findLineContaining(dep.Source, dep.Name)
+		findings = append(findings, newFinding("vulnerable-dependency/"+match.ID, description, categorySecurity,
+			normalizeSeverity(match.Severity), message, dep.Source, line, dep.Name+"@"+dep.Version))
+	}
+	return findings
+}
+
+var todoMarkerRegex = regexp.MustCompile(`\b(TODO|FIXME)\b`)
+
+func qualityFindings() []Finding {
+	var findings []Finding
+	for _, file := range listRepositoryFiles() {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.Size() > largeFileThreshold {
+			findings = append(findings, newFinding("quality/large-file", "Large file committed to the repository", categoryQuality, "info",
+				fmt.Sprintf("File is %.1f MB", float64(info.Size())/(1024*1024)), file, 0, ""))
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil || isBinaryContent(content) || strings.HasPrefix(file, "grabitsh_chunk_") {
+			continue
+		}
+		scanner := bufio.NewScanner(strings.NewReader(string(content)))
+		scanner.Buffer(make([]byte, 64*1024), largeFileThreshold)
+		lineNumber := 0
+		for scanner.Scan() {
+			lineNumber++
+			line := scanner.Text()
+			if match := todoMarkerRegex.FindString(line); match != "" {
+				text := strings.TrimSpace(line)
+				findings = append(findings, newFinding("quality/"+strings.ToLower(match), match+" comment", categoryQuality, "info",
+					truncateLine(text, 200), file, lineNumber, text))
+			}
+		}
+	}
+	return findings
+}
+
+// Map free-form severities (GHSA ratings, CVSS labels) onto the finding scale
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(severity)
+	for _, level := range []string{"critical", "high", "moderate", "medium", "low"} {
+		if strings.HasPrefix(severity, level) {
+			if level == "moderate" {
+				return "medium"
+			}
+			return level
+		}
+	}
+	return "medium"
+}
+
+func findLineContaining(path, needle string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxSecretScanSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if strings.Contains(scanner.Text(), needle) {
+			return lineNumber
+		}
+	}
+	return 0
+}
+
+func truncateLine(line string, length int) string {
+	if len(line) > length {
+		return line[:length] + "..."
+	}
+	return line
+}
@@ -0,0 +1,24 @@
+package grabitsh
+
+import "testing"
+
+func TestSensitiveFileFindingsFingerprint(t *testing.T) {
+	statuses := []string{"untracked", "tracked", "ignored", "unknown"}
+	var fingerprint string
+	for _, status := range statuses {
+		findings := sensitiveFileFindings([]SensitiveFile{{Path: "config/.env", Category: "environment file", Status: status}})
+		if len(findings) != 1 {
+			t.Fatalf("%s: got %d findings, want 1", status, len(findings))
+		}
+		if fingerprint == "" {
+			fingerprint = findings[0].Fingerprint
+		} else if findings[0].Fingerprint != fingerprint {
+			t.Errorf("%s: fingerprint %s changed from %s", status, findings[0].Fingerprint, fingerprint)
+		}
+	}
+
+	other := sensitiveFileFindings([]SensitiveFile{{Path: "other/.env", Category: "environment file", Status: "tracked"}})
+	if other[0].Fingerprint == fingerprint {
+		t.Error("different paths share a fingerprint")
+	}
+}
@@ -0,0 +1,173 @@
+package grabitsh
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const maxStatusEntries = 20
+
+type GitStatusEntry struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"`
+	Code     string `json:"code"`
+}
+
+type GitStatus struct {
+	Staged       []GitStatusEntry `json:"staged"`
+	Unstaged     []GitStatusEntry `json:"unstaged"`
+	Untracked    []GitStatusEntry `json:"untracked"`
+	Conflicted   []GitStatusEntry `json:"conflicted"`
+	Ignored      []GitStatusEntry `json:"ignored"`
+	InProgress   []string         `json:"in_progress"`
+	ChangedFiles int              `json:"changed_files"`
+}
+
+func (s GitStatus) Clean() bool {
+	return s.ChangedFiles == 0 && len(s.InProgress) == 0
+}
+
+func collectGitStatus() (GitStatus, error) {
+	out, err := runCommandOutput("git", "status", "--porcelain=v1", "-z", "--ignored")
+	if err != nil {
+		return GitStatus{}, fmt.Errorf("error running git status: %w", err)
+	}
+
+	status := parseGitStatus(out)
+	status.InProgress = detectInProgressOperations(gitDir())
+	return status, nil
+}
+
+// Classify the NUL-separated records of git status --porcelain=v1 -z
+func parseGitStatus(out string) GitStatus {
+	var status GitStatus
+	changed := make(map[string]bool)
+	records := strings.Split(out, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		entry := GitStatusEntry{Code: record[:2], Path: record[3:]}
+		x, y := record[0], record[1]
+
+		// Renames and copies are followed by a record holding the original path
+		if x == 'R' || x == 'C' || y == 'R' || y == 'C' {
+			if i+1 < len(records) {
+				entry.OrigPath = records[i+1]
+				i++
+			}
+		}
+
+		switch {
+		case entry.Code == "??":
+			status.Untracked = append(status.Untracked, entry)
+			changed[entry.Path] = true
+		case entry.Code == "!!":
+			status.Ignored = append(status.Ignored, entry)
+		case isConflictCode(entry.Code):
+			status.Conflicted = append(status.Conflicted, entry)
+			changed[entry.Path] = true
+		default:
+			if x != ' ' {
+				status.Staged = append(status.Staged, entry)
+			}
+			if y != ' ' {
+				status.Unstaged = append(status.Unstaged, entry)
+			}
+			changed[entry.Path] = true
+		}
+	}
+	status.ChangedFiles = len(changed)
+	return status
+}
+
+func isConflictCode(code string) bool {
+	switch code {
+	case "DD", "AU", "UD", "UA", "DU", "AA", "UU":
+		return true
+	}
+	return false
+}
+
+func gitDir() string {
+	out, err := runCommandOutput("git", "rev-parse", "--git-dir")
+	if err != nil || strings.TrimSpace(out) == "" {
+		return ".git"
+	}
+	return strings.TrimSpace(out)
+}
+
+// Detect merge/rebase/cherry-pick/revert/bisect state from the marker files git leaves behind
+func detectInProgressOperations(dir string) []string {
+	var operations []string
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	if exists("MERGE_HEAD") {
+		operations = append(operations, "merge")
+	}
+	if exists("rebase-merge") {
+		operations = append(operations, "rebase (interactive or merge backend)")
+	} else if exists("rebase-apply") {
+		if exists("rebase-apply/applying") {
+			operations = append(operations, "am (applying mailbox patches)")
+		} else {
+			operations = append(operations, "rebase")
+		}
+	}
+	if exists("CHERRY_PICK_HEAD") {
+		operations = append(operations, "cherry-pick")
+	}
+	if exists("REVERT_HEAD") {
+		operations = append(operations, "revert")
+	}
+	if exists("BISECT_LOG") {
+		operations = append(operations, "bisect")
+	}
+
+	return operations
+}
+
+func writeGitStatus(buffer *bytes.Buffer, status GitStatus) {
+	if status.Clean() {
+		buffer.WriteString("Working tree clean: this snapshot matches the last commit.\n")
+	} else if status.ChangedFiles > 0 {
+		buffer.WriteString(fmt.Sprintf("This snapshot includes uncommitted changes to %d files (%d staged, %d unstaged, %d untracked, %d conflicted).\n",
+			status.ChangedFiles, len(status.Staged), len(status.Unstaged), len(status.Untracked), len(status.Conflicted)))
+	}
+
+	for _, operation := range status.InProgress {
+		buffer.WriteString(fmt.Sprintf("In-progress operation: %s\n", operation))
+	}
+
+	writeStatusEntries(buffer, "Conflicted", status.Conflicted)
+	writeStatusEntries(buffer, "Staged", status.Staged)
+	writeStatusEntries(buffer, "Unstaged", status.Unstaged)
+	writeStatusEntries(buffer, "Untracked", status.Untracked)
+	writeStatusEntries(buffer, "Ignored but present", status.Ignored)
+}
+
+func writeStatusEntries(buffer *bytes.Buffer, label string, entries []GitStatusEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	buffer.WriteString(fmt.Sprintf("%s (%d):\n", label, len(entries)))
+	for i, entry := range entries {
+		if i == maxStatusEntries {
+			buffer.WriteString(fmt.Sprintf("  ... and %d more\n", len(entries)-maxStatusEntries))
+			break
+		}
+		if entry.OrigPath != "" {
+			buffer.WriteString(fmt.Sprintf("  %s %s -> %s\n", entry.Code, entry.OrigPath, entry.Path))
+		} else {
+			buffer.WriteString(fmt.Sprintf("  %s %s\n", entry.Code, entry.Path))
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package grabitsh
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGitStatus(t *testing.T) {
+	out := strings.Join([]string{
+		"M  staged.go",
+		" M unstaged.go",
+		"MM both.go",
+		"R  new name.go", "old name.go",
+		" C copy.go", "source.go",
+		"UU conflict.go",
+		"AA added-twice.go",
+		"DU deleted-by-us.go",
+		"?? scratch/",
+		"!! build/",
+		"",
+	}, "\x00")
+	status := parseGitStatus(out)
+
+	entries := func(list []GitStatusEntry) []string {
+		var paths []string
+		for _, entry := range list {
+			path := entry.Code + " " + entry.Path
+			if entry.OrigPath != "" {
+				path += " <- " + entry.OrigPath
+			}
+			paths = append(paths, path)
+		}
+		return paths
+	}
+	tests := []struct {
+		name string
+		got  []GitStatusEntry
+		want []string
+	}{
+		{"staged", status.Staged, []string{"M  staged.go", "MM both.go", "R  new name.go <- old name.go"}},
+		{"unstaged", status.Unstaged, []string{" M unstaged.go", "MM both.go", " C copy.go <- source.go"}},
+		{"conflicted", status.Conflicted, []string{"UU conflict.go", "AA added-twice.go", "DU deleted-by-us.go"}},
+		{"untracked", status.Untracked, []string{"?? scratch/"}},
+		{"ignored", status.Ignored, []string{"!! build/"}},
+	}
+	for _, test := range tests {
+		if got := entries(test.got); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s = %q, want %q", test.name, got, test.want)
+		}
+	}
+	// Ignored files are not changes; a file both staged and unstaged counts once
+	if status.ChangedFiles != 9 {
+		t.Errorf("ChangedFiles = %d, want 9", status.ChangedFiles)
+	}
+	if clean := parseGitStatus("!! build/\x00"); !clean.Clean() {
+		t.Errorf("a tree with only ignored files should be clean: %+v", clean)
+	}
+}
+
+func TestDetectInProgressOperations(t *testing.T) {
+	tests := []struct {
+		files map[string]string
+		want  []string
+	}{
+		{map[string]string{"HEAD": ""}, nil},
+		{map[string]string{"MERGE_HEAD": ""}, []string{"merge"}},
+		{map[string]string{"rebase-merge/done": ""}, []string{"rebase (interactive or merge backend)"}},
+		{map[string]string{"rebase-apply/next": ""}, []string{"rebase"}},
+		{map[string]string{"rebase-apply/applying": ""}, []string{"am (applying mailbox patches)"}},
+		{map[string]string{"rebase-merge/done": "", "CHERRY_PICK_HEAD": ""}, []string{"rebase (interactive or merge backend)", "cherry-pick"}},
+		{map[string]string{"REVERT_HEAD": "", "BISECT_LOG": ""}, []string{"revert", "bisect"}},
+	}
+	for _, test := range tests {
+		if got := detectInProgressOperations(writeTestFiles(t, test.files)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("detectInProgressOperations(%v) = %v, want %v", test.files, got, test.want)
+		}
+	}
+}
+
+func TestWriteGitStatus(t *testing.T) {
+	var buffer bytes.Buffer
+	writeGitStatus(&buffer, GitStatus{})
+	if got, want := buffer.String(), "Working tree clean: this snapshot matches the last commit.\n"; got != want {
+		t.Errorf("clean status = %q, want %q", got, want)
+	}
+
+	var out strings.Builder
+	for i := 0; i < maxStatusEntries+3; i++ {
+		out.WriteString(fmt.Sprintf("?? file%d.txt\x00", i))
+	}
+	out.WriteString("R  b.go\x00a.go\x00")
+	status := parseGitStatus(out.String())
+	status.InProgress = []string{"merge"}
+	buffer.Reset()
+	writeGitStatus(&buffer, status)
+	report := buffer.String()
+	for _, want := range []string{
+		"uncommitted changes to 24 files (1 staged, 0 unstaged, 23 untracked, 0 conflicted)",
+		"In-progress operation: merge\n",
+		"Staged (1):\n  R  a.go -> b.go\n",
+		"Untracked (23):\n",
+		"  ... and 3 more\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package grabitsh
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+type GoModRequirement struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect,omitempty"`
+}
+
+type GoModReplacement struct {
+	Old        string `json:"old"`
+	OldVersion string `json:"old_version,omitempty"`
+	New        string `json:"new"`
+	NewVersion string `json:"new_version,omitempty"`
+}
+
+// Go treats a replacement that is a relative or absolute path, in either slash style, as a directory rather than a module
+func (replacement GoModReplacement) isLocal() bool {
+	target := strings.ReplaceAll(replacement.New, `\`, "/")
+	if target == "." || target == ".." || strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") || strings.HasPrefix(target, "/") {
+		return true
+	}
+	// Windows drive letter
+	return len(target) >= 2 && target[1] == ':' && ('a' <= target[0]|0x20 && target[0]|0x20 <= 'z')
+}
+
+type GoModRetraction struct {
+	// A single version or a [low, high] interval
+	Versions  string `json:"versions"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// The directives of a go.mod or go.work file
+type GoModFile struct {
+	File      string             `json:"file"`
+	Module    string             `json:"module,omitempty"`
+	Go        string             `json:"go,omitempty"`
+	Toolchain string             `json:"toolchain,omitempty"`
+	Godebug   []string           `json:"godebug,omitempty"`
+	Require   []GoModRequirement `json:"require,omitempty"`
+	Replace   []GoModReplacement `json:"replace,omitempty"`
+	Exclude   []GoModRequirement `json:"exclude,omitempty"`
+	Retract   []GoModRetraction  `json:"retract,omitempty"`
+	// go.work only
+	Use []string `json:"use,omitempty"`
+}
+
+type GoPackage struct {
+	ImportPath string   `json:"import_path"`
+	Module     string   `json:"module,omitempty"`
+	Dir        string   `json:"dir"`
+	Name       string   `json:"name"`
+	Files      int      `json:"files"`
+	TestFiles  int      `json:"test_files"`
+	Imports    []string `json:"imports"`
+	// package main with a main function
+	Command bool `json:"command,omitempty"`
+
+	// Where each import first appears in the package's non-test files
+	importSites map[string]goImportSite
+}
+
+type goImportSite struct {
+	File string
+	Line int
+}
+
+type GoGenerateDirective struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Command string `json:"command"`
+}
+
+type GoProject struct {
+	Modules   []*GoModFile `json:"modules"`
+	Workspace *GoModFile   `json:"workspace,omitempty"`
+	Packages  []*GoPackage `json:"packages"`
+	// Build constraint expressions and the files guarded by each
+	BuildConstraints map[string][]string   `json:"build_constraints,omitempty"`
+	BuildTags        []string              `json:"build_tags,omitempty"`
+	CgoFiles         []string              `json:"cgo_files,omitempty"`
+	CgoDirectives    []string              `json:"cgo_directives,omitempty"`
+	Generate         []GoGenerateDirective `json:"generate,omitempty"`
+	Errors           []string              `json:"errors,omitempty"`
+}
+
+// Parse go.mod or go.work with the go command's own parser
+func parseGoModFile(file string) (*GoModFile, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	mod := &GoModFile{File: file}
+	if path.Base(filepath.ToSlash(file)) == "go.work" {
+		work, err := modfile.ParseWork(file, content, nil)
+		if err != nil {
+			return nil, err
+		}
+		if work.Go != nil {
+			mod.Go = work.Go.Version
+		}
+		if work.Toolchain != nil {
+			mod.Toolchain = work.Toolchain.Name
+		}
+		mod.Godebug = goModGodebug(work.Godebug)
+		for _, use := range work.Use {
+			mod.Use = append(mod.Use, use.Path)
+		}
+		mod.Replace = goModReplacements(work.Replace)
+		return mod, nil
+	}
+
+	parsed, err := modfile.Parse(file, content, nil)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Module != nil {
+		mod.Module = parsed.Module.Mod.Path
+	}
+	if parsed.Go != nil {
+		mod.Go = parsed.Go.Version
+	}
+	if parsed.Toolchain != nil {
+		mod.Toolchain = parsed.Toolchain.Name
+	}
+	mod.Godebug = goModGodebug(parsed.Godebug)
+	for _, require := range parsed.Require {
+		mod.Require = append(mod.Require, GoModRequirement{Path: require.Mod.Path, Version: require.Mod.Version, Indirect: require.Indirect})
+	}
+	for _, exclude := range parsed.Exclude {
+		mod.Exclude = append(mod.Exclude, GoModRequirement{Path: exclude.Mod.Path, Version: exclude.Mod.Version})
+	}
+	mod.Replace = goModReplacements(parsed.Replace)
+	for _, retract := range parsed.Retract {
+		versions := retract.Low
+		if retract.High != retract.Low {
+			versions = fmt.Sprintf("[%s, %s]", retract.Low, retract.High)
+		}
+		mod.Retract = append(mod.Retract, GoModRetraction{Versions: versions, Rationale: retract.Rationale})
+	}
+	return mod, nil
+}
+
+func goModGodebug(settings []*modfile.Godebug) []string {
+	var godebug []string
+	for _, setting := range settings {
+		godebug = append(godebug, setting.Key+"="+setting.Value)
+	}
+	return godebug
+}
+
+func goModReplacements(replacements []*modfile.Replace) []GoModReplacement {
+	var result []GoModReplacement
+	for _, replace := range replacements {
+		result = append(result, GoModReplacement{Old: replace.Old.Path, OldVersion: replace.Old.Version,
+			New: replace.New.Path, NewVersion: replace.New.Version})
+	}
+	return result
+}
+
+// Directories the go command ignores when matching packages
+func isIgnoredGoPath(file string) bool {
+	for _, segment := range strings.Split(path.Dir(file), "/") {
+		if segment == "testdata" || (segment != "." && (strings.HasPrefix(segment, ".") || strings.HasPrefix(segment, "_"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// Every go.mod and go.work in the tree outside the directories the go command ignores
+func findGoModuleFiles() []string {
+	var files []string
+	for _, file := range findRepositoryFiles(func(name string) bool { return name == "go.mod" || name == "go.work" }) {
+		if !isIgnoredGoPath(file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func analyzeGoSources() *GoProject {
+	project := &GoProject{BuildConstraints: make(map[string][]string)}
+
+	// Every go.mod roots a module; packages belong to the nearest one above them
+	modules := make(map[string]string)
+	for _, file := range findGoModuleFiles() {
+		if path.Base(file) != "go.mod" {
+			continue
+		}
+		mod, err := parseGoModFile(file)
+		if err != nil {
+			project.Errors = append(project.Errors, err.Error())
+			continue
+		}
+		project.Modules = append(project.Modules, mod)
+		modules[path.Dir(file)] = mod.Module
+	}
+	if fileExists("go.work") {
+		work, err := parseGoModFile("go.work")
+		if err != nil {
+			project.Errors = append(project.Errors, err.Error())
+		}
+		project.Workspace = work
+	}
+	importPath := func(dir string) (string, string) {
+		for current := dir; ; current = path.Dir(current) {
+			if module, ok := modules[current]; ok {
+				rel := strings.TrimPrefix(strings.TrimPrefix(dir, current), "/")
+				if rel == "" || current == dir {
+					return module, module
+				}
+				return module + "/" + rel, module
+			}
+			if current == "." || current == "/" {
+				return dir, ""
+			}
+		}
+	}
+
+	packages := make(map[string]*GoPackage)
+	tags := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, file := range findRepositoryFiles(func(name string) bool { return strings.HasSuffix(name, ".go") }) {
+		if isIgnoredGoPath(file) {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			project.Errors = append(project.Errors, err.Error())
+			continue
+		}
+
+		dir := path.Dir(file)
+		test := strings.HasSuffix(file, "_test.go")
+		name := strings.TrimSuffix(parsed.Name.Name, "_test")
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &GoPackage{Dir: dir, Name: name, importSites: make(map[string]goImportSite)}
+			pkg.ImportPath, pkg.Module = importPath(dir)
+			packages[dir] = pkg
+		}
+		if test {
+			pkg.TestFiles++
+		} else {
+			pkg.Files++
+			for _, spec := range parsed.Imports {
+				if imported, err := strconv.Unquote(spec.Path.Value); err == nil && imported != "C" {
+					pkg.Imports = appendUnique(pkg.Imports, imported)
+					if _, ok := pkg.importSites[imported]; !ok {
+						pkg.importSites[imported] = goImportSite{File: file, Line: fset.Position(spec.Pos()).Line}
+					}
+				}
+			}
+			if parsed.Name.Name == "main" && hasMainFunc(parsed) {
+				pkg.Command = true
+			}
+		}
+
+		for _, spec := range parsed.Imports {
+			if spec.Path.Value != `"C"` {
+				continue
+			}
+			project.CgoFiles = append(project.CgoFiles, file)
+			// #cgo directives live in the comment directly above import "C"
+			doc := spec.Doc
+			if doc == nil {
+				for _, decl := range parsed.Decls {
+					if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Lparen == token.NoPos && gen.Specs[0] == spec {
+						doc = gen.Doc
+					}
+				}
+			}
+			if doc != nil {
+				for _, line := range strings.Split(doc.Text(), "\n") {
+					if strings.HasPrefix(strings.TrimSpace(line), "#cgo ") {
+						project.CgoDirectives = appendUnique(project.CgoDirectives, strings.TrimSpace(line))
+					}
+				}
+			}
+		}
+
+		for _, group := range parsed.Comments {
+			for _, comment := range group.List {
+				switch {
+				case strings.HasPrefix(comment.Text, "//go:generate "):
+					project.Generate = append(project.Generate, GoGenerateDirective{File: file, Line: fset.Position(comment.Pos()).Line,
+						Command: strings.TrimSpace(strings.TrimPrefix(comment.Text, "//go:generate "))})
+				case comment.Pos() < parsed.Package && (constraint.IsGoBuild(comment.Text) || constraint.IsPlusBuild(comment.Text)):
+					expr, err := constraint.Parse(comment.Text)
+					if err != nil {
+						continue
+					}
+					// A file with both forms reports only its //go:build line
+					if constraint.IsPlusBuild(comment.Text) && hasGoBuildLine(parsed) {
+						continue
+					}
+					project.BuildConstraints[expr.String()] = append(project.BuildConstraints[expr.String()], file)
+					expr.Eval(func(tag string) bool {
+						tags[tag] = true
+						return true
+					})
+				}
+			}
+		}
+	}
+
+	for _, dir := range sortedKeys(packages) {
+		sort.Strings(packages[dir].Imports)
+		project.Packages = append(project.Packages, packages[dir])
+	}
+	project.BuildTags = sortedKeys(tags)
+	return project
+}
+
+func hasMainFunc(file *ast.File) bool {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasGoBuildLine(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if constraint.IsGoBuild(comment.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The replace directive that applies to a required module version; a versioned replacement wins over a bare one
+func (mod *GoModFile) replacementFor(path, version string) (GoModReplacement, bool) {
+	var match GoModReplacement
+	found := false
+	for _, replacement := range mod.Replace {
+		if replacement.Old != path || (replacement.OldVersion != "" && replacement.OldVersion != version) {
+			continue
+		}
+		if !found || replacement.OldVersion != "" {
+			match, found = replacement, true
+		}
+	}
+	return match, found
+}
+
+// Standard library imports have no dot in their first path element
+func isStandardLibraryImport(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+func writeGoModFile(output io.StringWriter, mod *GoModFile) {
+	if mod.Module != "" {
+		output.WriteString(fmt.Sprintf("Module: %s (%s)\n", mod.Module, mod.File))
+	} else {
+		output.WriteString(fmt.Sprintf("Workspace: %s\n", mod.File))
+	}
+	if mod.Go != "" {
+		output.WriteString(fmt.Sprintf("  go %s\n", mod.Go))
+	}
+	if mod.Toolchain != "" {
+		output.WriteString(fmt.Sprintf("  toolchain %s\n", mod.Toolchain))
+	}
+	for _, setting := range mod.Godebug {
+		output.WriteString(fmt.Sprintf("  godebug %s\n", setting))
+	}
+	for _, use := range mod.Use {
+		output.WriteString(fmt.Sprintf("  use %s\n", use))
+	}
+	if len(mod.Require) > 0 {
+		indirect := 0
+		for _, requirement := range mod.Require {
+			if requirement.Indirect {
+				indirect++
+			}
+		}
+		output.WriteString(fmt.Sprintf("  require: %d direct, %d indirect\n", len(mod.Require)-indirect, indirect))
+	}
+	for _, replacement := range mod.Replace {
+		old := strings.TrimSpace(replacement.Old + " " + replacement.OldVersion)
+		replaced := strings.TrimSpace(replacement.New + " " + replacement.NewVersion)
+		if replacement.isLocal() {
+			replaced += " (local)"
+		}
+		output.WriteString(fmt.Sprintf("  replace %s => %s\n", old, replaced))
+	}
+	for _, exclusion := range mod.Exclude {
+		output.WriteString(fmt.Sprintf("  exclude %s %s\n", exclusion.Path, exclusion.Version))
+	}
+	for _, retraction := range mod.Retract {
+		line := "  retract " + retraction.Versions
+		if retraction.Rationale != "" {
+			line += " // " + retraction.Rationale
+		}
+		output.WriteString(line + "\n")
+	}
+}
+
+func writeGoProject(output io.StringWriter, project *GoProject) {
+	for _, mod := range project.Modules {
+		writeGoModFile(output, mod)
+	}
+	if project.Workspace != nil {
+		writeGoModFile(output, project.Workspace)
+	}
+
+	internal := make(map[string]bool)
+	for _, pkg := range project.Packages {
+		internal[pkg.ImportPath] = true
+	}
+	var commands []string
+	output.WriteString(fmt.Sprintf("Packages (%d):\n", len(project.Packages)))
+	for _, pkg := range project.Packages {
+		var std, local, external []string
+		for _, imported := range pkg.Imports {
+			switch {
+			case internal[imported]:
+				local = append(local, imported)
+			case isStandardLibraryImport(imported):
+				std = append(std, imported)
+			default:
+				external = append(external, imported)
+			}
+		}
+		output.WriteString(fmt.Sprintf("- %s (package %s, %d files, %d test files)\n", pkg.ImportPath, pkg.Name, pkg.Files, pkg.TestFiles))
+		if len(local) > 0 {
+			output.WriteString(fmt.Sprintf("    internal imports: %s\n", strings.Join(local, ", ")))
+		}
+		if len(external) > 0 {
+			output.WriteString(fmt.Sprintf("    external imports: %s\n", strings.Join(external, ", ")))
+		}
+		if len(std) > 0 {
+			output.WriteString(fmt.Sprintf("    standard library: %s\n", strings.Join(std, ", ")))
+		}
+		if pkg.Command {
+			commands = append(commands, fmt.Sprintf("%s (%s)", path.Base(pkg.ImportPath), pkg.Dir))
+		}
+	}
+	if len(commands) > 0 {
+		output.WriteString(fmt.Sprintf("Main packages (binaries): %s\n", strings.Join(commands, ", ")))
+	}
+
+	if len(project.BuildConstraints) > 0 {
+		output.WriteString(fmt.Sprintf("Build tags in use: %s\n", strings.Join(project.BuildTags, ", ")))
+		for _, expr := range sortedKeys(project.BuildConstraints) {
+			files := project.BuildConstraints[expr]
+			output.WriteString(fmt.Sprintf("  //go:build %s (%d files, e.g. %s)\n", expr, len(files), files[0]))
+		}
+	}
+	if len(project.CgoFiles) > 0 {
+		output.WriteString(fmt.Sprintf("cgo: %d files import \"C\": %s\n", len(project.CgoFiles), strings.Join(project.CgoFiles, ", ")))
+		for _, directive := range project.CgoDirectives {
+			output.WriteString(fmt.Sprintf("  %s\n", directive))
+		}
+	}
+	if len(project.Generate) > 0 {
+		output.WriteString("go:generate directives:\n")
+		for _, directive := range project.Generate {
+			output.WriteString(fmt.Sprintf("  %s:%d: %s\n", directive.File, directive.Line, directive.Command))
+		}
+	}
+	for _, err := range project.Errors {
+		output.WriteString(fmt.Sprintf("Error parsing %s\n", err))
+	}
+}
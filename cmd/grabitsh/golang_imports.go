@@ -0,0 +1,342 @@
+package grabitsh
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Packages listed as the most depended upon
+const goMostImportedLimit = 10
+
+type GoPackageUsage struct {
+	ImportPath string `json:"import_path"`
+	// Repository packages importing it directly
+	Importers int `json:"importers"`
+	// Repository packages depending on it directly or transitively
+	TransitiveImporters int `json:"transitive_importers"`
+}
+
+type LayeringViolation struct {
+	Rule    string `json:"rule"`
+	Package string `json:"package"`
+	Import  string `json:"import"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Reason  string `json:"reason"`
+}
+
+type GoImportGraph struct {
+	Packages int `json:"packages"`
+	Edges    int `json:"edges"`
+	// Each cycle starts and ends with the same package
+	PackageCycles [][]string          `json:"package_cycles,omitempty"`
+	ModuleCycles  [][]string          `json:"module_cycles,omitempty"`
+	MostImported  []GoPackageUsage    `json:"most_imported,omitempty"`
+	Violations    []LayeringViolation `json:"layering_violations,omitempty"`
+
+	edges    map[string][]string
+	packages map[string]*GoPackage
+}
+
+// The import graph between the repository's own packages, across every module of a go.work
+func buildGoImportGraph(project *GoProject, rules []LayeringRule) *GoImportGraph {
+	graph := &GoImportGraph{Packages: len(project.Packages), edges: make(map[string][]string), packages: make(map[string]*GoPackage)}
+	for _, pkg := range project.Packages {
+		graph.packages[pkg.ImportPath] = pkg
+	}
+	for _, pkg := range project.Packages {
+		for _, imported := range pkg.Imports {
+			if _, ok := graph.packages[imported]; ok {
+				graph.edges[pkg.ImportPath] = append(graph.edges[pkg.ImportPath], imported)
+				graph.Edges++
+			}
+		}
+	}
+
+	for _, component := range stronglyConnectedComponents(graph.edges) {
+		graph.PackageCycles = append(graph.PackageCycles, findCycle(graph.edges, component))
+	}
+
+	// Modules depend on each other through their packages' imports
+	moduleEdges := make(map[string][]string)
+	for from, targets := range graph.edges {
+		for _, to := range targets {
+			fromModule, toModule := graph.packages[from].Module, graph.packages[to].Module
+			if fromModule != "" && toModule != "" && fromModule != toModule {
+				moduleEdges[fromModule] = appendUnique(moduleEdges[fromModule], toModule)
+			}
+		}
+	}
+	for _, component := range stronglyConnectedComponents(moduleEdges) {
+		graph.ModuleCycles = append(graph.ModuleCycles, findCycle(moduleEdges, component))
+	}
+
+	graph.findMostImported()
+	graph.checkLayering(rules)
+	return graph
+}
+
+// Tarjan's algorithm, returning only the components that contain a cycle
+func stronglyConnectedComponents(edges map[string][]string) [][]string {
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, next := range edges[node] {
+			if _, seen := index[next]; !seen {
+				visit(next)
+				if lowlink[next] < lowlink[node] {
+					lowlink[node] = lowlink[next]
+				}
+			} else if onStack[next] && index[next] < lowlink[node] {
+				lowlink[node] = index[next]
+			}
+		}
+		if lowlink[node] != index[node] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, node := range sortedKeys(edges) {
+		if _, seen := index[node]; !seen {
+			visit(node)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+	return components
+}
+
+// Shortest cycle through the component's first node, found breadth-first within the component
+func findCycle(edges map[string][]string, component []string) []string {
+	members := make(map[string]bool)
+	for _, node := range component {
+		members[node] = true
+	}
+	start := component[0]
+	parent := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[node] {
+			if !members[next] {
+				continue
+			}
+			if next == start {
+				cycle := []string{start}
+				for current := node; current != start; current = parent[current] {
+					cycle = append([]string{current}, cycle...)
+				}
+				return append([]string{start}, cycle...)
+			}
+			if _, seen := parent[next]; !seen {
+				parent[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+	return append(component, start)
+}
+
+func (graph *GoImportGraph) findMostImported() {
+	importers := make(map[string][]string)
+	for from, targets := range graph.edges {
+		for _, to := range targets {
+			importers[to] = append(importers[to], from)
+		}
+	}
+	for _, target := range sortedKeys(importers) {
+		// Walk the reverse edges to count everything that depends on the package
+		seen := map[string]bool{target: true}
+		queue := []string{target}
+		for len(queue) > 0 {
+			for _, from := range importers[queue[0]] {
+				if !seen[from] {
+					seen[from] = true
+					queue = append(queue, from)
+				}
+			}
+			queue = queue[1:]
+		}
+		graph.MostImported = append(graph.MostImported, GoPackageUsage{ImportPath: target, Importers: len(importers[target]), TransitiveImporters: len(seen) - 1})
+	}
+	sort.SliceStable(graph.MostImported, func(i, j int) bool {
+		if graph.MostImported[i].Importers != graph.MostImported[j].Importers {
+			return graph.MostImported[i].Importers > graph.MostImported[j].Importers
+		}
+		return graph.MostImported[i].TransitiveImporters > graph.MostImported[j].TransitiveImporters
+	})
+	if len(graph.MostImported) > goMostImportedLimit {
+		graph.MostImported = graph.MostImported[:goMostImportedLimit]
+	}
+}
+
+// Match an import path against a layering pattern, absolute or relative to the package's module
+func goPackagePatternMatches(pattern, importPath, module string) bool {
+	candidates := []string{importPath}
+	if module != "" && importPath == module {
+		candidates = append(candidates, ".")
+	} else if module != "" && strings.HasPrefix(importPath, module+"/") {
+		candidates = append(candidates, strings.TrimPrefix(importPath, module+"/"))
+	}
+	pattern = strings.TrimPrefix(pattern, "./")
+	if pattern == "..." {
+		return true
+	}
+	for _, candidate := range candidates {
+		if base := strings.TrimSuffix(pattern, "/..."); base != pattern {
+			if candidate == base || strings.HasPrefix(candidate, base+"/") {
+				return true
+			}
+		} else if matched, _ := path.Match(pattern, candidate); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func goPatternsMatch(patterns []string, importPath, module string) bool {
+	for _, pattern := range patterns {
+		if goPackagePatternMatches(pattern, importPath, module) {
+			return true
+		}
+	}
+	return false
+}
+
+func (graph *GoImportGraph) checkLayering(rules []LayeringRule) {
+	for _, rule := range rules {
+		for _, importPath := range sortedKeys(graph.packages) {
+			pkg := graph.packages[importPath]
+			if !goPackagePatternMatches(rule.Package, pkg.ImportPath, pkg.Module) {
+				continue
+			}
+			for _, imported := range pkg.Imports {
+				// Imports of other repository packages match relative to their own module
+				importedModule := ""
+				internal, isInternal := graph.packages[imported]
+				if isInternal {
+					importedModule = internal.Module
+				}
+
+				reason := ""
+				switch {
+				case goPatternsMatch(rule.MustNotImport, imported, importedModule):
+					reason = "must not import " + imported
+				case isInternal && len(rule.MayOnlyImport) > 0 && !goPatternsMatch(rule.MayOnlyImport, imported, importedModule) &&
+					!goPackagePatternMatches(rule.Package, imported, importedModule):
+					reason = "may only import " + strings.Join(rule.MayOnlyImport, ", ")
+				default:
+					continue
+				}
+				if rule.Reason != "" {
+					reason += ": " + rule.Reason
+				}
+				site := pkg.importSites[imported]
+				graph.Violations = append(graph.Violations, LayeringViolation{Rule: rule.Package, Package: pkg.ImportPath, Import: imported,
+					File: site.File, Line: site.Line, Reason: reason})
+			}
+		}
+	}
+}
+
+var (
+	goAnalysisOnce  sync.Once
+	goProject       *GoProject
+	goImportGraph   *GoImportGraph
+	goAnalysisError error
+)
+
+// Go sources are parsed once per run; the report, the advanced analysis and the findings share the result.
+// Both are nil when the repository has no go.mod or go.work.
+func loadGoAnalysis() (*GoProject, *GoImportGraph, error) {
+	goAnalysisOnce.Do(func() {
+		if len(findGoModuleFiles()) == 0 {
+			return
+		}
+		config, err := loadGrabitConfig()
+		goProject = analyzeGoSources()
+		goImportGraph = buildGoImportGraph(goProject, config.Layering)
+		goAnalysisError = err
+	})
+	return goProject, goImportGraph, goAnalysisError
+}
+
+func goImportGraphFindings(project *GoProject, graph *GoImportGraph) []Finding {
+	if graph == nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, cycle := range graph.PackageCycles {
+		site := graph.packages[cycle[0]].importSites[cycle[1]]
+		findings = append(findings, newFinding("go/import-cycle", "Go packages import each other in a cycle", categoryQuality, "high",
+			"import cycle: "+strings.Join(cycle, " -> "), site.File, site.Line, strings.Join(cycle, " -> ")))
+	}
+	for _, cycle := range graph.ModuleCycles {
+		// Reported against the workspace, or else the go.mod of the first module in the cycle
+		moduleFile := "go.work"
+		if project.Workspace == nil {
+			for _, mod := range project.Modules {
+				if mod.Module == cycle[0] {
+					moduleFile = mod.File
+				}
+			}
+		}
+		findings = append(findings, newFinding("go/module-cycle", "Go modules depend on each other in a cycle", categoryQuality, "medium",
+			"module cycle: "+strings.Join(cycle, " -> "), moduleFile, 0, strings.Join(cycle, " -> ")))
+	}
+	for _, violation := range graph.Violations {
+		findings = append(findings, newFinding("go/layering-violation", "Go package import breaks a layering rule", categoryQuality, "medium",
+			fmt.Sprintf("%s %s", violation.Package, violation.Reason), violation.File, violation.Line, violation.Package+" -> "+violation.Import))
+	}
+	return findings
+}
+
+func writeGoImportGraph(output io.StringWriter, graph *GoImportGraph) {
+	output.WriteString(fmt.Sprintf("Internal import graph: %d packages, %d edges\n", graph.Packages, graph.Edges))
+	for _, cycle := range graph.PackageCycles {
+		output.WriteString(fmt.Sprintf("  Import cycle: %s\n", strings.Join(cycle, " -> ")))
+	}
+	for _, cycle := range graph.ModuleCycles {
+		output.WriteString(fmt.Sprintf("  Module cycle: %s\n", strings.Join(cycle, " -> ")))
+	}
+	if len(graph.MostImported) > 0 {
+		output.WriteString("Most depended-upon packages:\n")
+		for _, usage := range graph.MostImported {
+			output.WriteString(fmt.Sprintf("  %s: imported by %d packages, %d transitively\n", usage.ImportPath, usage.Importers, usage.TransitiveImporters))
+		}
+	}
+	if len(graph.Violations) > 0 {
+		output.WriteString(fmt.Sprintf("Layering violations (%d):\n", len(graph.Violations)))
+		for _, violation := range graph.Violations {
+			output.WriteString(fmt.Sprintf("  %s:%d: %s %s\n", violation.File, violation.Line, violation.Package, violation.Reason))
+		}
+	}
+}
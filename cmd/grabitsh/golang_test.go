@@ -0,0 +1,171 @@
+package grabitsh
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGoModReplacementIsLocal(t *testing.T) {
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"../b", true},
+		{"./vendor/b", true},
+		{".", true},
+		{"/opt/src/b", true},
+		{`..\b`, true},
+		{`C:\src\b`, true},
+		{"example.com/fork/b", false},
+		{"github.com/a/b", false},
+	}
+	for _, test := range tests {
+		if got := (GoModReplacement{New: test.target}).isLocal(); got != test.want {
+			t.Errorf("isLocal(%q) = %v, want %v", test.target, got, test.want)
+		}
+	}
+}
+
+func TestParseGoModDependenciesReplacements(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"go.mod": `module example.com/a
+
+go 1.21
+
+require (
+	example.com/b v0.0.0-00010101000000-000000000000
+	example.com/c v1.2.0
+	example.com/d v1.0.0 // indirect
+	example.com/e v1.5.0
+)
+
+replace example.com/b => ../b
+
+replace example.com/c => example.com/fork/c v1.2.1
+
+replace (
+	example.com/e v1.4.0 => ./e
+	example.com/e v1.5.0 => example.com/e v1.5.1
+)
+`,
+		"go.sum": "example.com/fork/c v1.2.1 h1:fork=\nexample.com/d v1.0.0 h1:d=\n",
+	})
+	path := filepath.Join(dir, "go.mod")
+	deps, err := parseGoModDependencies(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Dependency{
+		{Name: "example.com/b", Ecosystem: ecosystemGo, Source: path, Direct: true, Scope: scopeProd, Local: "../b"},
+		{Name: "example.com/fork/c", Version: "v1.2.1", Ecosystem: ecosystemGo, Source: path, Direct: true, Scope: scopeProd, Integrity: "h1:fork="},
+		{Name: "example.com/d", Version: "v1.0.0", Ecosystem: ecosystemGo, Source: path, Scope: scopeProd, Integrity: "h1:d="},
+		{Name: "example.com/e", Version: "v1.5.1", Ecosystem: ecosystemGo, Source: path, Direct: true, Scope: scopeProd},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("parseGoModDependencies() = %+v, want %+v", deps, want)
+	}
+	if purl := dependencyPURL(deps[0]); purl != "pkg:golang/example.com/b" {
+		t.Errorf("local replacement purl = %q, want no version", purl)
+	}
+}
+
+func TestParseGoModFile(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"go.mod": `module example.com/a
+
+go 1.22
+toolchain go1.22.4
+
+godebug (
+	default=go1.21
+	panicnil=1
+)
+
+require (
+	example.com/b v1.0.0
+	example.com/c v1.1.0 // indirect
+)
+
+exclude example.com/b v0.9.0
+
+replace example.com/b v1.0.0 => ../b
+
+// Published with a broken build
+retract v1.0.1
+
+retract [v0.1.0, v0.2.0] // Pre-release API
+`,
+		"go.work":    "go 1.22\n\nuse (\n\t.\n\t./tools\n)\n\nreplace example.com/c => example.com/c v1.1.1\n",
+		"bad/go.mod": "module example.com/bad\n\nrequire example.com/b\n",
+	})
+
+	mod, err := parseGoModFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &GoModFile{
+		File:      filepath.Join(dir, "go.mod"),
+		Module:    "example.com/a",
+		Go:        "1.22",
+		Toolchain: "go1.22.4",
+		Godebug:   []string{"default=go1.21", "panicnil=1"},
+		Require:   []GoModRequirement{{Path: "example.com/b", Version: "v1.0.0"}, {Path: "example.com/c", Version: "v1.1.0", Indirect: true}},
+		Exclude:   []GoModRequirement{{Path: "example.com/b", Version: "v0.9.0"}},
+		Replace:   []GoModReplacement{{Old: "example.com/b", OldVersion: "v1.0.0", New: "../b"}},
+		Retract: []GoModRetraction{
+			{Versions: "v1.0.1", Rationale: "Published with a broken build"},
+			{Versions: "[v0.1.0, v0.2.0]", Rationale: "Pre-release API"},
+		},
+	}
+	if !reflect.DeepEqual(mod, want) {
+		t.Errorf("parseGoModFile(go.mod) = %+v, want %+v", mod, want)
+	}
+
+	work, err := parseGoModFile(filepath.Join(dir, "go.work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWork := &GoModFile{
+		File:    filepath.Join(dir, "go.work"),
+		Go:      "1.22",
+		Use:     []string{".", "./tools"},
+		Replace: []GoModReplacement{{Old: "example.com/c", New: "example.com/c", NewVersion: "v1.1.1"}},
+	}
+	if !reflect.DeepEqual(work, wantWork) {
+		t.Errorf("parseGoModFile(go.work) = %+v, want %+v", work, wantWork)
+	}
+
+	if _, err := parseGoModFile(filepath.Join(dir, "bad", "go.mod")); err == nil {
+		t.Error("parseGoModFile accepted a require without a version")
+	}
+}
+
+func TestAnalyzeGoSourcesNestedModules(t *testing.T) {
+	chdirTestFiles(t, map[string]string{
+		"README.md":                    "not a Go repository at the root\n",
+		"services/api/go.mod":          "module example.com/api\n\ngo 1.22\n",
+		"services/api/main.go":         "package main\n\nimport \"example.com/api/store\"\n\nfunc main() { store.Open() }\n",
+		"services/api/store/store.go":  "package store\n\nfunc Open() {}\n",
+		"services/api/testdata/go.mod": "module example.com/ignored\n",
+		"tools/_old/go.mod":            "module example.com/old\n",
+	})
+	if got, want := findGoModuleFiles(), []string{"services/api/go.mod"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("findGoModuleFiles() = %v, want %v", got, want)
+	}
+	project := analyzeGoSources()
+	if len(project.Modules) != 1 || project.Modules[0].Module != "example.com/api" {
+		t.Fatalf("modules = %+v", project.Modules)
+	}
+	var importPaths []string
+	for _, pkg := range project.Packages {
+		importPaths = append(importPaths, pkg.ImportPath)
+	}
+	if want := []string{"example.com/api", "example.com/api/store"}; !reflect.DeepEqual(importPaths, want) {
+		t.Errorf("packages = %v, want %v", importPaths, want)
+	}
+	graph := buildGoImportGraph(project, nil)
+	if graph.Edges != 1 {
+		t.Errorf("graph edges = %d, want 1", graph.Edges)
+	}
+}
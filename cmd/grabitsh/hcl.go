@@ -0,0 +1,542 @@
+package grabitsh
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HCLBlock is a block of an HCL file; the file itself is the root block with an empty Type
+type HCLBlock struct {
+	Type       string                   `json:"type"`
+	Labels     []string                 `json:"labels,omitempty"`
+	Attributes map[string]*HCLAttribute `json:"attributes,omitempty"`
+	Blocks     []*HCLBlock              `json:"blocks,omitempty"`
+	Line       int                      `json:"line"`
+}
+
+// HCLAttribute keeps the expression source and, when it is a literal, its value
+type HCLAttribute struct {
+	Name  string      `json:"name"`
+	Raw   string      `json:"raw"`
+	Value interface{} `json:"value,omitempty"`
+	Line  int         `json:"line"`
+}
+
+// HCLExpression is an expression that cannot be evaluated without a Terraform run (references, function calls, ...)
+type HCLExpression string
+
+type hclParser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+// Parse an HCL file into its block structure. Expressions are not evaluated beyond literals.
+func parseHCL(content string) (*HCLBlock, error) {
+	parser := &hclParser{src: []rune(content), line: 1}
+	root := &HCLBlock{Line: 1, Attributes: make(map[string]*HCLAttribute)}
+	err := parser.parseBody(root, false)
+	return root, err
+}
+
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *hclParser) peek(offset int) rune {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return 0
+}
+
+func (p *hclParser) advance() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+// Skip whitespace and comments; newlines are only skipped when skipNewlines is set
+func (p *hclParser) skipSpace(skipNewlines bool) {
+	for p.pos < len(p.src) {
+		r := p.peek(0)
+		switch {
+		case r == '\n' && !skipNewlines:
+			return
+		case unicode.IsSpace(r):
+			p.advance()
+		case r == '#' || (r == '/' && p.peek(1) == '/'):
+			for p.pos < len(p.src) && p.peek(0) != '\n' {
+				p.advance()
+			}
+		case r == '/' && p.peek(1) == '*':
+			p.advance()
+			p.advance()
+			for p.pos < len(p.src) && !(p.peek(0) == '*' && p.peek(1) == '/') {
+				p.advance()
+			}
+			if p.pos < len(p.src) {
+				p.advance()
+				p.advance()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isHCLIdentRune(r rune, first bool) bool {
+	if unicode.IsLetter(r) || r == '_' {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || r == '-')
+}
+
+func (p *hclParser) readIdentifier() string {
+	start := p.pos
+	for p.pos < len(p.src) && isHCLIdentRune(p.peek(0), p.pos == start) {
+		p.advance()
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *hclParser) parseBody(block *HCLBlock, nested bool) error {
+	for {
+		p.skipSpace(true)
+		if p.pos >= len(p.src) {
+			if nested {
+				return p.errorf("unexpected end of file in block %s", block.Type)
+			}
+			return nil
+		}
+		if p.peek(0) == '}' {
+			if !nested {
+				return p.errorf("unexpected '}'")
+			}
+			p.advance()
+			return nil
+		}
+
+		line := p.line
+		name := p.readIdentifier()
+		if name == "" {
+			return p.errorf("unexpected character %q", p.peek(0))
+		}
+		p.skipSpace(false)
+
+		if p.peek(0) == '=' && p.peek(1) != '=' {
+			p.advance()
+			p.skipSpace(false)
+			raw, err := p.readExpression()
+			if err != nil {
+				return err
+			}
+			value, _ := evalHCLLiteral(raw)
+			block.Attributes[name] = &HCLAttribute{Name: name, Raw: raw, Value: value, Line: line}
+			continue
+		}
+
+		child := &HCLBlock{Type: name, Line: line, Attributes: make(map[string]*HCLAttribute)}
+		for {
+			p.skipSpace(false)
+			switch r := p.peek(0); {
+			case r == '"':
+				label, err := p.readQuoted()
+				if err != nil {
+					return err
+				}
+				unquoted, _ := strconv.Unquote(label)
+				child.Labels = append(child.Labels, unquoted)
+				continue
+			case isHCLIdentRune(r, true):
+				child.Labels = append(child.Labels, p.readIdentifier())
+				continue
+			case r == '{':
+				p.advance()
+			default:
+				return p.errorf("expected '{' after block %s", name)
+			}
+			break
+		}
+		if err := p.parseBody(child, true); err != nil {
+			return err
+		}
+		block.Blocks = append(block.Blocks, child)
+	}
+}
+
+// Read a quoted string including its quotes, honouring escapes and ${...} templates
+func (p *hclParser) readQuoted() (string, error) {
+	start := p.pos
+	p.advance()
+	for p.pos < len(p.src) {
+		r := p.advance()
+		switch {
+		case r == '\\':
+			if p.pos < len(p.src) {
+				p.advance()
+			}
+		case (r == '$' || r == '%') && p.peek(0) == '{':
+			p.advance()
+			if _, err := p.readUntilClose('}'); err != nil {
+				return "", err
+			}
+		case r == '"':
+			return string(p.src[start:p.pos]), nil
+		case r == '\n':
+			return "", p.errorf("unterminated string")
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// Read nested expression text up to and including the matching closing bracket
+func (p *hclParser) readUntilClose(closing rune) (string, error) {
+	start := p.pos
+	for p.pos < len(p.src) {
+		switch r := p.peek(0); r {
+		case '"':
+			if _, err := p.readQuoted(); err != nil {
+				return "", err
+			}
+		case '{':
+			p.advance()
+			if _, err := p.readUntilClose('}'); err != nil {
+				return "", err
+			}
+		case '[':
+			p.advance()
+			if _, err := p.readUntilClose(']'); err != nil {
+				return "", err
+			}
+		case '(':
+			p.advance()
+			if _, err := p.readUntilClose(')'); err != nil {
+				return "", err
+			}
+		case '<':
+			if err := p.readHeredocIfPresent(); err != nil {
+				return "", err
+			}
+		case '#', '/':
+			if r == '#' || p.peek(1) == '/' || p.peek(1) == '*' {
+				p.skipSpace(true)
+			} else {
+				p.advance()
+			}
+		case closing:
+			p.advance()
+			return string(p.src[start:p.pos]), nil
+		case '}', ']', ')':
+			return "", p.errorf("unexpected %q", r)
+		default:
+			p.advance()
+		}
+	}
+	return "", p.errorf("missing %q", closing)
+}
+
+func (p *hclParser) readHeredocIfPresent() error {
+	if p.peek(1) != '<' {
+		p.advance()
+		return nil
+	}
+	rest := string(p.src[p.pos:])
+	header := strings.SplitN(rest, "\n", 2)[0]
+	marker := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(header, "<<"), "-"))
+	if marker == "" || strings.IndexFunc(marker, func(r rune) bool { return !isHCLIdentRune(r, false) }) != -1 {
+		p.advance()
+		p.advance()
+		return nil
+	}
+	for p.pos < len(p.src) && p.peek(0) != '\n' {
+		p.advance()
+	}
+	for p.pos < len(p.src) {
+		p.advance()
+		lineStart := p.pos
+		for p.pos < len(p.src) && p.peek(0) != '\n' {
+			p.advance()
+		}
+		if strings.TrimSpace(string(p.src[lineStart:p.pos])) == marker {
+			return nil
+		}
+	}
+	return p.errorf("unterminated heredoc %s", marker)
+}
+
+// Read an attribute expression, which ends at a newline outside of brackets
+func (p *hclParser) readExpression() (string, error) {
+	start := p.pos
+	for p.pos < len(p.src) {
+		switch r := p.peek(0); r {
+		case '\n':
+			return strings.TrimSpace(string(p.src[start:p.pos])), nil
+		case '"':
+			if _, err := p.readQuoted(); err != nil {
+				return "", err
+			}
+		case '{':
+			p.advance()
+			if _, err := p.readUntilClose('}'); err != nil {
+				return "", err
+			}
+		case '[':
+			p.advance()
+			if _, err := p.readUntilClose(']'); err != nil {
+				return "", err
+			}
+		case '(':
+			p.advance()
+			if _, err := p.readUntilClose(')'); err != nil {
+				return "", err
+			}
+		case '<':
+			if err := p.readHeredocIfPresent(); err != nil {
+				return "", err
+			}
+		case '}':
+			// End of a single-line block such as `tags = { Name = "x" }`
+			return strings.TrimSpace(string(p.src[start:p.pos])), nil
+		case '#', '/':
+			if r == '#' || p.peek(1) == '/' || p.peek(1) == '*' {
+				raw := strings.TrimSpace(string(p.src[start:p.pos]))
+				p.skipSpace(false)
+				if p.peek(0) == '\n' || p.pos >= len(p.src) {
+					return raw, nil
+				}
+				continue
+			}
+			p.advance()
+		default:
+			p.advance()
+		}
+	}
+	return strings.TrimSpace(string(p.src[start:p.pos])), nil
+}
+
+// Evaluate literal expressions: strings, numbers, bools, null, tuples and objects.
+// Anything else is returned as an HCLExpression holding the source text.
+func evalHCLLiteral(raw string) (interface{}, bool) {
+	evaluator := &hclParser{src: []rune(raw), line: 1}
+	value, ok := evaluator.literal()
+	evaluator.skipSpace(true)
+	if !ok || evaluator.pos < len(evaluator.src) {
+		return HCLExpression(raw), false
+	}
+	return value, true
+}
+
+func (p *hclParser) literal() (interface{}, bool) {
+	p.skipSpace(true)
+	if p.pos >= len(p.src) {
+		return nil, false
+	}
+	start := p.pos
+	switch r := p.peek(0); {
+	case r == '"':
+		quoted, err := p.readQuoted()
+		if err != nil {
+			return nil, false
+		}
+		return unquoteHCLString(quoted), true
+	case r == '<' && p.peek(1) == '<':
+		if err := p.readHeredocIfPresent(); err != nil {
+			return nil, false
+		}
+		return heredocBody(string(p.src[start:p.pos])), true
+	case r == '-' || unicode.IsDigit(r):
+		p.advance()
+		for p.pos < len(p.src) && (unicode.IsDigit(p.peek(0)) || strings.ContainsRune(".eE+-", p.peek(0))) {
+			p.advance()
+		}
+		number, err := strconv.ParseFloat(string(p.src[start:p.pos]), 64)
+		if err != nil {
+			return nil, false
+		}
+		if number == float64(int64(number)) {
+			return int(number), true
+		}
+		return number, true
+	case r == '[':
+		p.advance()
+		items := []interface{}{}
+		for {
+			p.skipSpace(true)
+			if p.peek(0) == ']' {
+				p.advance()
+				return items, true
+			}
+			item, ok := p.literal()
+			if !ok {
+				return nil, false
+			}
+			items = append(items, item)
+			p.skipSpace(true)
+			if p.peek(0) == ',' {
+				p.advance()
+			}
+		}
+	case r == '{':
+		p.advance()
+		object := map[string]interface{}{}
+		for {
+			p.skipSpace(true)
+			if p.peek(0) == '}' {
+				p.advance()
+				return object, true
+			}
+			var key string
+			if p.peek(0) == '"' {
+				quoted, err := p.readQuoted()
+				if err != nil {
+					return nil, false
+				}
+				key = unquoteHCLString(quoted)
+			} else {
+				key = p.readIdentifier()
+			}
+			p.skipSpace(false)
+			if key == "" || (p.peek(0) != '=' && p.peek(0) != ':') {
+				return nil, false
+			}
+			p.advance()
+			p.skipSpace(false)
+			valueStart := p.pos
+			value, ok := p.literal()
+			if !ok {
+				// Keep the rest of the object usable when one value is an expression
+				p.pos = valueStart
+				raw, err := p.readExpressionInObject()
+				if err != nil {
+					return nil, false
+				}
+				value = HCLExpression(raw)
+			}
+			object[key] = value
+			p.skipSpace(false)
+			if p.peek(0) == ',' {
+				p.advance()
+			}
+		}
+	case isHCLIdentRune(r, true):
+		switch word := p.readIdentifier(); word {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// Like readExpression but also stops at a comma separating object items
+func (p *hclParser) readExpressionInObject() (string, error) {
+	start := p.pos
+	for p.pos < len(p.src) {
+		switch p.peek(0) {
+		case '\n', ',', '}':
+			return strings.TrimSpace(string(p.src[start:p.pos])), nil
+		case '"':
+			if _, err := p.readQuoted(); err != nil {
+				return "", err
+			}
+		case '{', '[', '(':
+			closing := map[rune]rune{'{': '}', '[': ']', '(': ')'}[p.advance()]
+			if _, err := p.readUntilClose(closing); err != nil {
+				return "", err
+			}
+		default:
+			p.advance()
+		}
+	}
+	return strings.TrimSpace(string(p.src[start:p.pos])), nil
+}
+
+func unquoteHCLString(quoted string) string {
+	// HCL escapes are a superset-compatible subset of Go's; template sequences stay as written
+	inner := strings.ReplaceAll(quoted, "$${", "${")
+	if value, err := strconv.Unquote(inner); err == nil {
+		return value
+	}
+	return strings.Trim(quoted, `"`)
+}
+
+func heredocBody(heredoc string) string {
+	lines := strings.Split(heredoc, "\n")
+	if len(lines) < 2 {
+		return ""
+	}
+	body := lines[1 : len(lines)-1]
+	if strings.HasPrefix(lines[0], "<<-") {
+		// Indented heredocs strip the smallest common indentation
+		indent := -1
+		for _, line := range body {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			width := len(line) - len(strings.TrimLeft(line, " \t"))
+			if indent == -1 || width < indent {
+				indent = width
+			}
+		}
+		for i, line := range body {
+			if len(line) >= indent && indent > 0 {
+				body[i] = line[indent:]
+			}
+		}
+	}
+	return strings.Join(body, "\n") + "\n"
+}
+
+// Child blocks of the given type, in file order
+func (block *HCLBlock) BlocksOfType(blockType string) []*HCLBlock {
+	var blocks []*HCLBlock
+	for _, child := range block.Blocks {
+		if child.Type == blockType {
+			blocks = append(blocks, child)
+		}
+	}
+	return blocks
+}
+
+// Literal string value of an attribute, or its source text when it is an expression
+func (block *HCLBlock) AttributeString(name string) string {
+	attribute, ok := block.Attributes[name]
+	if !ok {
+		return ""
+	}
+	switch value := attribute.Value.(type) {
+	case string:
+		return value
+	case HCLExpression:
+		return string(value)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", attribute.Value)
+}
+
+func (block *HCLBlock) Label(index int) string {
+	if index < len(block.Labels) {
+		return block.Labels[index]
+	}
+	return ""
+}
+
+func (block *HCLBlock) AttributeNames() []string {
+	names := make([]string, 0, len(block.Attributes))
+	for name := range block.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,139 @@
+package grabitsh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHCLStructure(t *testing.T) {
+	content := `# Comment
+terraform {
+  required_version = ">= 1.5"
+  backend "s3" {
+    bucket = "state"
+  }
+}
+
+// Another comment
+resource "aws_instance" "web" {
+  ami           = var.ami
+  instance_type = "t3.micro"
+  count         = 2
+  tags = {
+    Name = "web"
+    Env  = var.env
+  }
+  /* block comment */
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+locals {
+  ports   = [80, 443]
+  ratio   = 0.5
+  enabled = false
+  nothing = null
+  script  = <<-EOT
+    echo one
+      echo two
+  EOT
+  name = "${var.prefix}-app"
+}
+`
+	root, err := parseHCL(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Blocks) != 3 {
+		t.Fatalf("got %d top-level blocks, want 3", len(root.Blocks))
+	}
+
+	terraform := root.BlocksOfType("terraform")[0]
+	if got := terraform.AttributeString("required_version"); got != ">= 1.5" {
+		t.Errorf("required_version = %q", got)
+	}
+	backend := terraform.BlocksOfType("backend")[0]
+	if backend.Label(0) != "s3" || backend.AttributeString("bucket") != "state" {
+		t.Errorf("backend = %+v", backend)
+	}
+
+	resource := root.BlocksOfType("resource")[0]
+	if !reflect.DeepEqual(resource.Labels, []string{"aws_instance", "web"}) || resource.Line != 10 {
+		t.Errorf("resource labels %v at line %d", resource.Labels, resource.Line)
+	}
+	if got := resource.Attributes["ami"].Value; got != HCLExpression("var.ami") {
+		t.Errorf("ami = %#v, want expression", got)
+	}
+	if got := resource.Attributes["count"].Value; got != 2 {
+		t.Errorf("count = %#v", got)
+	}
+	wantTags := map[string]interface{}{"Name": "web", "Env": HCLExpression("var.env")}
+	if got := resource.Attributes["tags"].Value; !reflect.DeepEqual(got, wantTags) {
+		t.Errorf("tags = %#v, want %#v", got, wantTags)
+	}
+	if got := resource.AttributeNames(); !reflect.DeepEqual(got, []string{"ami", "count", "instance_type", "tags"}) {
+		t.Errorf("attribute names = %v", got)
+	}
+	if len(resource.BlocksOfType("lifecycle")) != 1 {
+		t.Error("lifecycle block missing")
+	}
+
+	locals := root.BlocksOfType("locals")[0]
+	tests := map[string]interface{}{
+		"ports":   []interface{}{80, 443},
+		"ratio":   0.5,
+		"enabled": false,
+		"nothing": nil,
+		"script":  "echo one\n  echo two\n",
+		"name":    "${var.prefix}-app",
+	}
+	for name, want := range tests {
+		attribute, ok := locals.Attributes[name]
+		if !ok {
+			t.Errorf("locals.%s missing", name)
+			continue
+		}
+		if !reflect.DeepEqual(attribute.Value, want) {
+			t.Errorf("locals.%s = %#v, want %#v", name, attribute.Value, want)
+		}
+	}
+}
+
+func TestEvalHCLLiteral(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want interface{}
+		ok   bool
+	}{
+		{`"plain"`, "plain", true},
+		{`"tab\tquote\""`, "tab\tquote\"", true},
+		{`"$${literal}"`, "${literal}", true},
+		{"-3", -3, true},
+		{"1e3", 1000, true},
+		{"true", true, true},
+		{`["a", 1, [true]]`, []interface{}{"a", 1, []interface{}{true}}, true},
+		{`{ "key" = "v", other: 2 }`, map[string]interface{}{"key": "v", "other": 2}, true},
+		{"var.name", HCLExpression("var.name"), false},
+		{`upper("x")`, HCLExpression(`upper("x")`), false},
+		{`"a" + "b"`, HCLExpression(`"a" + "b"`), false},
+	}
+	for _, test := range tests {
+		got, ok := evalHCLLiteral(test.raw)
+		if ok != test.ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("evalHCLLiteral(%q) = %#v, %v, want %#v, %v", test.raw, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestParseHCLErrors(t *testing.T) {
+	for _, content := range []string{
+		"resource \"a\" \"b\" {\n  name = \"x\"\n",
+		"name = \"unterminated\n",
+		"locals {\n  x = [1, 2\n}\n",
+	} {
+		if _, err := parseHCL(content); err == nil {
+			t.Errorf("parseHCL(%q) succeeded, want an error", content)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package grabitsh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	helmReleaseName = "release-name"
+	helmKubeVersion = "v1.29.0"
+)
+
+type HelmDependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository,omitempty"`
+	Condition  string `json:"condition,omitempty"`
+	// Version resolved in Chart.lock / requirements.lock
+	LockedVersion string `json:"locked_version,omitempty"`
+	// locked, not locked, no lock file
+	LockStatus string `json:"lock_status"`
+	Vendored   bool   `json:"vendored"`
+}
+
+type HelmChart struct {
+	Dir          string           `json:"dir"`
+	Name         string           `json:"name"`
+	Version      string           `json:"version"`
+	AppVersion   string           `json:"app_version,omitempty"`
+	APIVersion   string           `json:"api_version"`
+	Type         string           `json:"type,omitempty"`
+	Dependencies []HelmDependency `json:"dependencies,omitempty"`
+	// Top-level properties declared in values.schema.json, required ones marked with *
+	SchemaProperties []string    `json:"schema_properties,omitempty"`
+	ValueKeys        []string    `json:"value_keys,omitempty"`
+	Templates        []string    `json:"templates"`
+	Objects          []K8sObject `json:"objects"`
+	RenderErrors     []string    `json:"render_errors,omitempty"`
+	// Template functions this renderer does not implement; they render as empty strings
+	MissingFunctions []string `json:"missing_functions,omitempty"`
+
+	metadata map[string]interface{}
+	values   map[string]interface{}
+}
+
+// Capabilities.APIVersions, answering Has for the API groups built into Kubernetes
+type helmAPIVersions []string
+
+func (versions helmAPIVersions) Has(version string) bool {
+	group := strings.SplitN(version, "/", 2)[0]
+	return !strings.Contains(version, "/") || !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
+
+// .Files of a chart, read relative to the chart directory
+type helmFiles struct {
+	dir string
+}
+
+// Templates come from the scanned repository, so reads must stay inside the chart, symlinks included
+func (files helmFiles) Get(name string) string {
+	root, err := filepath.EvalSymlinks(files.dir)
+	if err != nil {
+		return ""
+	}
+	file, err := filepath.EvalSymlinks(filepath.Join(root, filepath.Clean(filepath.FromSlash(name))))
+	if err != nil {
+		return ""
+	}
+	relative, err := filepath.Rel(root, file)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) || filepath.IsAbs(relative) {
+		return ""
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+func (files helmFiles) GetBytes(name string) []byte {
+	return []byte(files.Get(name))
+}
+
+func (files helmFiles) Lines(name string) []string {
+	return strings.Split(strings.TrimSuffix(files.Get(name), "\n"), "\n")
+}
+
+func (files helmFiles) Glob(pattern string) helmFileSet {
+	set := helmFileSet{}
+	_ = filepath.Walk(files.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(files.dir, path)
+		if matchesPathPattern(pattern, filepath.ToSlash(rel)) {
+			set[filepath.ToSlash(rel)] = files.Get(rel)
+		}
+		return nil
+	})
+	return set
+}
+
+type helmFileSet map[string]string
+
+func (set helmFileSet) AsConfig() string {
+	data := make(map[string]string, len(set))
+	for name, content := range set {
+		data[filepath.Base(name)] = content
+	}
+	content, _ := yaml.Marshal(data)
+	return strings.TrimSuffix(string(content), "\n")
+}
+
+func (set helmFileSet) AsSecrets() string {
+	data := make(map[string]string, len(set))
+	for name, content := range set {
+		data[filepath.Base(name)] = base64.StdEncoding.EncodeToString([]byte(content))
+	}
+	content, _ := yaml.Marshal(data)
+	return strings.TrimSuffix(string(content), "\n")
+}
+
+func findHelmCharts() []*HelmChart {
+	var charts []*HelmChart
+	for _, path := range findRepositoryFiles(func(name string) bool { return name == "Chart.yaml" }) {
+		charts = append(charts, &HelmChart{Dir: filepath.ToSlash(filepath.Dir(path))})
+	}
+	return charts
+}
+
+// Directories holding chart templates, which are rendered rather than read as plain manifests
+func helmTemplateDirs() []string {
+	var dirs []string
+	for _, chart := range findHelmCharts() {
+		dirs = append(dirs, filepath.ToSlash(filepath.Join(chart.Dir, "templates"))+"/")
+	}
+	return dirs
+}
+
+func loadHelmChart(chart *HelmChart) error {
+	metadata, err := readYAMLMap(filepath.Join(chart.Dir, "Chart.yaml"))
+	if err != nil {
+		return err
+	}
+	chart.metadata = metadata
+	chart.Name = yamlString(metadata["name"])
+	chart.Version = yamlString(metadata["version"])
+	chart.AppVersion = yamlString(metadata["appVersion"])
+	chart.APIVersion = yamlString(metadata["apiVersion"])
+	chart.Type = yamlString(metadata["type"])
+
+	// Helm 2 charts declare dependencies in requirements.yaml and lock them in requirements.lock
+	dependencies, lockFile := yamlList(metadata["dependencies"]), "Chart.lock"
+	if chart.APIVersion == "v1" {
+		requirements, _ := readYAMLMap(filepath.Join(chart.Dir, "requirements.yaml"))
+		dependencies, lockFile = yamlList(requirements["dependencies"]), "requirements.lock"
+	}
+	locked := make(map[string]string)
+	lock, lockErr := readYAMLMap(filepath.Join(chart.Dir, lockFile))
+	for _, item := range yamlList(lock["dependencies"]) {
+		entry := yamlMap(item)
+		locked[yamlString(entry["name"])] = yamlString(entry["version"])
+	}
+	for _, item := range dependencies {
+		entry := yamlMap(item)
+		dependency := HelmDependency{
+			Name:       yamlString(entry["name"]),
+			Version:    yamlString(entry["version"]),
+			Repository: yamlString(entry["repository"]),
+			Condition:  yamlString(entry["condition"]),
+			LockStatus: "no lock file",
+		}
+		if lockErr == nil {
+			dependency.LockStatus = "not locked"
+			if version, ok := locked[dependency.Name]; ok {
+				dependency.LockedVersion = version
+				dependency.LockStatus = "locked"
+			}
+		}
+		archives, _ := filepath.Glob(filepath.Join(chart.Dir, "charts", dependency.Name+"-*.tgz"))
+		dependency.Vendored = len(archives) > 0 || dirExists(filepath.Join(chart.Dir, "charts", dependency.Name))
+		chart.Dependencies = append(chart.Dependencies, dependency)
+	}
+
+	chart.values, _ = readYAMLMap(filepath.Join(chart.Dir, "values.yaml"))
+	if chart.values == nil {
+		chart.values = map[string]interface{}{}
+	}
+	chart.ValueKeys = yamlKeysOrItems(chart.values)
+
+	if content, err := os.ReadFile(filepath.Join(chart.Dir, "values.schema.json")); err == nil {
+		var schema struct {
+			Properties map[string]interface{} `json:"properties"`
+			Required   []string               `json:"required"`
+		}
+		if err := json.Unmarshal(content, &schema); err != nil {
+			chart.RenderErrors = append(chart.RenderErrors, fmt.Sprintf("values.schema.json: %v", err))
+		}
+		required := make(map[string]bool)
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+		for name := range schema.Properties {
+			if required[name] {
+				name += "*"
+			}
+			chart.SchemaProperties = append(chart.SchemaProperties, name)
+		}
+		sort.Strings(chart.SchemaProperties)
+	}
+
+	renderHelmChart(chart)
+	return nil
+}
+
+// Chart.yaml keys and the field names Helm exposes them under in .Chart
+var helmChartFields = map[string]string{
+	"apiVersion": "APIVersion", "name": "Name", "version": "Version", "kubeVersion": "KubeVersion",
+	"description": "Description", "type": "Type", "keywords": "Keywords", "home": "Home", "sources": "Sources",
+	"dependencies": "Dependencies", "maintainers": "Maintainers", "icon": "Icon", "appVersion": "AppVersion",
+	"deprecated": "Deprecated", "annotations": "Annotations", "condition": "Condition", "tags": "Tags",
+}
+
+var helmUndefinedFunctionRegex = regexp.MustCompile(`function "([^"]+)" not defined`)
+
+// Parse a template, standing in an empty function for each one the renderer lacks so a single
+// unknown sprig function does not take every include of the chart down with it
+func parseHelmTemplate(root *template.Template, name, content string, chart *HelmChart) error {
+	for {
+		_, err := root.New(name).Parse(content)
+		match := helmUndefinedFunctionRegex.FindStringSubmatch(fmt.Sprint(err))
+		if err == nil || match == nil {
+			return err
+		}
+		chart.MissingFunctions = appendUnique(chart.MissingFunctions, match[1])
+		root.Funcs(template.FuncMap{match[1]: func(...interface{}) string { return "" }})
+	}
+}
+
+// Render the chart templates with default values, like `helm template` without a cluster
+func renderHelmChart(chart *HelmChart) {
+	templatesDir := filepath.Join(chart.Dir, "templates")
+	var root *template.Template
+	root = template.New(chart.Name).Funcs(helmTemplateFuncs(&root)).Option("missingkey=zero")
+
+	var renderable []string
+	_ = filepath.Walk(templatesDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(chart.Dir, path)
+		name := chart.Name + "/" + filepath.ToSlash(rel)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			chart.RenderErrors = append(chart.RenderErrors, fmt.Sprintf("%s: %v", rel, err))
+			return nil
+		}
+		if err := parseHelmTemplate(root, name, string(content), chart); err != nil {
+			chart.RenderErrors = append(chart.RenderErrors, fmt.Sprintf("%s: %v", rel, err))
+			return nil
+		}
+		chart.Templates = append(chart.Templates, filepath.ToSlash(rel))
+		// Partials (_helpers.tpl) only define named templates; NOTES.txt is not a manifest
+		if !strings.HasPrefix(info.Name(), "_") && info.Name() != "NOTES.txt" {
+			renderable = append(renderable, name)
+		}
+		return nil
+	})
+
+	chartValues := make(map[string]interface{})
+	for key, value := range chart.metadata {
+		if field, ok := helmChartFields[key]; ok {
+			chartValues[field] = value
+		}
+	}
+	kubeMajor, kubeMinor, _ := versionCore(helmKubeVersion)
+
+	for _, name := range renderable {
+		data := map[string]interface{}{
+			"Values": chart.values,
+			"Chart":  chartValues,
+			"Release": map[string]interface{}{
+				"Name": helmReleaseName, "Namespace": "default", "Service": "Helm",
+				"IsInstall": true, "IsUpgrade": false, "Revision": 1,
+			},
+			"Capabilities": map[string]interface{}{
+				"KubeVersion": map[string]interface{}{
+					"Version": helmKubeVersion, "GitVersion": helmKubeVersion,
+					"Major": fmt.Sprint(kubeMajor), "Minor": fmt.Sprint(kubeMinor),
+				},
+				"APIVersions": helmAPIVersions{},
+			},
+			"Template": map[string]interface{}{"Name": name, "BasePath": chart.Name + "/templates"},
+			"Files":    helmFiles{dir: chart.Dir},
+		}
+
+		var out bytes.Buffer
+		if err := root.ExecuteTemplate(&out, name, data); err != nil {
+			chart.RenderErrors = append(chart.RenderErrors, err.Error())
+			continue
+		}
+		rendered := strings.ReplaceAll(out.String(), "<no value>", "")
+		file := filepath.ToSlash(filepath.Join(chart.Dir, strings.TrimPrefix(name, chart.Name+"/")))
+		for _, object := range parseK8sManifest(file, rendered) {
+			// Line numbers of rendered output do not map back to the template source
+			object.Line = 0
+			object.Origin = "helm chart " + chart.Name
+			chart.Objects = append(chart.Objects, object)
+		}
+	}
+}
+
+func readYAMLMap(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := yaml.Unmarshal(content, &value); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return yamlMap(normalizeYAML(value)), nil
+}
+
+func loadHelmCharts() []*HelmChart {
+	charts := findHelmCharts()
+	for _, chart := range charts {
+		if err := loadHelmChart(chart); err != nil {
+			chart.RenderErrors = append(chart.RenderErrors, err.Error())
+		}
+	}
+	return charts
+}
+
+func helmObjects(charts []*HelmChart) []K8sObject {
+	var objects []K8sObject
+	for _, chart := range charts {
+		objects = append(objects, chart.Objects...)
+	}
+	return objects
+}
+
+func writeHelmCharts(output io.StringWriter, charts []*HelmChart) {
+	for _, chart := range charts {
+		output.WriteString(fmt.Sprintf("Helm chart: %s (%s)\n", chart.Name, chart.Dir))
+		output.WriteString(fmt.Sprintf("  Version: %s, appVersion: %s, apiVersion: %s\n", orDash(chart.Version), orDash(chart.AppVersion), orDash(chart.APIVersion)))
+		if chart.Type != "" {
+			output.WriteString(fmt.Sprintf("  Type: %s\n", chart.Type))
+		}
+
+		if len(chart.Dependencies) > 0 {
+			output.WriteString("  Dependencies:\n")
+			for _, dependency := range chart.Dependencies {
+				status := dependency.LockStatus
+				if dependency.LockedVersion != "" {
+					status += " at " + dependency.LockedVersion
+				}
+				if dependency.Vendored {
+					status += ", vendored"
+				}
+				line := fmt.Sprintf("    - %s %s", dependency.Name, dependency.Version)
+				if dependency.Repository != "" {
+					line += " from " + dependency.Repository
+				}
+				if dependency.Condition != "" {
+					line += " (if " + dependency.Condition + ")"
+				}
+				output.WriteString(line + " [" + status + "]\n")
+			}
+		}
+
+		if len(chart.SchemaProperties) > 0 {
+			output.WriteString(fmt.Sprintf("  Values schema properties (* required): %s\n", strings.Join(chart.SchemaProperties, ", ")))
+		} else {
+			output.WriteString("  Values schema: none\n")
+		}
+		if len(chart.ValueKeys) > 0 {
+			output.WriteString(fmt.Sprintf("  Default values: %s\n", strings.Join(chart.ValueKeys, ", ")))
+		}
+
+		output.WriteString(fmt.Sprintf("  Templates: %d, rendered objects: %d\n", len(chart.Templates), len(chart.Objects)))
+		for _, object := range chart.Objects {
+			output.WriteString(fmt.Sprintf("    - %s [%s]\n", object.displayName(), object.File))
+		}
+		if len(chart.MissingFunctions) > 0 {
+			output.WriteString(fmt.Sprintf("  Unsupported template functions (rendered empty): %s\n", strings.Join(chart.MissingFunctions, ", ")))
+		}
+		for _, renderError := range chart.RenderErrors {
+			output.WriteString(fmt.Sprintf("  Error rendering: %s\n", renderError))
+		}
+		output.WriteString("\n")
+	}
+}
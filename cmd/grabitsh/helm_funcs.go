@@ -0,0 +1,796 @@
+package grabitsh
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/adler32"
+	"math"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Template functions available to chart templates: the Helm builtins plus the commonly used part of sprig.
+// Functions that depend on a live cluster or randomness return fixed placeholders so renders are reproducible.
+func helmTemplateFuncs(root **template.Template) template.FuncMap {
+	return template.FuncMap{
+		"include": func(name string, data interface{}) (string, error) {
+			t := (*root).Lookup(name)
+			if t == nil {
+				return "", fmt.Errorf("template %q not defined", name)
+			}
+			var out bytes.Buffer
+			err := t.Execute(&out, data)
+			return out.String(), err
+		},
+		"tpl": func(text string, data interface{}) (string, error) {
+			clone, err := (*root).Clone()
+			if err != nil {
+				return "", err
+			}
+			t, err := clone.New("tpl").Parse(text)
+			if err != nil {
+				return "", err
+			}
+			var out bytes.Buffer
+			err = t.Execute(&out, data)
+			return strings.ReplaceAll(out.String(), "<no value>", ""), err
+		},
+		"required": func(message string, value interface{}) (interface{}, error) {
+			if value == nil || value == "" {
+				return nil, errors.New(message)
+			}
+			return value, nil
+		},
+		"fail":   func(message string) (string, error) { return "", errors.New(message) },
+		"lookup": func(...interface{}) map[string]interface{} { return map[string]interface{}{} },
+
+		"default": func(fallback interface{}, given ...interface{}) interface{} {
+			if len(given) == 0 || helmEmpty(given[0]) {
+				return fallback
+			}
+			return given[0]
+		},
+		"empty": helmEmpty,
+		"coalesce": func(values ...interface{}) interface{} {
+			for _, value := range values {
+				if !helmEmpty(value) {
+					return value
+				}
+			}
+			return nil
+		},
+		"ternary": func(whenTrue, whenFalse interface{}, condition bool) interface{} {
+			if condition {
+				return whenTrue
+			}
+			return whenFalse
+		},
+
+		"toYaml": func(value interface{}) string {
+			content, err := yaml.Marshal(value)
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSuffix(string(content), "\n")
+		},
+		"fromYaml": func(text string) map[string]interface{} {
+			var value interface{}
+			if err := yaml.Unmarshal([]byte(text), &value); err != nil {
+				return map[string]interface{}{"Error": err.Error()}
+			}
+			result := yamlMap(normalizeYAML(value))
+			if result == nil {
+				result = map[string]interface{}{}
+			}
+			return result
+		},
+		"toJson":     helmToJSON,
+		"mustToJson": helmToJSON,
+		"toPrettyJson": func(value interface{}) string {
+			content, _ := json.MarshalIndent(value, "", "  ")
+			return string(content)
+		},
+		"fromJson": func(text string) map[string]interface{} {
+			result := map[string]interface{}{}
+			_ = json.Unmarshal([]byte(text), &result)
+			return result
+		},
+
+		"quote":      func(values ...interface{}) string { return helmQuote(`"`, values) },
+		"squote":     func(values ...interface{}) string { return helmQuote(`'`, values) },
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      helmTitle,
+		"trim":       strings.TrimSpace,
+		"trimAll":    func(cutset, s string) string { return strings.Trim(s, cutset) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trunc": func(length int, s string) string {
+			if length < 0 && len(s)+length > 0 {
+				return s[len(s)+length:]
+			}
+			if length >= 0 && len(s) > length {
+				return s[:length]
+			}
+			return s
+		},
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":    func(count int, s string) (string, error) { return helmRepeat(s, count) },
+		"nospace":   func(s string) string { return strings.Join(strings.Fields(s), "") },
+		"indent":    helmIndent,
+		"nindent": func(spaces int, s string) (string, error) {
+			indented, err := helmIndent(spaces, s)
+			return "\n" + indented, err
+		},
+		"toString": func(value interface{}) string { return helmString(value) },
+		"toStrings": func(list interface{}) []string {
+			var result []string
+			for _, item := range helmList(list) {
+				result = append(result, helmString(item))
+			}
+			return result
+		},
+		"join": func(separator string, list interface{}) string {
+			var items []string
+			for _, item := range helmList(list) {
+				items = append(items, helmString(item))
+			}
+			return strings.Join(items, separator)
+		},
+		"splitList": func(separator, s string) []interface{} {
+			var items []interface{}
+			for _, item := range strings.Split(s, separator) {
+				items = append(items, item)
+			}
+			return items
+		},
+		"regexMatch": func(pattern, s string) bool { matched, _ := regexp.MatchString(pattern, s); return matched },
+		"regexReplaceAll": func(pattern, s, replacement string) string {
+			return regexp.MustCompile(pattern).ReplaceAllString(s, replacement)
+		},
+		"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec": func(s string) string {
+			decoded, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return err.Error()
+			}
+			return string(decoded)
+		},
+		"sha256sum":    func(s string) string { sum := sha256.Sum256([]byte(s)); return hex.EncodeToString(sum[:]) },
+		"randAlphaNum": func(length int) (string, error) { return helmRepeat("x", length) },
+		"uuidv4":       func() string { return "00000000-0000-4000-8000-000000000000" },
+		"now":          time.Now,
+		"date":         func(layout string, t time.Time) string { return t.Format(layout) },
+
+		"int":     helmInt,
+		"int64":   func(value interface{}) int64 { return int64(helmInt(value)) },
+		"float64": func(value interface{}) float64 { f, _ := strconv.ParseFloat(helmString(value), 64); return f },
+		"atoi":    func(s string) int { i, _ := strconv.Atoi(s); return i },
+		"add": func(values ...interface{}) int {
+			total := 0
+			for _, value := range values {
+				total += helmInt(value)
+			}
+			return total
+		},
+		"add1": func(value interface{}) int { return helmInt(value) + 1 },
+		"sub":  func(a, b interface{}) int { return helmInt(a) - helmInt(b) },
+		"mul":  func(a, b interface{}) int { return helmInt(a) * helmInt(b) },
+		"div": func(a, b interface{}) (int, error) {
+			if helmInt(b) == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return helmInt(a) / helmInt(b), nil
+		},
+		"mod": func(a, b interface{}) (int, error) {
+			if helmInt(b) == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return helmInt(a) % helmInt(b), nil
+		},
+		"until": func(count int) ([]int, error) {
+			if count > helmMaxListLength {
+				return nil, fmt.Errorf("until %d exceeds the limit of %d items", count, helmMaxListLength)
+			}
+			items := []int{}
+			for i := 0; i < count; i++ {
+				items = append(items, i)
+			}
+			return items, nil
+		},
+
+		"list": func(items ...interface{}) []interface{} { return items },
+		"first": func(list interface{}) interface{} {
+			if items := helmList(list); len(items) > 0 {
+				return items[0]
+			}
+			return nil
+		},
+		"last": func(list interface{}) interface{} {
+			if items := helmList(list); len(items) > 0 {
+				return items[len(items)-1]
+			}
+			return nil
+		},
+		"has": func(needle, list interface{}) bool {
+			for _, item := range helmList(list) {
+				if reflect.DeepEqual(item, needle) {
+					return true
+				}
+			}
+			return false
+		},
+		"append": func(list interface{}, item interface{}) []interface{} { return append(helmList(list), item) },
+		"uniq": func(list interface{}) []interface{} {
+			var items []interface{}
+			for _, item := range helmList(list) {
+				duplicate := false
+				for _, existing := range items {
+					if reflect.DeepEqual(existing, item) {
+						duplicate = true
+						break
+					}
+				}
+				if !duplicate {
+					items = append(items, item)
+				}
+			}
+			return items
+		},
+		"compact": func(list interface{}) []interface{} {
+			var items []interface{}
+			for _, item := range helmList(list) {
+				if !helmEmpty(item) {
+					items = append(items, item)
+				}
+			}
+			return items
+		},
+		"sortAlpha": func(list interface{}) []string {
+			var items []string
+			for _, item := range helmList(list) {
+				items = append(items, helmString(item))
+			}
+			sort.Strings(items)
+			return items
+		},
+
+		"dict": func(pairs ...interface{}) map[string]interface{} {
+			result := make(map[string]interface{}, len(pairs)/2)
+			for i := 0; i+1 < len(pairs); i += 2 {
+				result[helmString(pairs[i])] = pairs[i+1]
+			}
+			return result
+		},
+		"get": func(m map[string]interface{}, key string) interface{} {
+			if value, ok := m[key]; ok {
+				return value
+			}
+			return ""
+		},
+		"set": func(m map[string]interface{}, key string, value interface{}) map[string]interface{} {
+			m[key] = value
+			return m
+		},
+		"unset": func(m map[string]interface{}, key string) map[string]interface{} {
+			delete(m, key)
+			return m
+		},
+		"hasKey": func(m map[string]interface{}, key string) bool { _, ok := m[key]; return ok },
+		"keys": func(maps ...map[string]interface{}) []string {
+			var keys []string
+			for _, m := range maps {
+				for key := range m {
+					keys = append(keys, key)
+				}
+			}
+			return keys
+		},
+		"pick": func(m map[string]interface{}, keys ...string) map[string]interface{} {
+			result := make(map[string]interface{})
+			for _, key := range keys {
+				if value, ok := m[key]; ok {
+					result[key] = value
+				}
+			}
+			return result
+		},
+		"omit": func(m map[string]interface{}, keys ...string) map[string]interface{} {
+			result := make(map[string]interface{})
+			for key, value := range m {
+				result[key] = value
+			}
+			for _, key := range keys {
+				delete(result, key)
+			}
+			return result
+		},
+		"merge": func(dst map[string]interface{}, sources ...map[string]interface{}) map[string]interface{} {
+			for _, src := range sources {
+				helmMergeMissing(dst, src)
+			}
+			return dst
+		},
+		"deepCopy": func(value interface{}) interface{} {
+			content, _ := json.Marshal(value)
+			var copied interface{}
+			_ = json.Unmarshal(content, &copied)
+			return copied
+		},
+
+		"mergeOverwrite": func(dst map[string]interface{}, sources ...map[string]interface{}) map[string]interface{} {
+			for _, src := range sources {
+				helmMergeOverwrite(dst, src)
+			}
+			return dst
+		},
+		// dig "a" "b" "default" $dict walks nested maps and falls back to the default
+		"dig": func(args ...interface{}) (interface{}, error) {
+			if len(args) < 3 {
+				return nil, errors.New("dig needs at least one key, a default and a map")
+			}
+			current := args[len(args)-1]
+			for _, key := range args[:len(args)-2] {
+				m, ok := current.(map[string]interface{})
+				if !ok {
+					return args[len(args)-2], nil
+				}
+				if current, ok = m[helmString(key)]; !ok {
+					return args[len(args)-2], nil
+				}
+			}
+			return current, nil
+		},
+		"values": func(m map[string]interface{}) []interface{} {
+			var values []interface{}
+			for _, key := range sortedKeys(m) {
+				values = append(values, m[key])
+			}
+			return values
+		},
+		"pluck": func(key string, maps ...map[string]interface{}) []interface{} {
+			var values []interface{}
+			for _, m := range maps {
+				if value, ok := m[key]; ok {
+					values = append(values, value)
+				}
+			}
+			return values
+		},
+		"tuple": func(items ...interface{}) []interface{} { return items },
+		"concat": func(lists ...interface{}) []interface{} {
+			var items []interface{}
+			for _, list := range lists {
+				items = append(items, helmList(list)...)
+			}
+			return items
+		},
+		"rest": func(list interface{}) []interface{} {
+			if items := helmList(list); len(items) > 0 {
+				return items[1:]
+			}
+			return nil
+		},
+		"initial": func(list interface{}) []interface{} {
+			if items := helmList(list); len(items) > 0 {
+				return items[:len(items)-1]
+			}
+			return nil
+		},
+		"reverse": func(list interface{}) []interface{} {
+			items := helmList(list)
+			reversed := make([]interface{}, len(items))
+			for i, item := range items {
+				reversed[len(items)-1-i] = item
+			}
+			return reversed
+		},
+		"without": func(list interface{}, omit ...interface{}) []interface{} {
+			var items []interface{}
+			for _, item := range helmList(list) {
+				keep := true
+				for _, omitted := range omit {
+					if reflect.DeepEqual(item, omitted) {
+						keep = false
+						break
+					}
+				}
+				if keep {
+					items = append(items, item)
+				}
+			}
+			return items
+		},
+
+		"regexFind": func(pattern, s string) (string, error) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return "", err
+			}
+			return re.FindString(s), nil
+		},
+		"regexFindAll": func(pattern, s string, n int) ([]string, error) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			return re.FindAllString(s, n), nil
+		},
+		"regexSplit": func(pattern, s string, n int) ([]string, error) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			return re.Split(s, n), nil
+		},
+		"regexQuoteMeta": regexp.QuoteMeta,
+		"split": func(separator, s string) map[string]interface{} {
+			return helmSplitParts(strings.Split(s, separator))
+		},
+		"splitn": func(separator string, n int, s string) map[string]interface{} {
+			return helmSplitParts(strings.SplitN(s, separator, n))
+		},
+		"substr": func(start, end int, s string) string {
+			if start < 0 {
+				start = 0
+			}
+			if end < 0 || end > len(s) {
+				end = len(s)
+			}
+			if start > end {
+				return ""
+			}
+			return s[start:end]
+		},
+		"cat": func(values ...interface{}) string {
+			var parts []string
+			for _, value := range values {
+				if value != nil {
+					parts = append(parts, helmString(value))
+				}
+			}
+			return strings.Join(parts, " ")
+		},
+		"plural": func(one, many string, count int) string {
+			if count == 1 {
+				return one
+			}
+			return many
+		},
+		"snakecase": func(s string) string { return strings.Join(helmWords(s), "_") },
+		"kebabcase": func(s string) string { return strings.Join(helmWords(s), "-") },
+		"camelcase": func(s string) string {
+			words := helmWords(s)
+			for i, word := range words {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+			return strings.Join(words, "")
+		},
+		"sha1sum":           func(s string) string { sum := sha1.Sum([]byte(s)); return hex.EncodeToString(sum[:]) },
+		"adler32sum":        func(s string) string { return strconv.FormatUint(uint64(adler32.Checksum([]byte(s))), 10) },
+		"b32enc":            func(s string) string { return base32.StdEncoding.EncodeToString([]byte(s)) },
+		"randAlpha":         func(length int) (string, error) { return helmRepeat("x", length) },
+		"randNumeric":       func(length int) (string, error) { return helmRepeat("0", length) },
+		"randAscii":         func(length int) (string, error) { return helmRepeat("x", length) },
+		"htpasswd":          func(user, password string) string { return user + ":<htpasswd>" },
+		"genCA":             helmGenCertificate,
+		"genSelfSignedCert": helmGenCertificate,
+		"genSignedCert":     helmGenCertificate,
+		"genPrivateKey":     func(...interface{}) string { return "<private key>" },
+
+		"max": func(first interface{}, rest ...interface{}) int {
+			result := helmInt(first)
+			for _, value := range rest {
+				if n := helmInt(value); n > result {
+					result = n
+				}
+			}
+			return result
+		},
+		"min": func(first interface{}, rest ...interface{}) int {
+			result := helmInt(first)
+			for _, value := range rest {
+				if n := helmInt(value); n < result {
+					result = n
+				}
+			}
+			return result
+		},
+		"ceil": func(value interface{}) float64 {
+			f, _ := strconv.ParseFloat(helmString(value), 64)
+			return math.Ceil(f)
+		},
+		"floor": func(value interface{}) float64 {
+			f, _ := strconv.ParseFloat(helmString(value), 64)
+			return math.Floor(f)
+		},
+
+		"toDate":    func(layout, value string) time.Time { t, _ := time.Parse(layout, value); return t },
+		"unixEpoch": func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) },
+		"htmlDate":  func(t time.Time) string { return t.Format("2006-01-02") },
+
+		"fromYamlArray": func(text string) []interface{} {
+			var value []interface{}
+			if err := yaml.Unmarshal([]byte(text), &value); err != nil {
+				return []interface{}{err.Error()}
+			}
+			return yamlList(normalizeYAML(value))
+		},
+		"fromJsonArray": func(text string) []interface{} {
+			var value []interface{}
+			_ = json.Unmarshal([]byte(text), &value)
+			return value
+		},
+
+		// The host environment never leaks into rendered manifests
+		"env":       func(string) string { return "" },
+		"expandenv": func(s string) string { return os.Expand(s, func(string) string { return "" }) },
+		"base":      path.Base,
+		"dir":       path.Dir,
+		"ext":       path.Ext,
+		"clean":     path.Clean,
+
+		"typeIs":    func(name string, value interface{}) bool { return fmt.Sprintf("%T", value) == name },
+		"deepEqual": reflect.DeepEqual,
+		"kindIs":    func(kind string, value interface{}) bool { return helmKind(value) == kind },
+		"kindOf":    helmKind,
+		"typeOf":    func(value interface{}) string { return fmt.Sprintf("%T", value) },
+		"semverCompare": func(constraint, version string) bool {
+			return helmSemverSatisfies(constraint, version)
+		},
+	}
+}
+
+func helmEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func helmString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func helmInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return 0
+}
+
+func helmList(value interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil
+	}
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items
+}
+
+func helmKind(value interface{}) string {
+	if value == nil {
+		return "invalid"
+	}
+	return reflect.ValueOf(value).Kind().String()
+}
+
+// helmQuote follows sprig: double quotes escape their contents, single quotes do not
+func helmQuote(quote string, values []interface{}) string {
+	var quoted []string
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		if quote == `"` {
+			quoted = append(quoted, strconv.Quote(helmString(value)))
+		} else {
+			quoted = append(quoted, quote+helmString(value)+quote)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Charts are untrusted input, so generated strings and lists are capped instead of growing until memory runs out
+const (
+	helmMaxStringSize = 16 << 20
+	helmMaxListLength = 100000
+)
+
+func helmRepeat(s string, count int) (string, error) {
+	if count < 0 || (len(s) > 0 && count > helmMaxStringSize/len(s)) {
+		return "", fmt.Errorf("repeating %d bytes %d times exceeds the limit of %d bytes", len(s), count, helmMaxStringSize)
+	}
+	return strings.Repeat(s, count), nil
+}
+
+func helmIndent(spaces int, s string) (string, error) {
+	lines := strings.Count(s, "\n") + 1
+	if spaces > helmMaxStringSize/lines {
+		return "", fmt.Errorf("indenting %d lines by %d spaces exceeds the limit of %d bytes", lines, spaces, helmMaxStringSize)
+	}
+	padding, err := helmRepeat(" ", spaces)
+	if err != nil {
+		return "", err
+	}
+	return padding + strings.ReplaceAll(s, "\n", "\n"+padding), nil
+}
+
+func helmTitle(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func helmToJSON(value interface{}) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// Copy src into dst, src winning on conflicts, recursing into nested maps
+func helmMergeOverwrite(dst, src map[string]interface{}) {
+	for key, value := range src {
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		srcMap, srcIsMap := value.(map[string]interface{})
+		if dstIsMap && srcIsMap {
+			helmMergeOverwrite(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// sprig's split and splitn return a dict keyed _0, _1, ...
+func helmSplitParts(parts []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(parts))
+	for i, part := range parts {
+		result[fmt.Sprintf("_%d", i)] = part
+	}
+	return result
+}
+
+// Lower-case words of an identifier, split on separators and case changes ("HTTPServer" is http, server)
+func helmWords(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' || r == '.' {
+			if len(current) > 0 {
+				words = append(words, strings.ToLower(string(current)))
+				current = nil
+			}
+			continue
+		}
+		if isUpperASCII(r) && len(current) > 0 {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z'
+			if !isUpperASCII(previous) || nextLower {
+				words = append(words, strings.ToLower(string(current)))
+				current = nil
+			}
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, strings.ToLower(string(current)))
+	}
+	return words
+}
+
+func isUpperASCII(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+// Certificate generators return fixed placeholders with the fields templates read
+func helmGenCertificate(...interface{}) map[string]interface{} {
+	return map[string]interface{}{"Cert": "<certificate>", "Key": "<private key>"}
+}
+
+// Fill keys missing from dst with values from src, recursing into nested maps
+func helmMergeMissing(dst, src map[string]interface{}) {
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		if dstMap, ok := existing.(map[string]interface{}); ok {
+			if srcMap, ok := value.(map[string]interface{}); ok {
+				helmMergeMissing(dstMap, srcMap)
+			}
+		}
+	}
+}
+
+// Evaluate the comparison subset of semver constraints (">=1.19-0", "<1.25.0, >=1.21") used for API version switches
+func helmSemverSatisfies(constraint, version string) bool {
+	version = strings.TrimPrefix(version, "v")
+	for _, alternative := range strings.Split(constraint, "||") {
+		satisfied := true
+		for _, clause := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' }) {
+			operator := strings.TrimRight(clause, "0123456789.-+xX*vV")
+			target := strings.TrimPrefix(strings.TrimPrefix(clause[len(operator):], "v"), "V")
+			target = strings.TrimSuffix(target, "-0")
+			comparison := compareVersions(strings.SplitN(version, "-", 2)[0], target)
+			switch operator {
+			case ">=":
+				satisfied = satisfied && comparison >= 0
+			case ">":
+				satisfied = satisfied && comparison > 0
+			case "<=":
+				satisfied = satisfied && comparison <= 0
+			case "<":
+				satisfied = satisfied && comparison < 0
+			case "!=":
+				satisfied = satisfied && comparison != 0
+			case "~", "^", "~>":
+				satisfied = satisfied && comparison >= 0
+			default:
+				satisfied = satisfied && comparison == 0
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
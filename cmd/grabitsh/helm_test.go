@@ -0,0 +1,131 @@
+package grabitsh
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func renderHelmTestChart(t *testing.T, templates map[string]string) *HelmChart {
+	t.Helper()
+	files := map[string]string{
+		"chart/Chart.yaml":  "apiVersion: v2\nname: demo\nversion: 1.2.3\nkubeVersion: \">=1.20\"\nappVersion: \"4.5\"\n",
+		"chart/values.yaml": "image:\n  tag: \"1.0\"\nextra:\n  a: 1\n  nested:\n    keep: true\n",
+		"outside.txt":       "outside-secret\n",
+	}
+	for name, content := range templates {
+		files["chart/templates/"+name] = content
+	}
+	chart := &HelmChart{Dir: filepath.Join(writeTestFiles(t, files), "chart")}
+	if err := loadHelmChart(chart); err != nil {
+		t.Fatal(err)
+	}
+	return chart
+}
+
+// A ConfigMap whose data holds the template expression under test
+func helmConfigMap(expression string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: demo\ndata:\n  value: {{ " + expression + " | toString | quote }}\n"
+}
+
+func TestRenderHelmChartFunctions(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{".Chart.APIVersion", "v2"},
+		{".Chart.KubeVersion", ">=1.20"},
+		{".Chart.AppVersion", "4.5"},
+		{`dig "image" "tag" "none" .Values`, "1.0"},
+		{`dig "image" "missing" "none" .Values`, "none"},
+		{`regexFind "[0-9]+" "v12.3"`, "12"},
+		{`regexReplaceAll "-+" "a--b" "_"`, "a_b"},
+		{`mergeOverwrite (dict "a" 0 "b" 2) .Values.extra | toJson`, `{\"a\":1,\"b\":2,\"nested\":{\"keep\":true}}`},
+		{`sha1sum "abc"`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{`semverCompare ">=1.21-0" .Capabilities.KubeVersion.GitVersion`, "true"},
+		{`snakecase "HTTPServerName"`, "http_server_name"},
+		{`camelcase "foo_bar"`, "FooBar"},
+		{`tuple 1 2 | len`, "2"},
+		{`(splitn "." 2 "a.b.c")._1`, "b.c"},
+		{`env "HOME"`, ""},
+	}
+	for _, test := range tests {
+		chart := renderHelmTestChart(t, map[string]string{"cm.yaml": helmConfigMap(test.expression)})
+		if len(chart.RenderErrors) > 0 || len(chart.Objects) != 1 {
+			t.Errorf("%s: objects %d, errors %v", test.expression, len(chart.Objects), chart.RenderErrors)
+			continue
+		}
+		if !strings.Contains(chart.Objects[0].content, "value: \""+test.want+"\"") &&
+			!strings.Contains(chart.Objects[0].content, "value: '"+test.want+"'") {
+			t.Errorf("%s: rendered %q, want value %q", test.expression, chart.Objects[0].content, test.want)
+		}
+	}
+}
+
+func TestRenderHelmChartUnknownFunction(t *testing.T) {
+	chart := renderHelmTestChart(t, map[string]string{
+		"_helpers.tpl": `{{- define "demo.name" -}}{{ .Chart.Name }}{{ frobnicate 1 }}{{- end -}}`,
+		"cm.yaml":      helmConfigMap(`include "demo.name" .`),
+	})
+	if len(chart.RenderErrors) > 0 || len(chart.Objects) != 1 {
+		t.Fatalf("objects %d, errors %v", len(chart.Objects), chart.RenderErrors)
+	}
+	if len(chart.MissingFunctions) != 1 || chart.MissingFunctions[0] != "frobnicate" {
+		t.Errorf("MissingFunctions = %v, want [frobnicate]", chart.MissingFunctions)
+	}
+	if !strings.Contains(chart.Objects[0].content, `value: "demo"`) {
+		t.Errorf("rendered %q", chart.Objects[0].content)
+	}
+}
+
+func TestParseHelmTemplateKeepsSyntaxErrors(t *testing.T) {
+	chart := &HelmChart{}
+	root := template.New("demo").Funcs(template.FuncMap{})
+	if err := parseHelmTemplate(root, "broken", "{{ if }}", chart); err == nil {
+		t.Error("parseHelmTemplate accepted a syntax error")
+	}
+	if len(chart.MissingFunctions) != 0 {
+		t.Errorf("MissingFunctions = %v, want none", chart.MissingFunctions)
+	}
+}
+
+func TestHelmFilesStayInsideChart(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"chart/files/app.conf": "in-chart",
+		"outside.txt":          "outside-secret",
+	})
+	if err := os.Symlink(filepath.Join(dir, "outside.txt"), filepath.Join(dir, "chart", "files", "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	files := helmFiles{dir: filepath.Join(dir, "chart")}
+	tests := []struct {
+		name, want string
+	}{
+		{"files/app.conf", "in-chart"},
+		{"files/../files/app.conf", "in-chart"},
+		{"../outside.txt", ""},
+		{"files/../../outside.txt", ""},
+		{filepath.Join(dir, "outside.txt"), ""},
+		{"files/link.txt", ""},
+	}
+	for _, test := range tests {
+		if got := files.Get(test.name); got != test.want {
+			t.Errorf("Files.Get(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRenderHelmChartLimits(t *testing.T) {
+	for _, expression := range []string{`until 1000000000 | len`, `repeat 1000000000 "abc"`, `"a" | indent 1000000000`, `randAlpha 1000000000`} {
+		chart := renderHelmTestChart(t, map[string]string{"cm.yaml": helmConfigMap(expression)})
+		if len(chart.RenderErrors) != 1 || len(chart.Objects) != 0 {
+			t.Errorf("%s: objects %d, errors %v, want a limit error", expression, len(chart.Objects), chart.RenderErrors)
+		}
+	}
+	chart := renderHelmTestChart(t, map[string]string{"cm.yaml": helmConfigMap(`.Files.Get "../outside.txt"`)})
+	if len(chart.Objects) != 1 || strings.Contains(chart.Objects[0].content, "outside-secret") {
+		t.Errorf("rendered a file outside the chart: %v", chart.Objects)
+	}
+}
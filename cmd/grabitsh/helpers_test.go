@@ -0,0 +1,42 @@
+package grabitsh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Write a tree of files, keyed by slash-separated path, into a fresh temporary directory
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// Write a tree of files into a temporary directory and make it the working directory for the rest of the test
+func chdirTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := writeTestFiles(t, files)
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(previous); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return dir
+}
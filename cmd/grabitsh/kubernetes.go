@@ -0,0 +1,394 @@
+package grabitsh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// K8sObject is a single Kubernetes object from a manifest document
+type K8sObject struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	// Where the object came from when it was not read verbatim from a manifest (e.g. a rendered chart)
+	Origin string                 `json:"origin,omitempty"`
+	Raw    map[string]interface{} `json:"-"`
+
+	content string
+	// metadata.name as written in the source, before any overlay renames the object
+	sourceName string
+}
+
+type K8sContainer struct {
+	Name  string
+	Image string
+	Init  bool
+	Spec  map[string]interface{}
+}
+
+var (
+	k8sWorkloadKinds = map[string]bool{
+		"Deployment": true, "StatefulSet": true, "DaemonSet": true, "ReplicaSet": true,
+		"Job": true, "CronJob": true, "Pod": true, "ReplicationController": true,
+	}
+	// Workloads that run to completion do not need liveness or readiness probes
+	k8sBatchKinds = map[string]bool{"Job": true, "CronJob": true}
+
+	k8sInventoryGroups = []struct {
+		title string
+		kinds []string
+	}{
+		{"Workloads", []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job", "CronJob", "Pod"}},
+		{"Services", []string{"Service"}},
+		{"Ingresses", []string{"Ingress", "Gateway", "HTTPRoute"}},
+		{"ConfigMaps", []string{"ConfigMap"}},
+		{"Secrets", []string{"Secret", "SealedSecret", "ExternalSecret"}},
+		{"CRDs", []string{"CustomResourceDefinition"}},
+	}
+)
+
+func isYAMLFileName(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
+
+// Discover every Kubernetes object in the repository by apiVersion and kind
+func findK8sObjects() []K8sObject {
+	templateDirs := helmTemplateDirs()
+	_, patchFiles := buildKustomizations()
+	var objects []K8sObject
+	for _, path := range findRepositoryFiles(isYAMLFileName) {
+		// Chart templates are rendered and kustomize patches are partial objects, neither is a manifest on its own
+		if hasAnyPrefix(path, templateDirs) || patchFiles[path] {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(content), "apiVersion") {
+			continue
+		}
+		objects = append(objects, parseK8sManifest(path, string(content))...)
+	}
+	return objects
+}
+
+// Parse a multi-document manifest; documents that are not Kubernetes objects or fail to parse are skipped
+func parseK8sManifest(path, content string) []K8sObject {
+	var objects []K8sObject
+	for _, document := range splitYAMLDocuments(content) {
+		values, err := decodeYAMLDocuments(document.Content)
+		if err != nil || len(values) == 0 {
+			continue
+		}
+		raw := yamlMap(values[0])
+		if yamlString(raw["kind"]) == "List" || strings.HasSuffix(yamlString(raw["kind"]), "List") {
+			for _, item := range yamlList(raw["items"]) {
+				if object, ok := newK8sObject(yamlMap(item), path, document.Line, document.Content); ok {
+					objects = append(objects, object)
+				}
+			}
+			continue
+		}
+		if object, ok := newK8sObject(raw, path, document.Line, document.Content); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+func newK8sObject(raw map[string]interface{}, file string, line int, content string) (K8sObject, bool) {
+	apiVersion, kind := yamlString(raw["apiVersion"]), yamlString(raw["kind"])
+	if apiVersion == "" || kind == "" || strings.HasPrefix(apiVersion, "kustomize.config.k8s.io/") {
+		return K8sObject{}, false
+	}
+	metadata := yamlMap(raw["metadata"])
+	return K8sObject{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       yamlString(metadata["name"]),
+		Namespace:  yamlString(metadata["namespace"]),
+		File:       file,
+		Line:       line,
+		Raw:        raw,
+		content:    content,
+		sourceName: yamlString(metadata["name"]),
+	}, true
+}
+
+// Pod spec of a workload, following the template nesting of each kind
+func k8sPodSpec(object K8sObject) map[string]interface{} {
+	spec := yamlMap(object.Raw["spec"])
+	switch object.Kind {
+	case "Pod":
+		return spec
+	case "CronJob":
+		spec = yamlMap(yamlMap(spec["jobTemplate"])["spec"])
+	}
+	if !k8sWorkloadKinds[object.Kind] {
+		return nil
+	}
+	return yamlMap(yamlMap(spec["template"])["spec"])
+}
+
+func k8sContainers(podSpec map[string]interface{}) []K8sContainer {
+	var containers []K8sContainer
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, item := range yamlList(podSpec[field]) {
+			container := yamlMap(item)
+			containers = append(containers, K8sContainer{
+				Name:  yamlString(container["name"]),
+				Image: yamlString(container["image"]),
+				Init:  field == "initContainers",
+				Spec:  container,
+			})
+		}
+	}
+	return containers
+}
+
+func (object K8sObject) displayName() string {
+	name := object.Kind + "/" + object.Name
+	if object.Namespace != "" {
+		name += " (ns: " + object.Namespace + ")"
+	}
+	return name
+}
+
+// Line of the first needle found within the manifest, falling back to the document start
+func (object K8sObject) lineOf(needles ...string) int {
+	if object.Line == 0 {
+		return 0
+	}
+	lines := strings.Split(object.content, "\n")
+	for _, needle := range needles {
+		for i, line := range lines {
+			if strings.Contains(line, needle) {
+				return object.Line + i
+			}
+		}
+	}
+	return object.Line
+}
+
+func lintK8sObjects(objects []K8sObject) []Finding {
+	var findings []Finding
+	for _, object := range objects {
+		podSpec := k8sPodSpec(object)
+		if podSpec == nil {
+			continue
+		}
+		add := func(ruleID, description, category, severity, message string, line int, identity string) {
+			if object.Origin != "" {
+				message += " (" + object.Origin + ")"
+			}
+			findings = append(findings, newFinding("kubernetes/"+ruleID, description, category, severity, message,
+				object.File, line, object.Kind+"/"+object.Namespace+"/"+object.Name+"/"+identity))
+		}
+
+		if hostNetwork, _ := podSpec["hostNetwork"].(bool); hostNetwork {
+			add("host-network", "Pod uses the host network namespace", categorySecurity, "high",
+				fmt.Sprintf("%s sets hostNetwork: true", object.displayName()), object.lineOf("hostNetwork"), "")
+		}
+
+		for _, container := range k8sContainers(podSpec) {
+			label := fmt.Sprintf("container %q in %s", container.Name, object.displayName())
+			line := object.lineOf("- name: "+container.Name, "name: "+container.Name)
+
+			securityContext := yamlMap(container.Spec["securityContext"])
+			if privileged, _ := securityContext["privileged"].(bool); privileged {
+				add("privileged-container", "Container runs in privileged mode", categorySecurity, "high",
+					fmt.Sprintf("Container %q in %s runs privileged", container.Name, object.displayName()), line, container.Name)
+			}
+
+			if container.Image != "" && parseDockerImageRef(container.Image, nil).Pinning == "latest" {
+				add("latest-image", "Container image uses the latest tag", categorySecurity, "medium",
+					fmt.Sprintf("Image %s of %s is not pinned to a tag or digest", container.Image, label), object.lineOf(container.Image), container.Name)
+			}
+
+			limits := yamlMap(yamlMap(container.Spec["resources"])["limits"])
+			var missing []string
+			for _, resource := range []string{"cpu", "memory"} {
+				if _, ok := limits[resource]; !ok {
+					missing = append(missing, resource)
+				}
+			}
+			if len(missing) > 0 {
+				add("missing-resource-limits", "Container has no resource limits", categoryQuality, "medium",
+					fmt.Sprintf("No %s limit set for %s", strings.Join(missing, " or "), label), line, container.Name)
+			}
+
+			if !container.Init && !k8sBatchKinds[object.Kind] {
+				var probes []string
+				for _, probe := range []string{"livenessProbe", "readinessProbe"} {
+					if container.Spec[probe] == nil {
+						probes = append(probes, probe)
+					}
+				}
+				if len(probes) > 0 {
+					add("missing-probes", "Container has no health probes", categoryQuality, "low",
+						fmt.Sprintf("No %s defined for %s", strings.Join(probes, " or "), label), line, container.Name)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// Plain manifests plus the objects rendered from Helm charts
+func collectK8sObjects() []K8sObject {
+	return append(findK8sObjects(), helmObjects(loadHelmCharts())...)
+}
+
+func kubernetesFindings() []Finding {
+	return lintK8sObjects(collectK8sObjects())
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeK8sInventory(output io.StringWriter, objects []K8sObject) {
+	if len(objects) == 0 {
+		return
+	}
+
+	files := make(map[string]bool)
+	byKind := make(map[string][]K8sObject)
+	for _, object := range objects {
+		files[object.File] = true
+		byKind[object.Kind] = append(byKind[object.Kind], object)
+	}
+	output.WriteString(fmt.Sprintf("\nKubernetes manifests: %d objects in %d files\n", len(objects), len(files)))
+
+	grouped := make(map[string]bool)
+	for _, group := range k8sInventoryGroups {
+		var lines []string
+		for _, kind := range group.kinds {
+			grouped[kind] = true
+			for _, object := range byKind[kind] {
+				lines = append(lines, fmt.Sprintf("    - %s%s [%s]", object.displayName(), k8sObjectDetails(object), k8sObjectLocation(object)))
+			}
+		}
+		if len(lines) > 0 {
+			output.WriteString(fmt.Sprintf("  %s (%d):\n%s\n", group.title, len(lines), strings.Join(lines, "\n")))
+		}
+	}
+
+	var otherKinds []string
+	for kind, items := range byKind {
+		if !grouped[kind] {
+			otherKinds = append(otherKinds, fmt.Sprintf("%s (%d)", kind, len(items)))
+		}
+	}
+	if len(otherKinds) > 0 {
+		sort.Strings(otherKinds)
+		output.WriteString(fmt.Sprintf("  Other resources: %s\n", strings.Join(otherKinds, ", ")))
+	}
+
+	images := make(map[string][]string)
+	for _, object := range objects {
+		for _, container := range k8sContainers(k8sPodSpec(object)) {
+			if container.Image != "" {
+				images[container.Image] = appendUnique(images[container.Image], object.Kind+"/"+object.Name)
+			}
+		}
+	}
+	if len(images) > 0 {
+		names := make([]string, 0, len(images))
+		for image := range images {
+			names = append(names, image)
+		}
+		sort.Strings(names)
+		output.WriteString("  Images:\n")
+		for _, image := range names {
+			output.WriteString(fmt.Sprintf("    - %s (used by %s)\n", image, strings.Join(images[image], ", ")))
+		}
+	}
+
+	if findings := lintK8sObjects(objects); len(findings) > 0 {
+		output.WriteString("  Issues:\n")
+		for _, finding := range findings {
+			location := finding.File
+			if finding.Line > 0 {
+				location = fmt.Sprintf("%s:%d", finding.File, finding.Line)
+			}
+			output.WriteString(fmt.Sprintf("    - [%s] %s: %s\n", finding.Severity, location, finding.Message))
+		}
+	}
+}
+
+func k8sObjectLocation(object K8sObject) string {
+	location := object.File
+	if object.Line > 0 {
+		location = fmt.Sprintf("%s:%d", object.File, object.Line)
+	}
+	if object.Origin != "" {
+		location = object.Origin + ": " + location
+	}
+	return location
+}
+
+// Short kind-specific summary shown next to each inventory entry
+func k8sObjectDetails(object K8sObject) string {
+	spec := yamlMap(object.Raw["spec"])
+	var details []string
+	switch object.Kind {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		if replicas := yamlString(spec["replicas"]); replicas != "" {
+			details = append(details, "replicas: "+replicas)
+		}
+	case "CronJob":
+		if schedule := yamlString(spec["schedule"]); schedule != "" {
+			details = append(details, "schedule: "+schedule)
+		}
+	case "Service":
+		serviceType := yamlString(spec["type"])
+		if serviceType == "" {
+			serviceType = "ClusterIP"
+		}
+		details = append(details, "type: "+serviceType)
+		var ports []string
+		for _, item := range yamlList(spec["ports"]) {
+			port := yamlMap(item)
+			ports = append(ports, yamlString(port["port"]))
+		}
+		if len(ports) > 0 {
+			details = append(details, "ports: "+strings.Join(ports, ","))
+		}
+	case "Ingress":
+		var hosts []string
+		for _, item := range yamlList(spec["rules"]) {
+			if host := yamlString(yamlMap(item)["host"]); host != "" {
+				hosts = appendUnique(hosts, host)
+			}
+		}
+		if len(hosts) > 0 {
+			details = append(details, "hosts: "+strings.Join(hosts, ","))
+		}
+	case "ConfigMap", "Secret":
+		// Only key names, values may hold credentials
+		var keys []string
+		for _, field := range []string{"data", "stringData", "binaryData"} {
+			keys = append(keys, yamlKeysOrItems(yamlMap(object.Raw[field]))...)
+		}
+		if len(keys) > 0 {
+			details = append(details, "keys: "+strings.Join(keys, ","))
+		}
+	case "CustomResourceDefinition":
+		names := yamlMap(spec["names"])
+		details = append(details, fmt.Sprintf("%s.%s", yamlString(names["kind"]), yamlString(spec["group"])))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, ", ") + ")"
+}
@@ -0,0 +1,162 @@
+package grabitsh
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const k8sTestManifest = `# app manifests
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+  template:
+    spec:
+      hostNetwork: true
+      initContainers:
+        - name: migrate
+          image: example/migrate:1.0
+          resources:
+            limits: {cpu: 100m, memory: 64Mi}
+      containers:
+        - name: app
+          image: example/app
+          securityContext:
+            privileged: true
+          resources:
+            limits:
+              memory: 128Mi
+          readinessProbe:
+            httpGet: {path: /healthz, port: 8080}
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  schedule: "0 3 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: report
+              image: example/report@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+              resources:
+                limits: {cpu: 1, memory: 1Gi}
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Service
+    metadata:
+      name: web
+    spec:
+      ports: [{port: 80}, {port: 443}]
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: creds
+    stringData:
+      password: hunter2
+---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources: [app.yaml]
+---
+name: not a kubernetes object
+---
+apiVersion: [broken
+`
+
+func TestParseK8sManifest(t *testing.T) {
+	objects := parseK8sManifest("deploy/app.yaml", k8sTestManifest)
+	var got []string
+	for _, object := range objects {
+		got = append(got, object.displayName()+"@"+k8sObjectLocation(object))
+	}
+	want := []string{
+		"Deployment/web (ns: prod)@deploy/app.yaml:1",
+		"CronJob/report@deploy/app.yaml:28",
+		"Service/web@deploy/app.yaml:44",
+		"Secret/creds@deploy/app.yaml:44",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("objects = %q, want %q", got, want)
+	}
+}
+
+func TestK8sPodSpec(t *testing.T) {
+	objects := parseK8sManifest("app.yaml", k8sTestManifest)
+	var containers []string
+	for _, object := range objects {
+		for _, container := range k8sContainers(k8sPodSpec(object)) {
+			name := object.Kind + ":" + container.Name
+			if container.Init {
+				name += " (init)"
+			}
+			containers = append(containers, name)
+		}
+	}
+	want := []string{"Deployment:migrate (init)", "Deployment:app", "CronJob:report"}
+	if !reflect.DeepEqual(containers, want) {
+		t.Errorf("containers = %q, want %q", containers, want)
+	}
+}
+
+func TestLintK8sObjects(t *testing.T) {
+	findings := lintK8sObjects(parseK8sManifest("app.yaml", k8sTestManifest))
+	var got []string
+	for _, finding := range findings {
+		got = append(got, fmt.Sprintf("%s:%d %s", finding.RuleID, finding.Line, finding.Message))
+	}
+	sort.Strings(got)
+	want := []string{
+		`kubernetes/host-network:11 Deployment/web (ns: prod) sets hostNetwork: true`,
+		`kubernetes/latest-image:19 Image example/app of container "app" in Deployment/web (ns: prod) is not pinned to a tag or digest`,
+		`kubernetes/missing-probes:18 No livenessProbe defined for container "app" in Deployment/web (ns: prod)`,
+		`kubernetes/missing-resource-limits:18 No cpu limit set for container "app" in Deployment/web (ns: prod)`,
+		`kubernetes/privileged-container:18 Container "app" in Deployment/web (ns: prod) runs privileged`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLintK8sObjectsOrigin(t *testing.T) {
+	objects := parseK8sManifest("templates/pod.yaml", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: tool\nspec:\n  hostNetwork: true\n")
+	objects[0].Origin = "chart demo"
+	findings := lintK8sObjects(objects)
+	if len(findings) != 1 || !strings.HasSuffix(findings[0].Message, "(chart demo)") {
+		t.Errorf("findings = %+v, want one host-network finding naming its origin", findings)
+	}
+}
+
+func TestWriteK8sInventory(t *testing.T) {
+	var output strings.Builder
+	writeK8sInventory(&output, parseK8sManifest("app.yaml", k8sTestManifest))
+	report := output.String()
+	for _, want := range []string{
+		"Kubernetes manifests: 4 objects in 1 files\n",
+		"  Workloads (2):\n    - Deployment/web (ns: prod) (replicas: 3) [app.yaml:1]\n    - CronJob/report (schedule: 0 3 * * *) [app.yaml:28]\n",
+		"  Services (1):\n    - Service/web (type: ClusterIP, ports: 80,443) [app.yaml:44]\n",
+		"  Secrets (1):\n    - Secret/creds (keys: password) [app.yaml:44]\n",
+		"    - example/app (used by Deployment/web)\n",
+		"  Issues:\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("inventory is missing %q:\n%s", want, report)
+		}
+	}
+	// Secret values never reach the report
+	if strings.Contains(report, "hunter2") {
+		t.Errorf("inventory leaks a secret value:\n%s", report)
+	}
+}
@@ -0,0 +1,629 @@
+package grabitsh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+type KustomizeImage struct {
+	Name    string `json:"name"`
+	NewName string `json:"new_name,omitempty"`
+	NewTag  string `json:"new_tag,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+}
+
+// Kustomization is a kustomization directory built offline into its final set of objects
+type Kustomization struct {
+	Dir         string           `json:"dir"`
+	Kind        string           `json:"kind"`
+	Environment string           `json:"environment"`
+	Namespace   string           `json:"namespace,omitempty"`
+	References  []string         `json:"references,omitempty"`
+	Images      []KustomizeImage `json:"images,omitempty"`
+	Objects     []K8sObject      `json:"objects"`
+	Remote      []string         `json:"remote,omitempty"`
+	Errors      []string         `json:"errors,omitempty"`
+}
+
+var (
+	kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+	// Cluster-scoped kinds keep their namespace unset when a namespace transformer runs
+	k8sClusterScopedKinds = map[string]bool{
+		"Namespace": true, "CustomResourceDefinition": true, "ClusterRole": true, "ClusterRoleBinding": true,
+		"PersistentVolume": true, "StorageClass": true, "PriorityClass": true, "IngressClass": true,
+		"ValidatingWebhookConfiguration": true, "MutatingWebhookConfiguration": true, "APIService": true,
+	}
+
+	kustomizeMergeKeys = []string{"name", "containerPort", "mountPath", "devicePath", "port", "ip", "topologyKey"}
+)
+
+type kustomizeBuilder struct {
+	patchFiles map[string]bool
+}
+
+func isKustomizationFileName(name string) bool {
+	for _, candidate := range kustomizationFileNames {
+		if name == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func kustomizationFile(dir string) string {
+	for _, name := range kustomizationFileNames {
+		if path := filepath.Join(dir, name); fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func isRemoteKustomizeResource(entry string) bool {
+	return strings.Contains(entry, "://") || strings.HasPrefix(entry, "github.com/") || strings.HasPrefix(entry, "git@") || strings.Contains(entry, "?ref=")
+}
+
+// Build every kustomization in the tree, also returning the patch files it consumed
+func buildKustomizations() ([]*Kustomization, map[string]bool) {
+	builder := &kustomizeBuilder{patchFiles: make(map[string]bool)}
+
+	var kustomizations []*Kustomization
+	for _, path := range findRepositoryFiles(isKustomizationFileName) {
+		dir := filepath.ToSlash(filepath.Dir(path))
+		kustomization := &Kustomization{Dir: dir, Environment: filepath.Base(dir)}
+		if dir == "." {
+			kustomization.Environment = "default"
+		}
+		kustomization.Objects = builder.build(dir, nil, kustomization, map[string]bool{})
+		kustomizations = append(kustomizations, kustomization)
+	}
+	return kustomizations, builder.patchFiles
+}
+
+// Kustomizations that no other kustomization references are the environments; components only apply to others
+func kustomizeEnvironments(kustomizations []*Kustomization) []*Kustomization {
+	referenced := make(map[string]bool)
+	for _, kustomization := range kustomizations {
+		for _, reference := range kustomization.References {
+			referenced[reference] = true
+		}
+	}
+	var environments []*Kustomization
+	for _, kustomization := range kustomizations {
+		if !referenced[kustomization.Dir] && kustomization.Kind != "Component" {
+			environments = append(environments, kustomization)
+		}
+	}
+	return environments
+}
+
+// Build one kustomization directory on top of inherited objects (non-empty only for components)
+func (builder *kustomizeBuilder) build(dir string, inherited []K8sObject, result *Kustomization, stack map[string]bool) []K8sObject {
+	if stack[dir] {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: reference cycle", dir))
+		return inherited
+	}
+	stack[dir] = true
+	defer delete(stack, dir)
+
+	path := kustomizationFile(dir)
+	config, err := readYAMLMap(path)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return inherited
+	}
+	if dir == result.Dir {
+		result.Kind = yamlString(config["kind"])
+	}
+
+	objects := inherited
+	for _, field := range []string{"resources", "bases"} {
+		for _, entry := range yamlKeysOrItems(config[field]) {
+			objects = append(objects, builder.resource(dir, entry, result, stack)...)
+		}
+	}
+	objects = append(objects, kustomizeGeneratedObjects(config)...)
+
+	for _, entry := range yamlKeysOrItems(config["components"]) {
+		componentDir := filepath.ToSlash(filepath.Join(dir, entry))
+		if isRemoteKustomizeResource(entry) {
+			result.Remote = appendUnique(result.Remote, entry)
+			continue
+		}
+		if kustomizationFile(componentDir) == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: component %s not found", dir, entry))
+			continue
+		}
+		if dir == result.Dir {
+			result.References = appendUnique(result.References, componentDir)
+		}
+		objects = builder.build(componentDir, objects, result, stack)
+	}
+
+	builder.applyPatches(dir, config, objects, result)
+
+	if namespace := yamlString(config["namespace"]); namespace != "" {
+		if dir == result.Dir {
+			result.Namespace = namespace
+		}
+		for i := range objects {
+			if !k8sClusterScopedKinds[objects[i].Kind] {
+				kustomizeMetadata(&objects[i])["namespace"] = namespace
+			}
+		}
+	}
+
+	prefix, suffix := yamlString(config["namePrefix"]), yamlString(config["nameSuffix"])
+	if prefix != "" || suffix != "" {
+		for i := range objects {
+			if objects[i].Kind != "CustomResourceDefinition" && objects[i].Kind != "Namespace" {
+				metadata := kustomizeMetadata(&objects[i])
+				metadata["name"] = prefix + yamlString(metadata["name"]) + suffix
+			}
+		}
+	}
+
+	for _, item := range yamlList(config["replicas"]) {
+		replicas := yamlMap(item)
+		for i := range objects {
+			if objects[i].Name == yamlString(replicas["name"]) && k8sWorkloadKinds[objects[i].Kind] {
+				if spec := yamlMap(objects[i].Raw["spec"]); spec != nil {
+					spec["replicas"] = replicas["count"]
+				}
+			}
+		}
+	}
+
+	for _, item := range yamlList(config["images"]) {
+		entry := yamlMap(item)
+		image := KustomizeImage{
+			Name:    yamlString(entry["name"]),
+			NewName: yamlString(entry["newName"]),
+			NewTag:  yamlString(entry["newTag"]),
+			Digest:  yamlString(entry["digest"]),
+		}
+		result.Images = append(result.Images, image)
+		for i := range objects {
+			applyKustomizeImage(objects[i], image)
+		}
+	}
+
+	for i := range objects {
+		metadata := yamlMap(objects[i].Raw["metadata"])
+		objects[i].Name = yamlString(metadata["name"])
+		objects[i].Namespace = yamlString(metadata["namespace"])
+	}
+	return objects
+}
+
+func (builder *kustomizeBuilder) resource(dir, entry string, result *Kustomization, stack map[string]bool) []K8sObject {
+	if isRemoteKustomizeResource(entry) {
+		result.Remote = appendUnique(result.Remote, entry)
+		return nil
+	}
+	path := filepath.ToSlash(filepath.Join(dir, entry))
+	if dirExists(path) {
+		if kustomizationFile(path) == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s has no kustomization file", dir, entry))
+			return nil
+		}
+		if dir == result.Dir {
+			result.References = appendUnique(result.References, path)
+		}
+		return builder.build(path, nil, result, stack)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: resource %s not found", dir, entry))
+		return nil
+	}
+	return parseK8sManifest(path, string(content))
+}
+
+// ConfigMaps and Secrets from generators, with key names only
+func kustomizeGeneratedObjects(config map[string]interface{}) []K8sObject {
+	var objects []K8sObject
+	for field, kind := range map[string]string{"configMapGenerator": "ConfigMap", "secretGenerator": "Secret"} {
+		for _, item := range yamlList(config[field]) {
+			generator := yamlMap(item)
+			data := make(map[string]interface{})
+			for _, literal := range yamlKeysOrItems(generator["literals"]) {
+				data[strings.SplitN(literal, "=", 2)[0]] = ""
+			}
+			for _, file := range yamlKeysOrItems(generator["files"]) {
+				key := strings.SplitN(file, "=", 2)[0]
+				data[filepath.Base(key)] = ""
+			}
+			for _, envFile := range yamlKeysOrItems(generator["envs"]) {
+				data["<"+envFile+">"] = ""
+			}
+			metadata := map[string]interface{}{"name": yamlString(generator["name"])}
+			if namespace := yamlString(generator["namespace"]); namespace != "" {
+				metadata["namespace"] = namespace
+			}
+			raw := map[string]interface{}{"apiVersion": "v1", "kind": kind, "metadata": metadata, "data": data}
+			if object, ok := newK8sObject(raw, "", 0, ""); ok {
+				object.Origin = "generated"
+				objects = append(objects, object)
+			}
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Kind+objects[i].Name < objects[j].Kind+objects[j].Name })
+	return objects
+}
+
+func kustomizeMetadata(object *K8sObject) map[string]interface{} {
+	metadata := yamlMap(object.Raw["metadata"])
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+		object.Raw["metadata"] = metadata
+	}
+	return metadata
+}
+
+func (builder *kustomizeBuilder) applyPatches(dir string, config map[string]interface{}, objects []K8sObject, result *Kustomization) {
+	type patch struct {
+		content string
+		target  map[string]interface{}
+	}
+	var patches []patch
+
+	load := func(entry map[string]interface{}, inline interface{}) {
+		content := yamlString(inline)
+		if path := yamlString(entry["path"]); path != "" {
+			file := filepath.ToSlash(filepath.Join(dir, path))
+			data, err := os.ReadFile(file)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: patch %s not found", dir, path))
+				return
+			}
+			builder.patchFiles[file] = true
+			content = string(data)
+		}
+		patches = append(patches, patch{content: content, target: yamlMap(entry["target"])})
+	}
+
+	for _, item := range yamlList(config["patchesStrategicMerge"]) {
+		if text, ok := item.(string); ok && !strings.Contains(text, "\n") {
+			load(map[string]interface{}{"path": text}, nil)
+		} else {
+			load(nil, item)
+		}
+	}
+	for _, field := range []string{"patches", "patchesJson6902"} {
+		for _, item := range yamlList(config[field]) {
+			entry := yamlMap(item)
+			load(entry, entry["patch"])
+		}
+	}
+
+	for _, p := range patches {
+		documents, err := decodeYAMLDocuments(p.content)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid patch: %v", dir, err))
+			continue
+		}
+		for _, document := range documents {
+			if operations := yamlList(document); operations != nil {
+				for i := range objects {
+					if kustomizeTargetMatches(objects[i], p.target) {
+						for _, operation := range operations {
+							if err := applyJSONPatchOperation(objects[i].Raw, yamlMap(operation)); err != nil {
+								result.Errors = append(result.Errors, fmt.Sprintf("%s: %s: %v", dir, objects[i].displayName(), err))
+							}
+						}
+					}
+				}
+				continue
+			}
+
+			patchObject := yamlMap(document)
+			target := p.target
+			if target == nil {
+				target = map[string]interface{}{
+					"kind": patchObject["kind"],
+					"name": yamlMap(patchObject["metadata"])["name"],
+				}
+			}
+			for i := range objects {
+				if kustomizeTargetMatches(objects[i], target) {
+					strategicMerge(objects[i].Raw, patchObject)
+				}
+			}
+		}
+	}
+}
+
+func kustomizeTargetMatches(object K8sObject, target map[string]interface{}) bool {
+	if target == nil {
+		return false
+	}
+	if kind := yamlString(target["kind"]); kind != "" && kind != object.Kind {
+		return false
+	}
+	if namespace := yamlString(target["namespace"]); namespace != "" && namespace != object.Namespace {
+		return false
+	}
+	if name := yamlString(target["name"]); name != "" {
+		pattern, err := regexp.Compile("^(?:" + name + ")$")
+		if err != nil || !pattern.MatchString(yamlString(yamlMap(object.Raw["metadata"])["name"])) {
+			return false
+		}
+	}
+	if selector := yamlString(target["labelSelector"]); selector != "" {
+		labels := yamlMap(yamlMap(object.Raw["metadata"])["labels"])
+		for _, requirement := range strings.Split(selector, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(requirement), "=")
+			if yamlString(labels[key]) != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Strategic merge patch: maps merge recursively, lists of named items merge by key, $patch: delete removes
+func strategicMerge(dst, patch map[string]interface{}) {
+	for key, value := range patch {
+		if key == "$patch" {
+			continue
+		}
+		switch v := value.(type) {
+		case nil:
+			delete(dst, key)
+		case map[string]interface{}:
+			if yamlString(v["$patch"]) == "delete" {
+				delete(dst, key)
+			} else if existing := yamlMap(dst[key]); existing != nil {
+				strategicMerge(existing, v)
+			} else {
+				dst[key] = v
+			}
+		case []interface{}:
+			dst[key] = strategicMergeList(yamlList(dst[key]), v)
+		default:
+			dst[key] = value
+		}
+	}
+}
+
+func strategicMergeList(dst, patch []interface{}) []interface{} {
+	mergeKey := ""
+	if len(patch) > 0 {
+		if first := yamlMap(patch[0]); first != nil {
+			for _, key := range kustomizeMergeKeys {
+				if _, ok := first[key]; ok {
+					mergeKey = key
+					break
+				}
+			}
+		}
+	}
+	if mergeKey == "" {
+		return patch
+	}
+
+	for _, item := range patch {
+		patchItem := yamlMap(item)
+		index := -1
+		for i, existing := range dst {
+			if existingItem := yamlMap(existing); existingItem != nil && yamlString(existingItem[mergeKey]) == yamlString(patchItem[mergeKey]) {
+				index = i
+				break
+			}
+		}
+		switch {
+		case yamlString(patchItem["$patch"]) == "delete":
+			if index != -1 {
+				dst = append(dst[:index], dst[index+1:]...)
+			}
+		case index != -1:
+			strategicMerge(yamlMap(dst[index]), patchItem)
+		default:
+			dst = append(dst, patchItem)
+		}
+	}
+	return dst
+}
+
+// Apply a single RFC 6902 add, replace or remove operation
+func applyJSONPatchOperation(document map[string]interface{}, operation map[string]interface{}) error {
+	op, path := yamlString(operation["op"]), yamlString(operation["path"])
+	var tokens []string
+	for _, token := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+	}
+	if op != "add" && op != "replace" && op != "remove" {
+		return fmt.Errorf("unsupported patch operation %q", op)
+	}
+	_, err := jsonPatchApply(document, tokens, op, operation["value"])
+	return err
+}
+
+func jsonPatchApply(node interface{}, tokens []string, op string, value interface{}) (interface{}, error) {
+	token, last := tokens[0], len(tokens) == 1
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if last {
+			if op == "remove" {
+				delete(container, token)
+			} else {
+				container[token] = value
+			}
+			return container, nil
+		}
+		child, ok := container[token]
+		if !ok {
+			return nil, fmt.Errorf("path segment %q not found", token)
+		}
+		updated, err := jsonPatchApply(child, tokens[1:], op, value)
+		if err == nil {
+			container[token] = updated
+		}
+		return container, err
+	case []interface{}:
+		index := len(container)
+		if token != "-" {
+			parsed, err := strconv.Atoi(token)
+			if err != nil || parsed < 0 || parsed > len(container) {
+				return nil, fmt.Errorf("invalid list index %q", token)
+			}
+			index = parsed
+		}
+		if last {
+			switch {
+			case op == "add":
+				container = append(container, nil)
+				copy(container[index+1:], container[index:])
+				container[index] = value
+			case index >= len(container):
+				return nil, fmt.Errorf("list index %d out of range", index)
+			case op == "remove":
+				container = append(container[:index], container[index+1:]...)
+			default:
+				container[index] = value
+			}
+			return container, nil
+		}
+		if index >= len(container) {
+			return nil, fmt.Errorf("list index %d out of range", index)
+		}
+		updated, err := jsonPatchApply(container[index], tokens[1:], op, value)
+		if err == nil {
+			container[index] = updated
+		}
+		return container, err
+	}
+	return nil, fmt.Errorf("path segment %q does not address a map or list", token)
+}
+
+func applyKustomizeImage(object K8sObject, image KustomizeImage) {
+	podSpec := k8sPodSpec(object)
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, item := range yamlList(podSpec[field]) {
+			container := yamlMap(item)
+			ref := parseDockerImageRef(yamlString(container["image"]), nil)
+			if ref.Name != image.Name {
+				continue
+			}
+			name, tag, digest := ref.Name, ref.Tag, ref.Digest
+			if image.NewName != "" {
+				name = image.NewName
+			}
+			if image.NewTag != "" {
+				tag, digest = image.NewTag, ""
+			}
+			if image.Digest != "" {
+				digest = image.Digest
+			}
+			result := name
+			if tag != "" {
+				result += ":" + tag
+			}
+			if digest != "" {
+				result += "@" + digest
+			}
+			container["image"] = result
+		}
+	}
+}
+
+func writeKustomizeReport(output io.StringWriter, kustomizations []*Kustomization) {
+	environments := kustomizeEnvironments(kustomizations)
+	if len(environments) == 0 {
+		return
+	}
+
+	output.WriteString(fmt.Sprintf("\nKustomize: %d kustomizations, %d environments\n", len(kustomizations), len(environments)))
+	for _, environment := range environments {
+		line := fmt.Sprintf("  - %s (%s): %d resources", environment.Environment, environment.Dir, len(environment.Objects))
+		if environment.Namespace != "" {
+			line += ", namespace " + environment.Namespace
+		}
+		if len(environment.References) > 0 {
+			line += ", builds on " + strings.Join(environment.References, ", ")
+		}
+		output.WriteString(line + "\n")
+		for _, remote := range environment.Remote {
+			output.WriteString(fmt.Sprintf("      remote resource not resolved offline: %s\n", remote))
+		}
+		for _, buildError := range environment.Errors {
+			output.WriteString(fmt.Sprintf("      Error: %s\n", buildError))
+		}
+	}
+
+	// Rows are keyed by the source document so renamed objects line up across environments
+	type row struct {
+		label string
+		cells map[string]string
+	}
+	rows := make(map[string]*row)
+	var rowKeys []string
+	imageRows := make(map[string]*row)
+	var imageKeys []string
+
+	for _, environment := range environments {
+		for _, object := range environment.Objects {
+			label := object.Kind + "/" + object.sourceName
+			key := fmt.Sprintf("%s@%s:%d", label, object.File, object.Line)
+			r, ok := rows[key]
+			if !ok {
+				r = &row{label: label, cells: make(map[string]string)}
+				rows[key] = r
+				rowKeys = append(rowKeys, key)
+			}
+			cell := object.Name
+			if object.Namespace != "" {
+				cell = object.Namespace + "/" + cell
+			}
+			if replicas := yamlString(yamlMap(object.Raw["spec"])["replicas"]); replicas != "" && k8sWorkloadKinds[object.Kind] {
+				cell += fmt.Sprintf(" (x%s)", replicas)
+			}
+			r.cells[environment.Dir] = cell
+
+			for _, container := range k8sContainers(k8sPodSpec(object)) {
+				imageKey := key + "#" + container.Name
+				ir, ok := imageRows[imageKey]
+				if !ok {
+					ir = &row{label: r.label + " " + container.Name, cells: make(map[string]string)}
+					imageRows[imageKey] = ir
+					imageKeys = append(imageKeys, imageKey)
+				}
+				ir.cells[environment.Dir] = container.Image
+			}
+		}
+	}
+
+	writeMatrix := func(title string, keys []string, rows map[string]*row) {
+		if len(keys) == 0 {
+			return
+		}
+		sort.SliceStable(keys, func(i, j int) bool { return rows[keys[i]].label < rows[keys[j]].label })
+		var table strings.Builder
+		writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		header := "  " + title
+		for _, environment := range environments {
+			header += "\t" + strings.ToUpper(environment.Environment)
+		}
+		fmt.Fprintln(writer, header)
+		for _, key := range keys {
+			line := "  " + rows[key].label
+			for _, environment := range environments {
+				line += "\t" + orDash(rows[key].cells[environment.Dir])
+			}
+			fmt.Fprintln(writer, line)
+		}
+		writer.Flush()
+		output.WriteString("\n" + table.String())
+	}
+	writeMatrix("RESOURCE", rowKeys, rows)
+	writeMatrix("CONTAINER IMAGE", imageKeys, imageRows)
+}
@@ -0,0 +1,106 @@
+package grabitsh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func kustomizeTestDeployment() map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "labels": map[string]interface{}{"app": "web", "tier": "frontend"}},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "web:1.0"},
+					map[string]interface{}{"name": "sidecar", "image": "proxy:2.0"},
+				},
+			}},
+		},
+	}
+}
+
+func TestStrategicMerge(t *testing.T) {
+	document := kustomizeTestDeployment()
+	strategicMerge(document, map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"tier": nil, "env": "prod"}},
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "web:2.0"},
+					map[string]interface{}{"name": "sidecar", "$patch": "delete"},
+					map[string]interface{}{"name": "metrics", "image": "exporter:1.0"},
+				},
+			}},
+		},
+	})
+
+	want := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "labels": map[string]interface{}{"app": "web", "env": "prod"}},
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "web:2.0"},
+					map[string]interface{}{"name": "metrics", "image": "exporter:1.0"},
+				},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(document, want) {
+		t.Errorf("strategicMerge() = %#v\nwant %#v", document, want)
+	}
+}
+
+func TestStrategicMergeReplacesUnkeyedLists(t *testing.T) {
+	document := map[string]interface{}{"args": []interface{}{"--a", "--b"}}
+	strategicMerge(document, map[string]interface{}{"args": []interface{}{"--c"}})
+	if want := []interface{}{"--c"}; !reflect.DeepEqual(document["args"], want) {
+		t.Errorf("args = %#v, want %#v", document["args"], want)
+	}
+}
+
+func TestApplyJSONPatchOperation(t *testing.T) {
+	containers := func(document map[string]interface{}) []interface{} {
+		return yamlList(yamlMap(yamlMap(yamlMap(document["spec"])["template"])["spec"])["containers"])
+	}
+	tests := []struct {
+		name      string
+		operation map[string]interface{}
+		check     func(map[string]interface{}) bool
+		wantErr   bool
+	}{
+		{"replace scalar", map[string]interface{}{"op": "replace", "path": "/spec/replicas", "value": 5},
+			func(d map[string]interface{}) bool { return yamlMap(d["spec"])["replicas"] == 5 }, false},
+		{"add to list index", map[string]interface{}{"op": "add", "path": "/spec/template/spec/containers/0", "value": "first"},
+			func(d map[string]interface{}) bool { c := containers(d); return len(c) == 3 && c[0] == "first" }, false},
+		{"append to list", map[string]interface{}{"op": "add", "path": "/spec/template/spec/containers/-", "value": "last"},
+			func(d map[string]interface{}) bool { c := containers(d); return len(c) == 3 && c[2] == "last" }, false},
+		{"remove list item", map[string]interface{}{"op": "remove", "path": "/spec/template/spec/containers/1"},
+			func(d map[string]interface{}) bool { return len(containers(d)) == 1 }, false},
+		{"escaped key", map[string]interface{}{"op": "add", "path": "/metadata/labels/app.kubernetes.io~1name", "value": "web"},
+			func(d map[string]interface{}) bool {
+				return yamlMap(yamlMap(d["metadata"])["labels"])["app.kubernetes.io/name"] == "web"
+			}, false},
+		{"remove map key", map[string]interface{}{"op": "remove", "path": "/metadata/labels/tier"},
+			func(d map[string]interface{}) bool {
+				_, ok := yamlMap(yamlMap(d["metadata"])["labels"])["tier"]
+				return !ok
+			}, false},
+		{"missing parent", map[string]interface{}{"op": "add", "path": "/status/phase", "value": "x"}, nil, true},
+		{"index out of range", map[string]interface{}{"op": "replace", "path": "/spec/template/spec/containers/5", "value": "x"}, nil, true},
+		{"unsupported op", map[string]interface{}{"op": "move", "from": "/a", "path": "/b"}, nil, true},
+	}
+	for _, test := range tests {
+		document := kustomizeTestDeployment()
+		err := applyJSONPatchOperation(document, test.operation)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: error = %v, want error %v", test.name, err, test.wantErr)
+			continue
+		}
+		if test.check != nil && !test.check(document) {
+			t.Errorf("%s: unexpected result %#v", test.name, document)
+		}
+	}
+}
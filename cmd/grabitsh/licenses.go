@@ -0,0 +1,454 @@
+package grabitsh
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SPDX license texts used to identify LICENSE files; the file name is the SPDX identifier.
+// A license file does not say whether "or later" applies, so GPL family texts map to -only.
+//
+//go:embed licenses/*.txt
+var licenseTemplateFiles embed.FS
+
+type licenseTemplate struct {
+	ID       string
+	shingles map[string]bool
+}
+
+// Minimum similarity for a license text to be reported as a known license
+const licenseMatchThreshold = 0.75
+
+var (
+	licenseTemplatesOnce sync.Once
+	licenseTemplates     []licenseTemplate
+
+	licenseFileRegex     = regexp.MustCompile(`(?i)^(un)?licen[cs]e([-._].*)?$|^copying([-._].*)?$`)
+	spdxIdentifierRegex  = regexp.MustCompile(`SPDX-License-Identifier:\s*([^\n*/]+)`)
+	licenseNonWordRegex  = regexp.MustCompile(`[^a-z0-9]+`)
+	licenseCopyrightLine = regexp.MustCompile(`(?im)^.*(copyright|\(c\)).*$`)
+	pythonLicenseRegex   = regexp.MustCompile(`(?m)^(?:License-Expression|License):\s*(.+)$`)
+	pythonClassifier     = regexp.MustCompile(`(?m)^Classifier: License :: (?:OSI Approved :: )?(.+)$`)
+)
+
+// Common license names used in package metadata mapped to SPDX identifiers
+var licenseNameAliases = map[string]string{
+	"mit license": "MIT", "apache software license": "Apache-2.0", "apache 2.0": "Apache-2.0", "apache-2": "Apache-2.0",
+	"apache license 2.0": "Apache-2.0", "bsd license": "BSD-3-Clause", "isc license (iscl)": "ISC", "bsd": "BSD-3-Clause",
+	"mozilla public license 2.0 (mpl 2.0)": "MPL-2.0", "gnu general public license v3 (gplv3)": "GPL-3.0-only",
+	"gnu general public license v2 (gplv2)": "GPL-2.0-only", "gnu affero general public license v3": "AGPL-3.0-only",
+	"gnu lesser general public license v3 (lgplv3)": "LGPL-3.0-only", "the unlicense (unlicense)": "Unlicense",
+}
+
+func loadLicenseTemplates() []licenseTemplate {
+	licenseTemplatesOnce.Do(func() {
+		entries, _ := licenseTemplateFiles.ReadDir("licenses")
+		for _, entry := range entries {
+			content, err := licenseTemplateFiles.ReadFile("licenses/" + entry.Name())
+			if err != nil {
+				continue
+			}
+			licenseTemplates = append(licenseTemplates, licenseTemplate{
+				ID:       strings.TrimSuffix(entry.Name(), ".txt"),
+				shingles: licenseShingles(string(content)),
+			})
+		}
+	})
+	return licenseTemplates
+}
+
+// Three-word shingles of the normalized text; copyright lines are dropped since holders differ
+func licenseShingles(text string) map[string]bool {
+	text = licenseCopyrightLine.ReplaceAllString(strings.ToLower(text), " ")
+	words := strings.Fields(licenseNonWordRegex.ReplaceAllString(text, " "))
+	shingles := make(map[string]bool)
+	for i := 0; i+2 < len(words); i++ {
+		shingles[words[i]+" "+words[i+1]+" "+words[i+2]] = true
+	}
+	return shingles
+}
+
+// Identify the license in a text, returning its SPDX identifier and the match confidence
+func identifyLicenseText(text string) (string, float64) {
+	if match := spdxIdentifierRegex.FindStringSubmatch(text); match != nil {
+		return strings.TrimSpace(match[1]), 1
+	}
+	if trimmed := strings.TrimSpace(text); !strings.Contains(trimmed, "\n") && licenseExpression([]string{trimmed}) != "" {
+		return trimmed, 1
+	}
+
+	shingles := licenseShingles(text)
+	bestID, bestScore := "", 0.0
+	for _, template := range loadLicenseTemplates() {
+		common := 0
+		for shingle := range shingles {
+			if template.shingles[shingle] {
+				common++
+			}
+		}
+		// Dice coefficient over the shingle sets
+		score := 2 * float64(common) / float64(len(shingles)+len(template.shingles))
+		if score > bestScore {
+			bestID, bestScore = template.ID, score
+		}
+	}
+	if bestScore < licenseMatchThreshold {
+		return "", bestScore
+	}
+	// The AGPL is the GPL v3 text with the network interaction clause added; the GPL itself
+	// mentions the Affero license in section 13, so only the title tells them apart
+	if bestID == "GPL-3.0-only" && strings.Contains(strings.ToLower(truncateLine(strings.TrimSpace(text), 200)), "affero") {
+		bestID = "AGPL-3.0-only"
+	}
+	return bestID, bestScore
+}
+
+// Normalize free-form license names from package metadata to SPDX identifiers
+func normalizeLicenseName(name string) string {
+	name = strings.TrimSpace(name)
+	if alias, ok := licenseNameAliases[strings.ToLower(name)]; ok {
+		return alias
+	}
+	return name
+}
+
+type ProjectLicense struct {
+	Source     string  `json:"source"`
+	License    string  `json:"license"`
+	Confidence float64 `json:"confidence"`
+}
+
+// The repository's own license, from license files at the root and manifest metadata
+func detectProjectLicenses() []ProjectLicense {
+	var licenses []ProjectLicense
+	entries, _ := os.ReadDir(".")
+	for _, entry := range entries {
+		if entry.IsDir() || !licenseFileRegex.MatchString(entry.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(entry.Name())
+		if err != nil {
+			continue
+		}
+		id, confidence := identifyLicenseText(string(content))
+		licenses = append(licenses, ProjectLicense{Source: entry.Name(), License: orDefault(id, "unknown"), Confidence: confidence})
+	}
+
+	var packageJSON struct {
+		License interface{} `json:"license"`
+	}
+	if content, err := os.ReadFile("package.json"); err == nil && json.Unmarshal(content, &packageJSON) == nil {
+		for _, license := range packageJSONLicenses(packageJSON.License) {
+			licenses = append(licenses, ProjectLicense{Source: "package.json", License: license, Confidence: 1})
+		}
+	}
+	var composerJSON struct {
+		License interface{} `json:"license"`
+	}
+	if content, err := os.ReadFile("composer.json"); err == nil && json.Unmarshal(content, &composerJSON) == nil {
+		if declared := packageJSONLicenses(composerJSON.License); len(declared) > 0 {
+			licenses = append(licenses, ProjectLicense{Source: "composer.json", License: strings.Join(declared, " OR "), Confidence: 1})
+		}
+	}
+	if manifest, err := readTOMLFile("Cargo.toml"); err == nil {
+		if license := yamlString(yamlMap(manifest["package"])["license"]); license != "" {
+			licenses = append(licenses, ProjectLicense{Source: "Cargo.toml", License: license, Confidence: 1})
+		}
+	}
+	if manifest, err := readTOMLFile("pyproject.toml"); err == nil {
+		license := yamlMap(manifest["project"])["license"]
+		if table := yamlMap(license); table != nil {
+			license = table["text"]
+		}
+		if license == nil {
+			license = yamlMap(yamlMap(manifest["tool"])["poetry"])["license"]
+		}
+		if name := yamlString(license); name != "" {
+			licenses = append(licenses, ProjectLicense{Source: "pyproject.toml", License: normalizeLicenseName(name), Confidence: 1})
+		}
+	}
+	return licenses
+}
+
+// Identify the license file in an installed or vendored package directory
+func licenseFromDir(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !licenseFileRegex.MatchString(entry.Name()) {
+			continue
+		}
+		if content, err := os.ReadFile(filepath.Join(dir, entry.Name())); err == nil {
+			if id, _ := identifyLicenseText(string(content)); id != "" {
+				return []string{id}
+			}
+		}
+	}
+	return nil
+}
+
+// Fill in licenses from installed or vendored copies for dependencies whose lockfile carries none
+func resolveDependencyLicenses(deps []Dependency) {
+	for i := range deps {
+		if len(deps[i].Licenses) > 0 {
+			continue
+		}
+		dep := &deps[i]
+		dir := filepath.Dir(dep.Source)
+		switch dep.Ecosystem {
+		case ecosystemNpm:
+			packageDir := filepath.Join(dir, "node_modules", filepath.FromSlash(dep.Name))
+			var manifest struct {
+				License  interface{} `json:"license"`
+				Licenses interface{} `json:"licenses"`
+			}
+			if content, err := os.ReadFile(filepath.Join(packageDir, "package.json")); err == nil && json.Unmarshal(content, &manifest) == nil {
+				dep.Licenses = packageJSONLicenses(manifest.License)
+				if len(dep.Licenses) == 0 {
+					dep.Licenses = packageJSONLicenses(manifest.Licenses)
+				}
+			}
+			if len(dep.Licenses) == 0 {
+				dep.Licenses = licenseFromDir(packageDir)
+			}
+		case ecosystemGo:
+			if dep.Local != "" {
+				dep.Licenses = licenseFromDir(filepath.Join(dir, filepath.FromSlash(dep.Local)))
+				break
+			}
+			dep.Licenses = licenseFromDir(filepath.Join(dir, "vendor", filepath.FromSlash(dep.Name)))
+			if len(dep.Licenses) == 0 && goModuleCacheDir() != "" {
+				dep.Licenses = licenseFromDir(filepath.Join(goModuleCacheDir(), filepath.FromSlash(goModuleCachePath(dep.Name))+"@"+dep.Version))
+			}
+		case ecosystemPackagist:
+			packageDir := filepath.Join(dir, "vendor", filepath.FromSlash(dep.Name))
+			var manifest struct {
+				License interface{} `json:"license"`
+			}
+			if content, err := os.ReadFile(filepath.Join(packageDir, "composer.json")); err == nil && json.Unmarshal(content, &manifest) == nil {
+				dep.Licenses = packageJSONLicenses(manifest.License)
+			}
+			if len(dep.Licenses) == 0 {
+				dep.Licenses = licenseFromDir(packageDir)
+			}
+		case ecosystemCrates:
+			sources, _ := filepath.Glob(filepath.Join(cargoHomeDir(), "registry", "src", "*", dep.Name+"-"+dep.Version))
+			for _, source := range sources {
+				if manifest, err := readTOMLFile(filepath.Join(source, "Cargo.toml")); err == nil {
+					if license := yamlString(yamlMap(manifest["package"])["license"]); license != "" {
+						dep.Licenses = []string{strings.ReplaceAll(license, "/", " OR ")}
+					}
+				}
+				if len(dep.Licenses) == 0 {
+					dep.Licenses = licenseFromDir(source)
+				}
+			}
+		case ecosystemRubyGems:
+			gems, _ := filepath.Glob(filepath.Join(dir, "vendor", "bundle", "ruby", "*", "gems", dep.Name+"-"+dep.Version))
+			for _, gem := range gems {
+				dep.Licenses = licenseFromDir(gem)
+			}
+		case ecosystemPyPI:
+			dep.Licenses = pythonDistributionLicenses(dir, dep.Name, dep.Version)
+		}
+	}
+}
+
+// License metadata of a package installed in a virtualenv next to the lockfile
+func pythonDistributionLicenses(dir, name, version string) []string {
+	var metadataFiles []string
+	for _, venv := range []string{".venv", "venv"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, venv, "lib", "python*", "site-packages", "*.dist-info", "METADATA"))
+		metadataFiles = append(metadataFiles, matches...)
+	}
+	for _, file := range metadataFiles {
+		distribution := strings.TrimSuffix(filepath.Base(filepath.Dir(file)), ".dist-info")
+		distName, distVersion, _ := strings.Cut(distribution, "-")
+		if normalizePythonName(distName) != name || distVersion != version {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil
+		}
+		if match := pythonLicenseRegex.FindStringSubmatch(string(content)); match != nil && strings.TrimSpace(match[1]) != "UNKNOWN" {
+			return []string{normalizeLicenseName(match[1])}
+		}
+		if match := pythonClassifier.FindStringSubmatch(string(content)); match != nil {
+			return []string{normalizeLicenseName(match[1])}
+		}
+		return licenseFromDir(filepath.Dir(file))
+	}
+	return nil
+}
+
+func goModuleCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	if gopath := os.Getenv("GOPATH"); gopath != "" {
+		return filepath.Join(strings.Split(gopath, string(os.PathListSeparator))[0], "pkg", "mod")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, "go", "pkg", "mod")
+	}
+	return ""
+}
+
+// The module cache escapes upper-case letters as "!" followed by the lower-case letter
+func goModuleCachePath(module string) string {
+	var escaped strings.Builder
+	for _, r := range module {
+		if r >= 'A' && r <= 'Z' {
+			escaped.WriteRune('!')
+			r += 'a' - 'A'
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+func cargoHomeDir() string {
+	if dir := os.Getenv("CARGO_HOME"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cargo")
+}
+
+type LicenseViolation struct {
+	Dependency Dependency
+	License    string
+	Reason     string
+	Severity   string
+}
+
+func licensePatternMatches(patterns []string, id string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(id)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Check every dependency license against the allow/deny policy. A dependency passes when at least
+// one way of satisfying its license expression uses only permitted licenses; it is denied when
+// every way includes a denied license.
+func checkLicensePolicy(policy LicensePolicyConfig, deps []Dependency) []LicenseViolation {
+	var violations []LicenseViolation
+	if len(policy.Allow) == 0 && len(policy.Deny) == 0 && !policy.FailOnUnknown {
+		return violations
+	}
+	for _, dep := range deps {
+		if licensePatternMatches(policy.Exclude, dep.Name) {
+			continue
+		}
+		choices := dependencyLicenseChoices(dep.Licenses)
+		if len(choices) == 0 {
+			if policy.FailOnUnknown {
+				violations = append(violations, LicenseViolation{Dependency: dep, License: "unknown", Reason: "license could not be determined", Severity: "low"})
+			}
+			continue
+		}
+
+		var denied []string
+		passes, alwaysDenied := false, true
+		for _, choice := range choices {
+			ok, choiceDenied := true, false
+			for _, id := range choice {
+				switch {
+				case licensePatternMatches(policy.Deny, id):
+					denied = appendUnique(denied, id)
+					ok, choiceDenied = false, true
+				case len(policy.Allow) > 0 && !licensePatternMatches(policy.Allow, id):
+					ok = false
+				}
+			}
+			passes = passes || ok
+			alwaysDenied = alwaysDenied && choiceDenied
+		}
+		if passes {
+			continue
+		}
+		license := strings.Join(dep.Licenses, " OR ")
+		if alwaysDenied {
+			violations = append(violations, LicenseViolation{Dependency: dep, License: license, Reason: "denied license " + strings.Join(denied, ", "), Severity: "high"})
+		} else {
+			violations = append(violations, LicenseViolation{Dependency: dep, License: license, Reason: "license not in the allow list", Severity: "medium"})
+		}
+	}
+	return violations
+}
+
+func licenseFindings(policy LicensePolicyConfig, dependencies []Dependency) []Finding {
+	var findings []Finding
+	for _, violation := range checkLicensePolicy(policy, dependencies) {
+		dep := violation.Dependency
+		ruleID, description := "license/not-allowed", "Dependency license is not in the allowed list"
+		switch {
+		case violation.License == "unknown":
+			ruleID, description = "license/unknown", "Dependency license could not be determined"
+		case violation.Severity == "high":
+			ruleID, description = "license/denied", "Dependency uses a denied license"
+		}
+		findings = append(findings, newFinding(ruleID, description, categoryLicense, violation.Severity,
+			fmt.Sprintf("%s %s@%s: %s (%s)", dep.Ecosystem, dep.Name, dep.Version, violation.Reason, violation.License),
+			dep.Source, findLineContaining(dep.Source, dep.Name), dep.Name+"@"+dep.Version))
+	}
+	return findings
+}
+
+func writeLicenseReport(output io.StringWriter, dependencies []Dependency) {
+	output.WriteString("\n### License Analysis ###\n")
+	projectLicenses := detectProjectLicenses()
+	if len(projectLicenses) == 0 {
+		output.WriteString("Project license: none found\n")
+	}
+	for _, license := range projectLicenses {
+		line := fmt.Sprintf("Project license: %s (%s", license.License, license.Source)
+		if license.Confidence < 1 {
+			line += fmt.Sprintf(", %.0f%% match", license.Confidence*100)
+		}
+		output.WriteString(line + ")\n")
+	}
+	if len(dependencies) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, dep := range dependencies {
+		counts[orDefault(strings.Join(dep.Licenses, " OR "), "unknown")]++
+	}
+	licenses := sortedKeys(counts)
+	sort.SliceStable(licenses, func(i, j int) bool { return counts[licenses[i]] > counts[licenses[j]] })
+	output.WriteString("Dependency licenses:\n")
+	for _, license := range licenses {
+		output.WriteString(fmt.Sprintf("  %-30s %d\n", license, counts[license]))
+	}
+
+	config, err := loadGrabitConfig()
+	if err != nil {
+		output.WriteString(fmt.Sprintf("Error loading license policy: %v\n", err))
+		return
+	}
+	violations := checkLicensePolicy(config.Licenses, dependencies)
+	if len(violations) > 0 {
+		output.WriteString("License policy violations:\n")
+		for _, violation := range violations {
+			dep := violation.Dependency
+			output.WriteString(fmt.Sprintf("  - [%s] %s@%s (%s): %s\n", violation.Severity, dep.Name, dep.Version, dep.Source, violation.Reason))
+		}
+	}
+}
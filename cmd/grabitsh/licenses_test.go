@@ -0,0 +1,51 @@
+package grabitsh
+
+import "testing"
+
+func TestCheckLicensePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   LicensePolicyConfig
+		licenses []string
+		severity string
+	}{
+		{"denied AND with a choice", LicensePolicyConfig{Deny: []string{"AGPL-*"}}, []string{"(MIT OR GPL-3.0-only) AND AGPL-3.0-only"}, "high"},
+		{"denied alternative avoidable", LicensePolicyConfig{Deny: []string{"AGPL-*"}}, []string{"MIT OR AGPL-3.0-only"}, ""},
+		{"AND binds tighter than OR", LicensePolicyConfig{Deny: []string{"AGPL-*"}}, []string{"MIT OR AGPL-3.0-only AND Apache-2.0"}, ""},
+		{"parenthesised OR then AND", LicensePolicyConfig{Deny: []string{"AGPL-*"}}, []string{"(MIT OR AGPL-3.0-only) AND Apache-2.0"}, ""},
+		{"single denied", LicensePolicyConfig{Deny: []string{"GPL-*"}}, []string{"GPL-3.0-only WITH GCC-exception-3.1"}, "high"},
+		{"any declared license may be used", LicensePolicyConfig{Deny: []string{"GPL-*"}}, []string{"GPL-2.0-only", "MIT"}, ""},
+		{"allow list satisfied", LicensePolicyConfig{Allow: []string{"MIT", "Apache-2.0"}}, []string{"(MIT OR ISC) AND Apache-2.0"}, ""},
+		{"allow list not satisfied", LicensePolicyConfig{Allow: []string{"MIT"}}, []string{"MIT AND ISC"}, "medium"},
+		{"denied on one path, not allowed on the other", LicensePolicyConfig{Allow: []string{"MIT"}, Deny: []string{"AGPL-*"}}, []string{"AGPL-3.0-only OR ISC"}, "medium"},
+		{"unparseable falls back to every identifier", LicensePolicyConfig{Deny: []string{"AGPL-*"}}, []string{"MIT AND (AGPL-3.0-only"}, "high"},
+		{"unknown reported", LicensePolicyConfig{FailOnUnknown: true}, nil, "low"},
+		{"unknown ignored", LicensePolicyConfig{Deny: []string{"AGPL-*"}}, nil, ""},
+	}
+	for _, test := range tests {
+		deps := []Dependency{{Name: "pkg", Version: "1.0.0", Licenses: test.licenses}}
+		violations := checkLicensePolicy(test.policy, deps)
+		severity := ""
+		if len(violations) == 1 {
+			severity = violations[0].Severity
+		} else if len(violations) > 1 {
+			t.Errorf("%s: got %d violations", test.name, len(violations))
+			continue
+		}
+		if severity != test.severity {
+			t.Errorf("%s: severity %q, want %q (%v)", test.name, severity, test.severity, violations)
+		}
+	}
+}
+
+func TestCheckLicensePolicyExclude(t *testing.T) {
+	policy := LicensePolicyConfig{Deny: []string{"AGPL-*"}, Exclude: []string{"@internal/*"}}
+	deps := []Dependency{
+		{Name: "@internal/tool", Licenses: []string{"AGPL-3.0-only"}},
+		{Name: "other", Licenses: []string{"AGPL-3.0-only"}},
+	}
+	violations := checkLicensePolicy(policy, deps)
+	if len(violations) != 1 || violations[0].Dependency.Name != "other" {
+		t.Errorf("violations = %v, want only other", violations)
+	}
+}
@@ -0,0 +1,713 @@
+package grabitsh
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ecosystem names follow the OSV schema so dependencies can be matched against advisories directly
+const (
+	ecosystemNpm       = "npm"
+	ecosystemGo        = "Go"
+	ecosystemPyPI      = "PyPI"
+	ecosystemRubyGems  = "RubyGems"
+	ecosystemCrates    = "crates.io"
+	ecosystemPackagist = "Packagist"
+	ecosystemMaven     = "Maven"
+)
+
+const (
+	scopeProd = "prod"
+	scopeDev  = "dev"
+)
+
+type Dependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	Source    string `json:"source"`
+	// Direct dependencies are declared in the project manifest, the rest are pulled in transitively
+	Direct bool `json:"direct"`
+	// Directory a Go replace directive points the module at, relative to the go.mod; it has no registry version
+	Local string `json:"local,omitempty"`
+	// Manifests that declare a direct dependency, when the lockfile covers workspace packages
+	DeclaredBy []string `json:"declared_by,omitempty"`
+	Scope      string   `json:"scope,omitempty"`
+	Integrity  string   `json:"integrity,omitempty"`
+	Licenses   []string `json:"licenses,omitempty"`
+	Requires   []string `json:"requires,omitempty"`
+	// Versions of required packages where the lockfile pins them per dependent (npm nesting, yarn,
+	// pnpm, Cargo); other requirements resolve by name within the same lockfile
+	RequiredVersions map[string]string `json:"-"`
+}
+
+type lockfileParser func(path string) ([]Dependency, error)
+
+var lockfileParsers = map[string]lockfileParser{
+	"package-lock.json":   parsePackageLock,
+	"npm-shrinkwrap.json": parsePackageLock,
+	"yarn.lock":           parseYarnLock,
+	"pnpm-lock.yaml":      parsePnpmLock,
+	"go.mod":              parseGoModDependencies,
+	"Gemfile.lock":        parseGemfileLock,
+	"Cargo.lock":          parseCargoLock,
+	"poetry.lock":         parsePoetryLock,
+	"Pipfile.lock":        parsePipfileLock,
+	"composer.lock":       parseComposerLock,
+	"requirements.txt":    parseRequirementsTxt,
+	"gradle.lockfile":     parseGradleLockfile,
+	"pom.xml":             parseMavenPOM,
+}
+
+// Find every supported lockfile in the tree and parse it into a flat dependency list
+func collectDependencies() ([]Dependency, []error) {
+	var dependencies []Dependency
+	var errs []error
+
+	for _, path := range findLockfiles() {
+		parser := lockfileParsers[lockfileName(path)]
+		deps, err := parser(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error parsing %s: %w", path, err))
+			continue
+		}
+		propagateScopes(deps)
+		resolveDependencyLicenses(deps)
+		sort.SliceStable(deps, func(i, j int) bool {
+			if deps[i].Name != deps[j].Name {
+				return deps[i].Name < deps[j].Name
+			}
+			return deps[i].Version < deps[j].Version
+		})
+		dependencies = append(dependencies, deps...)
+	}
+
+	return dependencies, errs
+}
+
+func findLockfiles() []string {
+	var lockfiles []string
+	for _, path := range findRepositoryFiles(func(name string) bool {
+		_, ok := lockfileParsers[name]
+		return ok || strings.HasSuffix(name, ".txt")
+	}) {
+		if _, ok := lockfileParsers[lockfileName(path)]; ok {
+			lockfiles = append(lockfiles, filepath.ToSlash(path))
+		}
+	}
+	return lockfiles
+}
+
+// The lockfileParsers key for a file; pip requirements variants such as requirements-dev.txt
+// and requirements/test.txt all map to requirements.txt
+func lockfileName(path string) string {
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, ".txt") && (requirementsFileRegex.MatchString(strings.ToLower(name)) || filepath.Base(filepath.Dir(path)) == "requirements") {
+		return "requirements.txt"
+	}
+	return name
+}
+
+// Fill in the scope of transitive dependencies from the direct dependencies that pull them in, by package name.
+// Anything reachable from a production dependency is production, the rest of the dev tree is dev.
+func propagateScopes(deps []Dependency) {
+	byName := make(map[string][]int)
+	for i, dep := range deps {
+		byName[dep.Name] = append(byName[dep.Name], i)
+	}
+	for _, scope := range []string{scopeProd, scopeDev} {
+		var queue []string
+		visited := make(map[string]bool)
+		for _, dep := range deps {
+			if dep.Direct && dep.Scope == scope && !visited[dep.Name] {
+				visited[dep.Name] = true
+				queue = append(queue, dep.Name)
+			}
+		}
+		// Queue entries are names, or name@version where the dependent's lockfile entry pins the version
+		for len(queue) > 0 {
+			name, version, pinned := strings.Cut(queue[0], "@\x00")
+			queue = queue[1:]
+			for _, i := range byName[name] {
+				if pinned && deps[i].Version != version {
+					continue
+				}
+				if deps[i].Scope == "" {
+					deps[i].Scope = scope
+				}
+				for _, required := range deps[i].Requires {
+					if version, ok := deps[i].RequiredVersions[required]; ok {
+						required += "@\x00" + version
+					}
+					if !visited[required] {
+						visited[required] = true
+						queue = append(queue, required)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Names and scopes declared in a manifest, keyed by package name
+type declaredDependencies map[string]declaredDependency
+
+type declaredDependency struct {
+	Scope      string
+	Constraint string
+}
+
+func (declared declaredDependencies) add(names map[string]interface{}, scope string) {
+	for name, constraint := range names {
+		// A package listed as both keeps its production scope
+		if existing, ok := declared[name]; ok && existing.Scope == scopeProd {
+			continue
+		}
+		value, _ := constraint.(string)
+		declared[name] = declaredDependency{Scope: scope, Constraint: value}
+	}
+}
+
+// Mark the dependencies named in the manifest as direct, with the manifest's scope
+func (declared declaredDependencies) apply(deps []Dependency) {
+	for i := range deps {
+		if entry, ok := declared[deps[i].Name]; ok {
+			deps[i].Direct = true
+			if deps[i].Scope == "" {
+				deps[i].Scope = entry.Scope
+			}
+		}
+	}
+}
+
+func parseGoModDependencies(path string) ([]Dependency, error) {
+	mod, err := parseGoModFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sums := readGoSum(filepath.Join(filepath.Dir(path), "go.sum"))
+
+	var deps []Dependency
+	for _, requirement := range mod.Require {
+		dep := Dependency{Name: requirement.Path, Version: requirement.Version, Ecosystem: ecosystemGo, Source: path,
+			Direct: !requirement.Indirect, Scope: scopeProd}
+		// The build uses the replacement, and a directory replacement has no version to look up
+		if replacement, ok := mod.replacementFor(requirement.Path, requirement.Version); ok {
+			if replacement.isLocal() {
+				dep.Version, dep.Local = "", filepath.ToSlash(replacement.New)
+			} else {
+				dep.Name, dep.Version = replacement.New, replacement.NewVersion
+			}
+		}
+		dep.Integrity = sums[dep.Name+"@"+dep.Version]
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// go.sum hashes of module contents keyed by module@version; go.mod-only hashes are skipped
+func readGoSum(path string) map[string]string {
+	sums := make(map[string]string)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return sums
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && !strings.HasSuffix(fields[1], "/go.mod") {
+			sums[fields[0]+"@"+fields[1]] = fields[2]
+		}
+	}
+	return sums
+}
+
+var (
+	gemSpecRegex      = regexp.MustCompile(`^    ([^\s(]+) \(([^)]+)\)$`)
+	gemRequireRegex   = regexp.MustCompile(`^      ([^\s(]+)`)
+	gemDirectRegex    = regexp.MustCompile(`^  ([^\s(!]+)`)
+	gemChecksumRegex  = regexp.MustCompile(`^  ([^\s(]+) \(([^)]+)\) (\S+)`)
+	gemfileGemRegex   = regexp.MustCompile(`^\s*gem\s+["']([^"']+)["'](.*)$`)
+	gemfileGroupRegex = regexp.MustCompile(`^\s*group\s+(.*?)\s+do\b`)
+	gemDevGroupRegex  = regexp.MustCompile(`:(development|test)\b|["'](development|test)["']`)
+)
+
+func parseGemfileLock(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	direct := make(map[string]bool)
+	checksums := make(map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && !strings.HasPrefix(line, " ") {
+			section = line
+			continue
+		}
+		switch section {
+		case "GEM", "GIT", "PATH":
+			// Four spaces of indentation is a resolved gem, six is one of its requirements
+			if match := gemSpecRegex.FindStringSubmatch(line); match != nil {
+				deps = append(deps, Dependency{Name: match[1], Version: match[2], Ecosystem: ecosystemRubyGems, Source: path})
+			} else if match := gemRequireRegex.FindStringSubmatch(line); match != nil && len(deps) > 0 {
+				deps[len(deps)-1].Requires = appendUnique(deps[len(deps)-1].Requires, match[1])
+			}
+		case "DEPENDENCIES":
+			if match := gemDirectRegex.FindStringSubmatch(line); match != nil {
+				direct[match[1]] = true
+			}
+		case "CHECKSUMS":
+			if match := gemChecksumRegex.FindStringSubmatch(line); match != nil {
+				checksums[match[1]+"@"+match[2]] = match[3]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	devGems := readGemfileDevGems(filepath.Join(filepath.Dir(path), "Gemfile"))
+	for i := range deps {
+		deps[i].Integrity = checksums[deps[i].Name+"@"+deps[i].Version]
+		if direct[deps[i].Name] {
+			deps[i].Direct = true
+			deps[i].Scope = scopeProd
+			if devGems[deps[i].Name] {
+				deps[i].Scope = scopeDev
+			}
+		}
+	}
+	return deps, nil
+}
+
+// Gems declared only for the development or test groups, either in a group block or inline
+func readGemfileDevGems(path string) map[string]bool {
+	devGems := make(map[string]bool)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return devGems
+	}
+	var groups []bool
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case gemfileGroupRegex.MatchString(line):
+			groups = append(groups, gemDevGroupRegex.MatchString(gemfileGroupRegex.FindStringSubmatch(line)[1]))
+		case strings.HasSuffix(trimmed, " do") || strings.Contains(trimmed, " do |"):
+			groups = append(groups, len(groups) > 0 && groups[len(groups)-1])
+		case trimmed == "end" && len(groups) > 0:
+			groups = groups[:len(groups)-1]
+		default:
+			if match := gemfileGemRegex.FindStringSubmatch(line); match != nil {
+				inDevGroup := len(groups) > 0 && groups[len(groups)-1]
+				inlineDev := strings.Contains(match[2], "group") && gemDevGroupRegex.MatchString(match[2])
+				if inDevGroup || inlineDev {
+					devGems[match[1]] = true
+				}
+			}
+		}
+	}
+	return devGems
+}
+
+func parseCargoLock(path string) ([]Dependency, error) {
+	lock, err := readTOMLFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	devCrates := make(map[string]bool)
+	if manifest, err := readTOMLFile(filepath.Join(filepath.Dir(path), "Cargo.toml")); err == nil {
+		for name := range yamlMap(manifest["dev-dependencies"]) {
+			devCrates[name] = true
+		}
+		for name := range yamlMap(manifest["build-dependencies"]) {
+			devCrates[name] = true
+		}
+	}
+
+	var deps []Dependency
+	direct := make(map[string]bool)
+	for _, pkg := range tomlTables(lock["package"]) {
+		var requires []string
+		requiredVersions := make(map[string]string)
+		for _, entry := range yamlList(pkg["dependencies"]) {
+			// Entries are "name", "name version" or "name version (source)"
+			fields := strings.Fields(yamlString(entry) + " ")
+			requires = appendUnique(requires, fields[0])
+			if len(fields) > 1 {
+				requiredVersions[fields[0]] = fields[1]
+			}
+		}
+		// Packages without a source are the workspace's own crates
+		if _, ok := pkg["source"]; !ok {
+			for _, name := range requires {
+				direct[name] = true
+			}
+			continue
+		}
+		deps = append(deps, Dependency{Name: tomlString(pkg, "name"), Version: tomlString(pkg, "version"), Ecosystem: ecosystemCrates,
+			Source: path, Integrity: tomlString(pkg, "checksum"), Requires: requires, RequiredVersions: requiredVersions})
+	}
+	for i := range deps {
+		if direct[deps[i].Name] {
+			deps[i].Direct = true
+			deps[i].Scope = scopeProd
+			if devCrates[deps[i].Name] {
+				deps[i].Scope = scopeDev
+			}
+		}
+	}
+	return deps, nil
+}
+
+var (
+	pythonNameSeparatorRegex = regexp.MustCompile(`[-_.]+`)
+	pep508NameRegex          = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+)
+
+// PEP 503 name normalization so manifest and lockfile names compare equal
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparatorRegex.ReplaceAllString(name, "-"))
+}
+
+// Dependencies declared in pyproject.toml, Poetry or PEP 621 style
+func readPyprojectDependencies(path string) declaredDependencies {
+	declared := make(declaredDependencies)
+	manifest, err := readTOMLFile(path)
+	if err != nil {
+		return declared
+	}
+	addList := func(items []interface{}, scope string) {
+		for _, item := range items {
+			if match := pep508NameRegex.FindStringSubmatch(yamlString(item)); match != nil {
+				declared.add(map[string]interface{}{normalizePythonName(match[1]): ""}, scope)
+			}
+		}
+	}
+	addTable := func(table map[string]interface{}, scope string) {
+		for name := range table {
+			if name != "python" {
+				declared.add(map[string]interface{}{normalizePythonName(name): ""}, scope)
+			}
+		}
+	}
+
+	project := yamlMap(manifest["project"])
+	addList(yamlList(project["dependencies"]), scopeProd)
+	for _, extras := range yamlMap(project["optional-dependencies"]) {
+		addList(yamlList(extras), scopeProd)
+	}
+	poetry := yamlMap(yamlMap(manifest["tool"])["poetry"])
+	addTable(yamlMap(poetry["dependencies"]), scopeProd)
+	addTable(yamlMap(poetry["dev-dependencies"]), scopeDev)
+	for group, settings := range yamlMap(poetry["group"]) {
+		scope := scopeDev
+		if group == "main" {
+			scope = scopeProd
+		}
+		addTable(yamlMap(yamlMap(settings)["dependencies"]), scope)
+	}
+	return declared
+}
+
+func parsePoetryLock(path string) ([]Dependency, error) {
+	lock, err := readTOMLFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Poetry before 1.2 keeps file hashes in a separate metadata table
+	legacyFiles := yamlMap(yamlMap(lock["metadata"])["files"])
+
+	var deps []Dependency
+	for _, pkg := range tomlTables(lock["package"]) {
+		name := tomlString(pkg, "name")
+		dep := Dependency{Name: name, Version: tomlString(pkg, "version"), Ecosystem: ecosystemPyPI, Source: path}
+		switch tomlString(pkg, "category") {
+		case "main":
+			dep.Scope = scopeProd
+		case "dev":
+			dep.Scope = scopeDev
+		}
+		files := yamlList(pkg["files"])
+		if len(files) == 0 {
+			files = yamlList(legacyFiles[name])
+		}
+		if len(files) > 0 {
+			dep.Integrity = yamlString(yamlMap(files[0])["hash"])
+		}
+		for required := range yamlMap(pkg["dependencies"]) {
+			dep.Requires = append(dep.Requires, normalizePythonName(required))
+		}
+		sort.Strings(dep.Requires)
+		dep.Name = normalizePythonName(name)
+		deps = append(deps, dep)
+	}
+	readPyprojectDependencies(filepath.Join(filepath.Dir(path), "pyproject.toml")).apply(deps)
+	return deps, nil
+}
+
+func parsePipfileLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock map[string]json.RawMessage
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	declared := make(declaredDependencies)
+	if pipfile, err := readTOMLFile(filepath.Join(filepath.Dir(path), "Pipfile")); err == nil {
+		for name := range yamlMap(pipfile["packages"]) {
+			declared.add(map[string]interface{}{normalizePythonName(name): ""}, scopeProd)
+		}
+		for name := range yamlMap(pipfile["dev-packages"]) {
+			declared.add(map[string]interface{}{normalizePythonName(name): ""}, scopeDev)
+		}
+	}
+
+	var deps []Dependency
+	for _, section := range []string{"default", "develop"} {
+		var packages map[string]struct {
+			Version string   `json:"version"`
+			Hashes  []string `json:"hashes"`
+		}
+		if raw, ok := lock[section]; ok {
+			if err := json.Unmarshal(raw, &packages); err != nil {
+				return nil, err
+			}
+		}
+		scope := scopeProd
+		if section == "develop" {
+			scope = scopeDev
+		}
+		for name, pkg := range packages {
+			if pkg.Version == "" {
+				continue
+			}
+			dep := Dependency{Name: normalizePythonName(name), Version: strings.TrimPrefix(pkg.Version, "=="), Ecosystem: ecosystemPyPI, Source: path, Scope: scope}
+			if len(pkg.Hashes) > 0 {
+				dep.Integrity = pkg.Hashes[0]
+			}
+			deps = append(deps, dep)
+		}
+	}
+	declared.apply(deps)
+	return deps, nil
+}
+
+func parseComposerLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	type composerPackage struct {
+		Name    string            `json:"name"`
+		Version string            `json:"version"`
+		Require map[string]string `json:"require"`
+		License []string          `json:"license"`
+		Dist    struct {
+			Shasum string `json:"shasum"`
+		} `json:"dist"`
+	}
+	var lock struct {
+		Packages    []composerPackage `json:"packages"`
+		PackagesDev []composerPackage `json:"packages-dev"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	for i, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		dep := Dependency{Name: pkg.Name, Version: strings.TrimPrefix(pkg.Version, "v"), Ecosystem: ecosystemPackagist, Source: path,
+			Scope: scopeProd, Integrity: pkg.Dist.Shasum, Licenses: pkg.License}
+		if i >= len(lock.Packages) {
+			dep.Scope = scopeDev
+		}
+		for required := range pkg.Require {
+			// Platform requirements are not packages
+			if required != "php" && !strings.HasPrefix(required, "ext-") && !strings.HasPrefix(required, "lib-") {
+				dep.Requires = append(dep.Requires, required)
+			}
+		}
+		sort.Strings(dep.Requires)
+		deps = append(deps, dep)
+	}
+
+	var manifest struct {
+		Require    map[string]interface{} `json:"require"`
+		RequireDev map[string]interface{} `json:"require-dev"`
+	}
+	if content, err := os.ReadFile(filepath.Join(filepath.Dir(path), "composer.json")); err == nil && json.Unmarshal(content, &manifest) == nil {
+		declared := make(declaredDependencies)
+		declared.add(manifest.Require, scopeProd)
+		declared.add(manifest.RequireDev, scopeDev)
+		declared.apply(deps)
+	}
+	return deps, nil
+}
+
+var (
+	requirementPinRegex  = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*===?\s*([^\s;#\\]+)`)
+	requirementHashRegex = regexp.MustCompile(`--hash[= ](\S+)`)
+	// requirements.txt, requirements-dev.txt, dev-requirements.txt, requirements.test.txt, ...
+	requirementsFileRegex = regexp.MustCompile(`^(?:[a-z0-9]+[-_.])*requirements(?:[-_.][a-z0-9]+)*\.txt$`)
+)
+
+// Only exact pins (name==version) identify an installed version. pip-compile output marks
+// transitive pins with "# via <package>" annotations; requirements sources (-r) mean direct.
+func parseRequirementsTxt(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scope := scopeProd
+	if isDevRequirementsFile(path) {
+		scope = scopeDev
+	}
+
+	var deps []Dependency
+	var viaPackages []bool
+	logical := ""
+	inVia := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		trimmed := strings.TrimSpace(raw)
+
+		if strings.HasPrefix(trimmed, "#") && len(deps) > 0 {
+			comment := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+			if strings.HasPrefix(comment, "via") {
+				inVia = true
+				comment = strings.TrimSpace(strings.TrimPrefix(comment, "via"))
+			}
+			if inVia && comment != "" {
+				last := len(deps) - 1
+				if strings.HasPrefix(comment, "-r ") || strings.HasPrefix(comment, "-c ") || strings.HasSuffix(comment, ".in") || strings.HasSuffix(comment, ".txt") || strings.HasSuffix(comment, ".toml") {
+					deps[last].Direct = true
+				} else {
+					viaPackages[last] = true
+				}
+			}
+			continue
+		}
+		inVia = false
+
+		logical += strings.TrimSuffix(trimmed, "\\") + " "
+		if strings.HasSuffix(trimmed, "\\") {
+			continue
+		}
+		line := strings.TrimSpace(logical)
+		logical = ""
+		if match := requirementPinRegex.FindStringSubmatch(line); match != nil {
+			dep := Dependency{Name: normalizePythonName(match[1]), Version: match[2], Ecosystem: ecosystemPyPI, Source: path, Scope: scope}
+			if hash := requirementHashRegex.FindStringSubmatch(line); hash != nil {
+				dep.Integrity = hash[1]
+			}
+			deps = append(deps, dep)
+			viaPackages = append(viaPackages, false)
+		}
+	}
+	// Without pip-compile annotations every pin is taken to be declared on purpose
+	for i := range deps {
+		if !viaPackages[i] {
+			deps[i].Direct = true
+		}
+	}
+	return deps, scanner.Err()
+}
+
+// Dev requirements are named for it: requirements-dev.txt, test-requirements.txt, requirements/test.txt.
+// Directories elsewhere in the path do not count, so devops/requirements.txt stays production.
+func isDevRequirementsFile(path string) bool {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".txt")
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		switch word {
+		case "dev", "develop", "development", "test", "tests", "testing":
+			return true
+		}
+	}
+	return false
+}
+
+func readTOMLFile(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTOML(string(content))
+}
+
+func tomlTables(value interface{}) []map[string]interface{} {
+	var tables []map[string]interface{}
+	array, _ := value.([]interface{})
+	for _, item := range array {
+		if table, ok := item.(map[string]interface{}); ok {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+func tomlString(table map[string]interface{}, key string) string {
+	value, _ := table[key].(string)
+	return value
+}
+
+// Per-lockfile package counts followed by the direct dependencies with their scope
+func writeDependencySummary(output io.StringWriter, dependencies []Dependency) {
+	var sources []string
+	bySource := make(map[string][]Dependency)
+	for _, dep := range dependencies {
+		if _, ok := bySource[dep.Source]; !ok {
+			sources = append(sources, dep.Source)
+		}
+		bySource[dep.Source] = append(bySource[dep.Source], dep)
+	}
+
+	for _, source := range sources {
+		deps := bySource[source]
+		var direct []Dependency
+		prod, dev, hashed := 0, 0, 0
+		for _, dep := range deps {
+			if dep.Direct {
+				direct = append(direct, dep)
+				if dep.Scope == scopeDev {
+					dev++
+				} else {
+					prod++
+				}
+			}
+			if dep.Integrity != "" {
+				hashed++
+			}
+		}
+		output.WriteString(fmt.Sprintf("%s (%s): %d packages, %d direct (%d prod, %d dev), %d transitive, %d with integrity hashes\n",
+			source, deps[0].Ecosystem, len(deps), len(direct), prod, dev, len(deps)-len(direct), hashed))
+		for _, dep := range direct {
+			version := orDash(dep.Version)
+			if dep.Local != "" {
+				version = "=> " + dep.Local
+			}
+			output.WriteString(fmt.Sprintf("  - %s %s [%s]\n", dep.Name, version, orDefault(dep.Scope, scopeProd)))
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package grabitsh
+
+import (
+	"bufio"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	gradleCoordinateRegex = regexp.MustCompile(`["']([\w.\-]+):([\w.\-]+)(?::[^"'\s]*)?["']`)
+	mavenPropertyRegex    = regexp.MustCompile(`\$\{([^}]+)\}`)
+)
+
+// gradle.lockfile lines are group:artifact:version=configuration,...
+func parseGradleLockfile(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// The lockfile does not say which dependencies are declared, the build script does
+	declared := make(map[string]bool)
+	for _, script := range []string{"build.gradle", "build.gradle.kts"} {
+		content, err := os.ReadFile(filepath.Join(filepath.Dir(path), script))
+		if err != nil {
+			continue
+		}
+		for _, match := range gradleCoordinateRegex.FindAllStringSubmatch(string(content), -1) {
+			declared[match[1]+":"+match[2]] = true
+		}
+	}
+
+	var deps []Dependency
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		coordinates, configurations, found := strings.Cut(line, "=")
+		parts := strings.Split(coordinates, ":")
+		if !found || strings.HasPrefix(line, "#") || len(parts) != 3 {
+			continue
+		}
+		name := parts[0] + ":" + parts[1]
+		dep := Dependency{Name: name, Version: parts[2], Ecosystem: ecosystemMaven, Source: path, Scope: scopeDev, Direct: declared[name]}
+		for _, configuration := range strings.Split(configurations, ",") {
+			if !strings.Contains(strings.ToLower(configuration), "test") {
+				dep.Scope = scopeProd
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps, scanner.Err()
+}
+
+type mavenProperties map[string]string
+
+func (properties *mavenProperties) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	*properties = make(mavenProperties)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err := decoder.DecodeElement(&value, &element); err != nil {
+				return err
+			}
+			(*properties)[element.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type mavenDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+type mavenProject struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties   mavenProperties   `xml:"properties"`
+	Dependencies []mavenDependency `xml:"dependencies>dependency"`
+	Managed      []mavenDependency `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+// Resolve ${property} references; versions that stay unresolved are left empty
+func (project *mavenProject) resolve(value string) string {
+	resolved := mavenPropertyRegex.ReplaceAllStringFunc(value, func(reference string) string {
+		name := strings.TrimSuffix(strings.TrimPrefix(reference, "${"), "}")
+		switch name {
+		case "project.version", "pom.version", "version":
+			return orDefault(project.Version, project.Parent.Version)
+		case "project.groupId", "pom.groupId":
+			return orDefault(project.GroupID, project.Parent.GroupID)
+		case "project.parent.version", "parent.version":
+			return project.Parent.Version
+		}
+		if property, ok := project.Properties[name]; ok && !strings.Contains(property, "${") {
+			return property
+		}
+		return reference
+	})
+	if strings.Contains(resolved, "${") {
+		return ""
+	}
+	return resolved
+}
+
+// pom.xml declares direct dependencies only; the transitive closure needs Maven itself
+func parseMavenPOM(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var project mavenProject
+	if err := xml.Unmarshal(content, &project); err != nil {
+		return nil, err
+	}
+
+	managed := make(map[string]string)
+	for _, dependency := range project.Managed {
+		managed[dependency.GroupID+":"+dependency.ArtifactID] = project.resolve(dependency.Version)
+	}
+
+	var deps []Dependency
+	for _, dependency := range project.Dependencies {
+		name := project.resolve(dependency.GroupID) + ":" + dependency.ArtifactID
+		version := project.resolve(dependency.Version)
+		if version == "" {
+			version = managed[dependency.GroupID+":"+dependency.ArtifactID]
+		}
+		dep := Dependency{Name: name, Version: version, Ecosystem: ecosystemMaven, Source: path, Direct: true, Scope: scopeProd}
+		if dependency.Scope == "test" {
+			dep.Scope = scopeDev
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
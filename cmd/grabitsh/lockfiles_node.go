@@ -0,0 +1,499 @@
+package grabitsh
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Dependencies declared in the package.json next to a lockfile
+func readPackageJSONDependencies(dir string) declaredDependencies {
+	declared := make(declaredDependencies)
+	var manifest struct {
+		Dependencies         map[string]interface{} `json:"dependencies"`
+		OptionalDependencies map[string]interface{} `json:"optionalDependencies"`
+		DevDependencies      map[string]interface{} `json:"devDependencies"`
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil || json.Unmarshal(content, &manifest) != nil {
+		return declared
+	}
+	declared.add(manifest.Dependencies, scopeProd)
+	declared.add(manifest.OptionalDependencies, scopeProd)
+	declared.add(manifest.DevDependencies, scopeDev)
+	return declared
+}
+
+// Split "name@range" into its parts, keeping the leading @ of scoped packages
+func splitPackageSpec(spec string) (string, string) {
+	if len(spec) < 2 {
+		return spec, ""
+	}
+	i := strings.Index(spec[1:], "@")
+	if i < 0 {
+		return spec, ""
+	}
+	return spec[:i+1], spec[i+2:]
+}
+
+// The license field is an SPDX expression, or a {type} object / list of them in old packages
+func packageJSONLicenses(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case map[string]interface{}:
+		return packageJSONLicenses(v["type"])
+	case []interface{}:
+		var licenses []string
+		for _, item := range v {
+			licenses = append(licenses, packageJSONLicenses(item)...)
+		}
+		return licenses
+	}
+	return nil
+}
+
+func sortedNames(maps ...map[string]interface{}) []string {
+	var names []string
+	for _, m := range maps {
+		for name := range m {
+			names = appendUnique(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type packageLockPackage struct {
+	Name                 string                 `json:"name"`
+	Version              string                 `json:"version"`
+	Integrity            string                 `json:"integrity"`
+	License              interface{}            `json:"license"`
+	Link                 bool                   `json:"link"`
+	Dev                  bool                   `json:"dev"`
+	DevOptional          bool                   `json:"devOptional"`
+	Dependencies         map[string]interface{} `json:"dependencies"`
+	OptionalDependencies map[string]interface{} `json:"optionalDependencies"`
+	DevDependencies      map[string]interface{} `json:"devDependencies"`
+}
+
+type packageLockV1Dependency struct {
+	Version      string                             `json:"version"`
+	Integrity    string                             `json:"integrity"`
+	Dev          bool                               `json:"dev"`
+	Requires     map[string]interface{}             `json:"requires"`
+	Dependencies map[string]packageLockV1Dependency `json:"dependencies"`
+}
+
+func parsePackageLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock struct {
+		LockfileVersion int                                `json:"lockfileVersion"`
+		Packages        map[string]packageLockPackage      `json:"packages"`
+		Dependencies    map[string]packageLockV1Dependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	// lockfileVersion 2 and 3 list every installed package under "packages" keyed by node_modules path
+	if len(lock.Packages) > 0 {
+		// The root project and workspace packages are the entries outside node_modules, and each of
+		// their declarations is the copy Node loads from that package's directory
+		declaredBy := make(map[string][]string)
+		for _, key := range sortedKeys(lock.Packages) {
+			pkg := lock.Packages[key]
+			if strings.Contains(key, "node_modules/") {
+				continue
+			}
+			manifest := filepath.ToSlash(filepath.Join(filepath.Dir(path), key, "package.json"))
+			for _, name := range sortedNames(pkg.Dependencies, pkg.OptionalDependencies, pkg.DevDependencies) {
+				if installed, ok := resolveNodeModule(lock.Packages, key, name); ok {
+					declaredBy[installed] = append(declaredBy[installed], manifest)
+				}
+			}
+		}
+		for key, pkg := range lock.Packages {
+			if key == "" || pkg.Link || !strings.Contains(key, "node_modules/") {
+				continue
+			}
+			name := pkg.Name
+			if name == "" {
+				name = key[strings.LastIndex(key, "node_modules/")+len("node_modules/"):]
+			}
+			dep := Dependency{Name: name, Version: pkg.Version, Ecosystem: ecosystemNpm, Source: path, Scope: scopeProd,
+				Integrity: pkg.Integrity, Licenses: packageJSONLicenses(pkg.License), Requires: sortedNames(pkg.Dependencies, pkg.OptionalDependencies),
+				RequiredVersions: make(map[string]string)}
+			for _, required := range dep.Requires {
+				if installed, ok := resolveNodeModule(lock.Packages, key, required); ok {
+					dep.RequiredVersions[required] = lock.Packages[installed].Version
+				}
+			}
+			if pkg.Dev || pkg.DevOptional {
+				dep.Scope = scopeDev
+			}
+			dep.DeclaredBy = declaredBy[key]
+			dep.Direct = len(dep.DeclaredBy) > 0
+			deps = append(deps, dep)
+		}
+		return deps, nil
+	}
+
+	declared := readPackageJSONDependencies(filepath.Dir(path))
+	// ancestors holds the nested dependency maps from the top level down to the current package
+	var walk func(map[string]packageLockV1Dependency, []map[string]packageLockV1Dependency)
+	walk = func(dependencies map[string]packageLockV1Dependency, ancestors []map[string]packageLockV1Dependency) {
+		topLevel := len(ancestors) == 0
+		scopes := append([]map[string]packageLockV1Dependency{dependencies}, ancestors...)
+		for name, pkg := range dependencies {
+			dep := Dependency{Name: name, Version: pkg.Version, Ecosystem: ecosystemNpm, Source: path, Scope: scopeProd,
+				Integrity: pkg.Integrity, Requires: sortedNames(pkg.Requires), RequiredVersions: make(map[string]string)}
+			for _, required := range dep.Requires {
+				for _, scope := range append([]map[string]packageLockV1Dependency{pkg.Dependencies}, scopes...) {
+					if installed, ok := scope[required]; ok {
+						dep.RequiredVersions[required] = installed.Version
+						break
+					}
+				}
+			}
+			if pkg.Dev {
+				dep.Scope = scopeDev
+			}
+			if _, ok := declared[name]; ok && topLevel {
+				dep.Direct = true
+			}
+			deps = append(deps, dep)
+			walk(pkg.Dependencies, scopes)
+		}
+	}
+	walk(lock.Dependencies, nil)
+	return deps, nil
+}
+
+// Node resolves a requirement from the nearest node_modules folder, walking up from the dependent's install path;
+// the result is the installed package's key
+func resolveNodeModule(packages map[string]packageLockPackage, key, name string) (string, bool) {
+	for {
+		candidate := "node_modules/" + name
+		if key != "" {
+			candidate = key + "/node_modules/" + name
+		}
+		if _, ok := packages[candidate]; ok {
+			return candidate, true
+		}
+		if key == "" {
+			return "", false
+		}
+		if i := strings.LastIndex(key, "/node_modules/"); i >= 0 {
+			key = key[:i]
+		} else {
+			key = ""
+		}
+	}
+}
+
+// The package.json next to a lockfile and those of the workspace packages it lists, keyed by manifest path
+func readWorkspaceManifests(dir string) map[string]declaredDependencies {
+	manifests := map[string]declaredDependencies{
+		filepath.ToSlash(filepath.Join(dir, "package.json")): readPackageJSONDependencies(dir),
+	}
+	root, err := readNodePackageManifest(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return manifests
+	}
+	patterns := root.workspacePatterns()
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern), "package.json"))
+		for _, file := range files {
+			rel, err := filepath.Rel(dir, filepath.Dir(file))
+			if err == nil && matchesWorkspacePatterns(patterns, filepath.ToSlash(rel)) {
+				manifests[filepath.ToSlash(file)] = readPackageJSONDependencies(filepath.Dir(file))
+			}
+		}
+	}
+	return manifests
+}
+
+// Record every manifest whose declared range the lockfile entry resolves; production scope wins
+func markDeclared(dep *Dependency, manifests map[string]declaredDependencies, specs []string) {
+	for _, manifest := range sortedKeys(manifests) {
+		entry, ok := manifests[manifest].matchesSpec(dep.Name, specs)
+		if !ok {
+			continue
+		}
+		if !dep.Direct || entry.Scope == scopeProd {
+			dep.Scope = entry.Scope
+		}
+		dep.Direct = true
+		dep.DeclaredBy = append(dep.DeclaredBy, manifest)
+	}
+}
+
+// Direct when the lockfile entry resolves the exact range declared in package.json
+func (declared declaredDependencies) matchesSpec(name string, specs []string) (declaredDependency, bool) {
+	entry, ok := declared[name]
+	if !ok {
+		return entry, false
+	}
+	if entry.Constraint == "" {
+		return entry, true
+	}
+	for _, spec := range specs {
+		if spec == name+"@"+entry.Constraint || spec == name+"@npm:"+entry.Constraint {
+			return entry, true
+		}
+	}
+	return entry, false
+}
+
+func parseYarnLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifests := readWorkspaceManifests(filepath.Dir(path))
+	// Yarn 2+ (berry) lockfiles are YAML with a __metadata entry
+	if strings.Contains(string(content), "\n__metadata:") || strings.HasPrefix(string(content), "__metadata:") {
+		return parseYarnBerryLock(path, content, manifests)
+	}
+
+	var deps []Dependency
+	var specs [][]string
+	var requiredSpecs []map[string]string
+	inDependencies := false
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		switch indent := len(line) - len(strings.TrimLeft(line, " ")); {
+		case indent == 0:
+			var entrySpecs []string
+			for _, spec := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				entrySpecs = append(entrySpecs, strings.Trim(strings.TrimSpace(spec), `"`))
+			}
+			name, _ := splitPackageSpec(entrySpecs[0])
+			deps = append(deps, Dependency{Name: name, Ecosystem: ecosystemNpm, Source: path})
+			specs = append(specs, entrySpecs)
+			requiredSpecs = append(requiredSpecs, make(map[string]string))
+			inDependencies = false
+		case len(deps) == 0:
+			continue
+		case indent == 2 && strings.HasSuffix(trimmed, ":"):
+			inDependencies = trimmed == "dependencies:" || trimmed == "optionalDependencies:"
+		case indent == 2:
+			key, value, _ := strings.Cut(trimmed, " ")
+			value = strings.Trim(value, `"`)
+			switch key {
+			case "version":
+				deps[len(deps)-1].Version = value
+			case "integrity":
+				deps[len(deps)-1].Integrity = value
+			}
+		case indent >= 4 && inDependencies:
+			name, spec, _ := strings.Cut(trimmed, " ")
+			name = strings.Trim(name, `"`)
+			deps[len(deps)-1].Requires = appendUnique(deps[len(deps)-1].Requires, name)
+			requiredSpecs[len(deps)-1][name] = name + "@" + strings.Trim(spec, `"`)
+		}
+	}
+	// Every entry lists the specs it satisfies, so requirements resolve through them
+	versions := make(map[string]string)
+	for i, entrySpecs := range specs {
+		for _, spec := range entrySpecs {
+			versions[spec] = deps[i].Version
+		}
+	}
+	for i := range deps {
+		deps[i].RequiredVersions = make(map[string]string)
+		for name, spec := range requiredSpecs[i] {
+			if version, ok := versions[spec]; ok {
+				deps[i].RequiredVersions[name] = version
+			}
+		}
+	}
+	for i := range deps {
+		markDeclared(&deps[i], manifests, specs[i])
+	}
+	return deps, scanner.Err()
+}
+
+func parseYarnBerryLock(path string, content []byte, manifests map[string]declaredDependencies) ([]Dependency, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	lock := yamlMap(normalizeYAML(document))
+
+	versions := make(map[string]string)
+	for key, value := range lock {
+		for _, spec := range strings.Split(key, ",") {
+			versions[strings.TrimSpace(spec)] = yamlString(yamlMap(value)["version"])
+		}
+	}
+
+	var deps []Dependency
+	for _, key := range sortedKeys(lock) {
+		entry := yamlMap(lock[key])
+		resolution := yamlString(entry["resolution"])
+		// Workspace entries are the project's own packages
+		if key == "__metadata" || entry == nil || strings.Contains(resolution, "@workspace:") {
+			continue
+		}
+		name, _ := splitPackageSpec(resolution)
+		dep := Dependency{Name: name, Version: yamlString(entry["version"]), Ecosystem: ecosystemNpm, Source: path,
+			Integrity: yamlString(entry["checksum"]), Requires: sortedNames(yamlMap(entry["dependencies"])),
+			RequiredVersions: make(map[string]string)}
+		// Dependency ranges omit the npm: protocol that the entry keys carry
+		for required, spec := range yamlMap(entry["dependencies"]) {
+			for _, key := range []string{required + "@" + yamlString(spec), required + "@npm:" + yamlString(spec)} {
+				if version, ok := versions[key]; ok {
+					dep.RequiredVersions[required] = version
+					break
+				}
+			}
+		}
+		var specs []string
+		for _, spec := range strings.Split(key, ",") {
+			specs = append(specs, strings.TrimSpace(spec))
+		}
+		markDeclared(&dep, manifests, specs)
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// Package keys are /name/version_peer@x in v5 lockfiles, /name@version(peer@x) in v6 and
+// name@version(peer@x) in v9; scoped names keep their leading @scope/
+func pnpmPackageKey(key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	// Peer suffixes can contain / and @ (v6+), so drop them before splitting
+	if i := strings.Index(key, "("); i > 0 {
+		key = key[:i]
+	}
+	if i := strings.LastIndex(key, "/"); i > 0 && i+1 < len(key) && key[i+1] >= '0' && key[i+1] <= '9' {
+		if version := pnpmCleanVersion(key[i+1:]); !strings.Contains(version, "@") {
+			return key[:i], version
+		}
+	}
+	if name, version := splitPackageSpec(key); version != "" {
+		return name, pnpmCleanVersion(version)
+	}
+	return key, ""
+}
+
+func pnpmCleanVersion(version string) string {
+	if i := strings.IndexAny(version, "(_"); i > 0 {
+		return version[:i]
+	}
+	return version
+}
+
+func parsePnpmLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	lock := yamlMap(normalizeYAML(document))
+
+	// Workspaces (and every v9 lockfile) list each project under importers
+	importers := yamlMap(lock["importers"])
+	if importers == nil {
+		importers = map[string]interface{}{".": lock}
+	}
+	directScopes := make(map[string]string)
+	declaredBy := make(map[string][]string)
+	for _, project := range sortedKeys(importers) {
+		manifest := filepath.ToSlash(filepath.Join(filepath.Dir(path), project, "package.json"))
+		sections := yamlMap(importers[project])
+		for _, section := range []string{"dependencies", "optionalDependencies", "devDependencies"} {
+			scope := scopeProd
+			if section == "devDependencies" {
+				scope = scopeDev
+			}
+			for name, spec := range yamlMap(sections[section]) {
+				version := yamlString(spec)
+				if settings := yamlMap(spec); settings != nil {
+					version = yamlString(settings["version"])
+				}
+				key := name + "@" + pnpmCleanVersion(version)
+				if directScopes[key] != scopeProd {
+					directScopes[key] = scope
+				}
+				declaredBy[key] = appendUnique(declaredBy[key], manifest)
+			}
+		}
+	}
+
+	// v9 moves the dependency graph from packages to snapshots
+	requires := make(map[string][]string)
+	requiredVersions := make(map[string]map[string]string)
+	addRequirements := func(key string, entry map[string]interface{}) {
+		if requiredVersions[key] == nil {
+			requiredVersions[key] = make(map[string]string)
+		}
+		for _, section := range []string{"dependencies", "optionalDependencies"} {
+			for required, version := range yamlMap(entry[section]) {
+				requires[key] = appendUnique(requires[key], required)
+				// Aliased entries carry a full package key instead of a version
+				resolved := pnpmCleanVersion(yamlString(version))
+				if strings.HasPrefix(resolved, "/") || strings.Contains(resolved, "@") {
+					_, resolved = pnpmPackageKey(resolved)
+				}
+				requiredVersions[key][required] = resolved
+			}
+		}
+	}
+	for key, value := range yamlMap(lock["snapshots"]) {
+		name, version := pnpmPackageKey(key)
+		addRequirements(name+"@"+version, yamlMap(value))
+	}
+
+	var deps []Dependency
+	for _, key := range sortedKeys(yamlMap(lock["packages"])) {
+		entry := yamlMap(yamlMap(lock["packages"])[key])
+		name, version := pnpmPackageKey(key)
+		dep := Dependency{Name: name, Version: version, Ecosystem: ecosystemNpm, Source: path,
+			Integrity: yamlString(yamlMap(entry["resolution"])["integrity"])}
+		addRequirements(name+"@"+version, entry)
+		dep.Requires = requires[name+"@"+version]
+		sort.Strings(dep.Requires)
+		dep.RequiredVersions = requiredVersions[name+"@"+version]
+		if dev, ok := entry["dev"].(bool); ok {
+			dep.Scope = scopeProd
+			if dev {
+				dep.Scope = scopeDev
+			}
+		}
+		if scope, ok := directScopes[name+"@"+version]; ok {
+			dep.Direct = true
+			dep.DeclaredBy = declaredBy[name+"@"+version]
+			if dep.Scope == "" {
+				dep.Scope = scope
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
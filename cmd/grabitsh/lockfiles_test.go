@@ -0,0 +1,470 @@
+package grabitsh
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Parse a lockfile fixture the way collectDependencies does and summarize each dependency as
+// "name@version scope" with a trailing "direct" for declared ones
+func parseLockfileFixture(t *testing.T, files map[string]string, lockfile string) []string {
+	t.Helper()
+	path := filepath.Join(writeTestFiles(t, files), filepath.FromSlash(lockfile))
+	parser, ok := lockfileParsers[lockfileName(path)]
+	if !ok {
+		t.Fatalf("no parser for %s", lockfile)
+	}
+	deps, err := parser(path)
+	if err != nil {
+		t.Fatalf("%s: %v", lockfile, err)
+	}
+	propagateScopes(deps)
+	var summary []string
+	for _, dep := range deps {
+		line := fmt.Sprintf("%s@%s %s", dep.Name, dep.Version, dep.Scope)
+		if dep.Direct {
+			line += " direct"
+		}
+		summary = append(summary, line)
+	}
+	sort.Strings(summary)
+	return summary
+}
+
+const lockfileTestPackageJSON = `{"name": "app", "dependencies": {"express": "^4.18.0"}, "devDependencies": {"jest": "^29.0.0"}}`
+
+func TestParseNodeLockfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		lockfile string
+		want     []string
+	}{
+		{
+			name: "npm v1",
+			files: map[string]string{
+				"package.json": lockfileTestPackageJSON,
+				"package-lock.json": `{"lockfileVersion": 1, "dependencies": {
+  "express": {"version": "4.18.2", "requires": {"debug": "2.6.9"}, "dependencies": {"debug": {"version": "2.6.9"}}},
+  "debug": {"version": "4.3.4", "dev": true},
+  "jest": {"version": "29.7.0", "dev": true, "requires": {"debug": "^4.3.4"}}
+}}`,
+			},
+			lockfile: "package-lock.json",
+			want:     []string{"debug@2.6.9 prod", "debug@4.3.4 dev", "express@4.18.2 prod direct", "jest@29.7.0 dev direct"},
+		},
+		{
+			name: "npm v2",
+			files: map[string]string{
+				"package.json": lockfileTestPackageJSON,
+				"package-lock.json": `{"lockfileVersion": 2, "packages": {
+  "": {"name": "app", "dependencies": {"express": "^4.18.0"}, "devDependencies": {"jest": "^29.0.0"}},
+  "node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9"}},
+  "node_modules/express/node_modules/debug": {"version": "2.6.9"},
+  "node_modules/debug": {"version": "4.3.4", "dev": true},
+  "node_modules/jest": {"version": "29.7.0", "dev": true, "dependencies": {"debug": "^4.3.4"}}
+}, "dependencies": {"express": {"version": "4.18.2"}}}`,
+			},
+			lockfile: "package-lock.json",
+			want:     []string{"debug@2.6.9 prod", "debug@4.3.4 dev", "express@4.18.2 prod direct", "jest@29.7.0 dev direct"},
+		},
+		{
+			name: "npm v3 workspace",
+			files: map[string]string{
+				"package.json":            `{"name": "app", "workspaces": ["packages/*"], "devDependencies": {"jest": "^29.0.0"}}`,
+				"packages/a/package.json": `{"name": "a", "dependencies": {"lodash": "^4.17.0"}}`,
+				"package-lock.json": `{"lockfileVersion": 3, "packages": {
+  "": {"name": "app", "workspaces": ["packages/*"], "devDependencies": {"jest": "^29.0.0"}},
+  "packages/a": {"name": "a", "dependencies": {"lodash": "^4.17.0"}},
+  "node_modules/a": {"resolved": "packages/a", "link": true},
+  "node_modules/lodash": {"version": "4.17.21"},
+  "node_modules/jest": {"version": "29.7.0", "dev": true}
+}}`,
+			},
+			lockfile: "package-lock.json",
+			want:     []string{"jest@29.7.0 dev direct", "lodash@4.17.21 prod direct"},
+		},
+		{
+			name: "yarn classic",
+			files: map[string]string{
+				"package.json": lockfileTestPackageJSON,
+				"yarn.lock": `# yarn lockfile v1
+
+
+"express@^4.18.0":
+  version "4.18.2"
+  resolved "https://registry.yarnpkg.com/express/-/express-4.18.2.tgz"
+  integrity sha512-express
+  dependencies:
+    debug "2.6.9"
+
+debug@2.6.9:
+  version "2.6.9"
+
+"@jest/core@^29.7.0", "@jest/core@~29.7.0":
+  version "29.7.0"
+
+jest@^29.0.0:
+  version "29.7.0"
+  dependencies:
+    "@jest/core" "^29.7.0"
+`,
+			},
+			lockfile: "yarn.lock",
+			want:     []string{"@jest/core@29.7.0 dev", "debug@2.6.9 prod", "express@4.18.2 prod direct", "jest@29.7.0 dev direct"},
+		},
+		{
+			name: "yarn berry",
+			files: map[string]string{
+				"package.json": lockfileTestPackageJSON,
+				"yarn.lock": `__metadata:
+  version: 6
+  cacheKey: 8
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+  dependencies:
+    express: ^4.18.0
+  languageName: unknown
+  linkType: soft
+
+"debug@npm:2.6.9":
+  version: 2.6.9
+  resolution: "debug@npm:2.6.9"
+  languageName: node
+  linkType: hard
+
+"express@npm:^4.18.0":
+  version: 4.18.2
+  resolution: "express@npm:4.18.2"
+  dependencies:
+    debug: 2.6.9
+  checksum: abc
+  languageName: node
+  linkType: hard
+
+"jest@npm:^29.0.0":
+  version: 29.7.0
+  resolution: "jest@npm:29.7.0"
+  languageName: node
+  linkType: hard
+`,
+			},
+			lockfile: "yarn.lock",
+			want:     []string{"debug@2.6.9 prod", "express@4.18.2 prod direct", "jest@29.7.0 dev direct"},
+		},
+		{
+			name: "pnpm v5",
+			files: map[string]string{
+				"pnpm-lock.yaml": `lockfileVersion: 5.4
+
+specifiers:
+  react-dom: ^17.0.2
+  '@types/react': ^17.0.0
+
+dependencies:
+  react-dom: 17.0.2_react@17.0.2
+
+devDependencies:
+  '@types/react': 17.0.50
+
+packages:
+
+  /react/17.0.2:
+    resolution: {integrity: sha512-react}
+    dev: false
+
+  /react-dom/17.0.2_react@17.0.2:
+    resolution: {integrity: sha512-reactdom}
+    dependencies:
+      react: 17.0.2
+    dev: false
+
+  /@types/react/17.0.50:
+    resolution: {integrity: sha512-types}
+    dev: true
+`,
+			},
+			lockfile: "pnpm-lock.yaml",
+			want:     []string{"@types/react@17.0.50 dev direct", "react-dom@17.0.2 prod direct", "react@17.0.2 prod"},
+		},
+		{
+			name: "pnpm v6",
+			files: map[string]string{
+				"pnpm-lock.yaml": `lockfileVersion: '6.0'
+
+dependencies:
+  react-dom:
+    specifier: ^17.0.2
+    version: 17.0.2(react@17.0.2)
+
+devDependencies:
+  '@types/react':
+    specifier: ^17.0.0
+    version: 17.0.50
+
+packages:
+
+  /react@17.0.2:
+    resolution: {integrity: sha512-react}
+    dev: false
+
+  /react-dom@17.0.2(react@17.0.2):
+    resolution: {integrity: sha512-reactdom}
+    dependencies:
+      react: 17.0.2
+    dev: false
+
+  /@types/react@17.0.50:
+    resolution: {integrity: sha512-types}
+    dev: true
+`,
+			},
+			lockfile: "pnpm-lock.yaml",
+			want:     []string{"@types/react@17.0.50 dev direct", "react-dom@17.0.2 prod direct", "react@17.0.2 prod"},
+		},
+		{
+			name: "pnpm v9",
+			files: map[string]string{
+				"pnpm-lock.yaml": `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react-dom:
+        specifier: ^17.0.2
+        version: 17.0.2(react@17.0.2)
+    devDependencies:
+      '@types/react':
+        specifier: ^17.0.0
+        version: 17.0.50
+
+packages:
+
+  '@types/react@17.0.50':
+    resolution: {integrity: sha512-types}
+
+  react-dom@17.0.2:
+    resolution: {integrity: sha512-reactdom}
+
+  react@17.0.2:
+    resolution: {integrity: sha512-react}
+
+snapshots:
+
+  '@types/react@17.0.50': {}
+
+  react-dom@17.0.2(react@17.0.2):
+    dependencies:
+      react: 17.0.2
+
+  react@17.0.2: {}
+`,
+			},
+			lockfile: "pnpm-lock.yaml",
+			want:     []string{"@types/react@17.0.50 dev direct", "react-dom@17.0.2 prod direct", "react@17.0.2 prod"},
+		},
+	}
+	for _, test := range tests {
+		if got := parseLockfileFixture(t, test.files, test.lockfile); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\n got %s\nwant %s", test.name, strings.Join(got, ", "), strings.Join(test.want, ", "))
+		}
+	}
+}
+
+func TestPnpmPackageKey(t *testing.T) {
+	tests := []struct {
+		key, name, version string
+	}{
+		{"/react-dom/17.0.2_react@17.0.2", "react-dom", "17.0.2"},
+		{"/@emotion/react/11.10.0_@types+react@17.0.50", "@emotion/react", "11.10.0"},
+		{"/lodash/4.17.21", "lodash", "4.17.21"},
+		{"/react-dom@17.0.2(react@17.0.2)", "react-dom", "17.0.2"},
+		{"/@emotion/react@11.10.0(@types/react@17.0.50)(react@17.0.2)", "@emotion/react", "11.10.0"},
+		{"react-dom@17.0.2(react@17.0.2)", "react-dom", "17.0.2"},
+		{"@scope/3d-lib@1.0.0", "@scope/3d-lib", "1.0.0"},
+	}
+	for _, test := range tests {
+		if name, version := pnpmPackageKey(test.key); name != test.name || version != test.version {
+			t.Errorf("pnpmPackageKey(%q) = %q, %q, want %q, %q", test.key, name, version, test.name, test.version)
+		}
+	}
+}
+
+func TestParseOtherLockfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		lockfile string
+		want     []string
+	}{
+		{
+			name: "poetry",
+			files: map[string]string{
+				"pyproject.toml": `[tool.poetry.dependencies]
+python = "^3.11"
+Flask = "^3.0"
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^8.0"
+`,
+				"poetry.lock": `[[package]]
+name = "flask"
+version = "3.0.0"
+files = [{file = "flask-3.0.0.whl", hash = "sha256:flask"}]
+
+[package.dependencies]
+Werkzeug = ">=3.0.0"
+
+[[package]]
+name = "werkzeug"
+version = "3.0.1"
+
+[[package]]
+name = "pytest"
+version = "8.0.0"
+`,
+			},
+			lockfile: "poetry.lock",
+			want:     []string{"flask@3.0.0 prod direct", "pytest@8.0.0 dev direct", "werkzeug@3.0.1 prod"},
+		},
+		{
+			name: "Pipfile",
+			files: map[string]string{
+				"Pipfile": "[packages]\nrequests = \"*\"\n\n[dev-packages]\npytest = \"*\"\n",
+				"Pipfile.lock": `{
+  "_meta": {"hash": {"sha256": "abc"}},
+  "default": {"requests": {"version": "==2.31.0", "hashes": ["sha256:req"]}, "idna": {"version": "==3.6"}},
+  "develop": {"pytest": {"version": "==8.0.0"}}
+}`,
+			},
+			lockfile: "Pipfile.lock",
+			want:     []string{"idna@3.6 prod", "pytest@8.0.0 dev direct", "requests@2.31.0 prod direct"},
+		},
+		{
+			name: "Gemfile.lock",
+			files: map[string]string{
+				"Gemfile": "source \"https://rubygems.org\"\ngem \"rails\"\ngroup :development, :test do\n  gem \"rspec\"\nend\n",
+				"Gemfile.lock": `GEM
+  remote: https://rubygems.org/
+  specs:
+    actionpack (7.1.2)
+      rack (>= 2.2.4)
+    rack (3.0.8)
+    rails (7.1.2)
+      actionpack (= 7.1.2)
+    rspec (3.12.0)
+
+PLATFORMS
+  ruby
+
+DEPENDENCIES
+  rails
+  rspec
+
+BUNDLED WITH
+   2.5.3
+`,
+			},
+			lockfile: "Gemfile.lock",
+			want:     []string{"actionpack@7.1.2 prod", "rack@3.0.8 prod", "rails@7.1.2 prod direct", "rspec@3.12.0 dev direct"},
+		},
+		{
+			name: "Cargo.lock",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"app\"\n\n[dependencies]\nserde = \"1\"\n\n[dev-dependencies]\ninsta = \"1\"\n",
+				"Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "insta",
+ "serde",
+]
+
+[[package]]
+name = "insta"
+version = "1.34.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "insta"
+
+[[package]]
+name = "serde"
+version = "1.0.193"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "serde"
+dependencies = [
+ "serde_derive 1.0.193",
+]
+
+[[package]]
+name = "serde_derive"
+version = "1.0.193"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			},
+			lockfile: "Cargo.lock",
+			want:     []string{"insta@1.34.0 dev direct", "serde@1.0.193 prod direct", "serde_derive@1.0.193 prod"},
+		},
+		{
+			name:     "requirements-dev.txt",
+			files:    map[string]string{"requirements-dev.txt": "pytest==8.0.0\n"},
+			lockfile: "requirements-dev.txt",
+			want:     []string{"pytest@8.0.0 dev direct"},
+		},
+		{
+			name:     "requirements/test.txt",
+			files:    map[string]string{"requirements/test.txt": "pytest==8.0.0\n"},
+			lockfile: "requirements/test.txt",
+			want:     []string{"pytest@8.0.0 dev direct"},
+		},
+		{
+			name:     "devops/requirements.txt",
+			files:    map[string]string{"devops/requirements.txt": "ansible==9.1.0\n"},
+			lockfile: "devops/requirements.txt",
+			want:     []string{"ansible@9.1.0 prod direct"},
+		},
+	}
+	for _, test := range tests {
+		if got := parseLockfileFixture(t, test.files, test.lockfile); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\n got %s\nwant %s", test.name, strings.Join(got, ", "), strings.Join(test.want, ", "))
+		}
+	}
+}
+
+func TestRequirementsFileNames(t *testing.T) {
+	tests := []struct {
+		path string
+		name string
+		dev  bool
+	}{
+		{"requirements.txt", "requirements.txt", false},
+		{"requirements-dev.txt", "requirements.txt", true},
+		{"requirements_test.txt", "requirements.txt", true},
+		{"dev-requirements.txt", "requirements.txt", true},
+		{"requirements/test.txt", "requirements.txt", true},
+		{"requirements/base.txt", "requirements.txt", false},
+		{"devops/requirements.txt", "requirements.txt", false},
+		{"contest/requirements.txt", "requirements.txt", false},
+		{"requirements-contest.txt", "requirements.txt", false},
+		{"notes.txt", "notes.txt", false},
+		{"docs/test.txt", "test.txt", false},
+	}
+	for _, test := range tests {
+		if got := lockfileName(test.path); got != test.name {
+			t.Errorf("lockfileName(%q) = %q, want %q", test.path, got, test.name)
+		}
+		if test.name == "requirements.txt" {
+			if got := isDevRequirementsFile(test.path); got != test.dev {
+				t.Errorf("isDevRequirementsFile(%q) = %v, want %v", test.path, got, test.dev)
+			}
+		}
+	}
+}
@@ -15,6 +15,8 @@ type AnalysisResult struct {
 	TestingFrameworks    []string          `json:"testing_frameworks"`
 	CodeQualityTools     []string          `json:"code_quality_tools"`
 	DependencyManagement []string          `json:"dependency_management"`
+	GoImportGraph        *GoImportGraph    `json:"go_import_graph,omitempty"`
+	SubProjects          []SubProject      `json:"sub_projects,omitempty"`
 }
 
 func PerformAdvancedAnalysis(buffer *bytes.Buffer) {
@@ -22,7 +24,7 @@ func PerformAdvancedAnalysis(buffer *bytes.Buffer) {
 
 	var result AnalysisResult
 	var wg sync.WaitGroup
-	wg.Add(7)
+	wg.Add(9)
 
 	go func() {
 		defer wg.Done()
@@ -66,6 +68,16 @@ func PerformAdvancedAnalysis(buffer *bytes.Buffer) {
 		result.DependencyManagement = analyzeDependencyManagement()
 	}()
 
+	go func() {
+		defer wg.Done()
+		_, result.GoImportGraph, _ = loadGoAnalysis()
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.SubProjects = detectSubProjects()
+	}()
+
 	wg.Wait()
 
 	// Marshal the result to JSON and write to buffer
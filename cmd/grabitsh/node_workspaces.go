@@ -0,0 +1,402 @@
+package grabitsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type NodeWorkspaceDependency struct {
+	Name string `json:"name"`
+	Spec string `json:"spec"`
+	// dependencies, devDependencies, peerDependencies or optionalDependencies
+	Section string `json:"section"`
+}
+
+type NodeWorkspacePackage struct {
+	Name       string                    `json:"name"`
+	Version    string                    `json:"version,omitempty"`
+	Dir        string                    `json:"dir"`
+	Private    bool                      `json:"private,omitempty"`
+	Scripts    []string                  `json:"scripts,omitempty"`
+	Frameworks []string                  `json:"frameworks,omitempty"`
+	Internal   []NodeWorkspaceDependency `json:"internal_dependencies,omitempty"`
+}
+
+type NodeWorkspace struct {
+	// npm, Yarn, pnpm or Bun
+	Manager  string                  `json:"manager"`
+	Tools    []string                `json:"tools,omitempty"`
+	Patterns []string                `json:"patterns"`
+	Packages []*NodeWorkspacePackage `json:"packages"`
+	// Turborepo tasks and Nx target defaults
+	Tasks      []string   `json:"tasks,omitempty"`
+	Cycles     [][]string `json:"cycles,omitempty"`
+	BuildOrder [][]string `json:"build_order,omitempty"`
+}
+
+// Frameworks recognised from a package's dependencies, more specific ones first
+var nodeFrameworks = []dependencyLabel{
+	{"next", "Next.js"}, {"nuxt", "Nuxt"}, {"@remix-run/react", "Remix"}, {"gatsby", "Gatsby"}, {"astro", "Astro"},
+	{"@sveltejs/kit", "SvelteKit"}, {"svelte", "Svelte"}, {"@angular/core", "Angular"}, {"vue", "Vue.js"},
+	{"react-native", "React Native"}, {"react", "React"}, {"@nestjs/core", "NestJS"}, {"express", "Express"},
+	{"fastify", "Fastify"}, {"koa", "Koa"}, {"electron", "Electron"}, {"vite", "Vite"},
+}
+
+var nodePackageManagers = map[string]string{"npm": "npm", "yarn": "Yarn", "pnpm": "pnpm", "bun": "Bun"}
+
+// Project type names DetectProjectTypes already uses for root-level frameworks
+var nodeFrameworkProjectTypes = map[string]string{
+	"Next.js": "Next.js framework", "React": "React project", "Astro": "Astro framework", "Vite": "Vite project",
+	"Vue.js": "Vue.js project", "Angular": "Angular project",
+}
+
+type nodePackageManifest struct {
+	Name                 string                 `json:"name"`
+	Version              string                 `json:"version"`
+	Private              bool                   `json:"private"`
+	PackageManager       string                 `json:"packageManager"`
+	Workspaces           interface{}            `json:"workspaces"`
+	Scripts              map[string]interface{} `json:"scripts"`
+	Dependencies         map[string]interface{} `json:"dependencies"`
+	DevDependencies      map[string]interface{} `json:"devDependencies"`
+	PeerDependencies     map[string]interface{} `json:"peerDependencies"`
+	OptionalDependencies map[string]interface{} `json:"optionalDependencies"`
+}
+
+func readNodePackageManifest(file string) (nodePackageManifest, error) {
+	var manifest nodePackageManifest
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return manifest, err
+	}
+	return manifest, json.Unmarshal(content, &manifest)
+}
+
+// The "workspaces" field is a list of globs, or {packages: [...]} in Yarn classic
+func (manifest nodePackageManifest) workspacePatterns() []string {
+	if settings := yamlMap(manifest.Workspaces); settings != nil {
+		return yamlKeysOrItems(settings["packages"])
+	}
+	return yamlKeysOrItems(manifest.Workspaces)
+}
+
+// Detect npm, Yarn, pnpm or Bun workspaces and the Turborepo, Nx and Lerna tooling around them; nil outside a monorepo
+func loadNodeWorkspace() (*NodeWorkspace, error) {
+	workspace := &NodeWorkspace{Manager: "npm"}
+	root, _ := readNodePackageManifest("package.json")
+
+	workspace.Patterns = root.workspacePatterns()
+	switch {
+	case fileExists("yarn.lock"):
+		workspace.Manager = "Yarn"
+	case fileExists("pnpm-lock.yaml"):
+		workspace.Manager = "pnpm"
+	case fileExists("bun.lockb") || fileExists("bun.lock"):
+		workspace.Manager = "Bun"
+	}
+	if fileExists("pnpm-workspace.yaml") {
+		workspace.Manager = "pnpm"
+		var config struct {
+			Packages []string `yaml:"packages"`
+		}
+		content, err := os.ReadFile("pnpm-workspace.yaml")
+		if err == nil {
+			err = yaml.Unmarshal(content, &config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing pnpm-workspace.yaml: %w", err)
+		}
+		workspace.Patterns = append(workspace.Patterns, config.Packages...)
+	}
+
+	if fileExists("lerna.json") {
+		var lerna struct {
+			Version   string   `json:"version"`
+			NpmClient string   `json:"npmClient"`
+			Packages  []string `json:"packages"`
+		}
+		content, err := os.ReadFile("lerna.json")
+		if err == nil {
+			err = json.Unmarshal(content, &lerna)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing lerna.json: %w", err)
+		}
+		mode := "fixed version " + lerna.Version
+		if lerna.Version == "independent" {
+			mode = "independent versioning"
+		}
+		workspace.Tools = append(workspace.Tools, "Lerna ("+mode+")")
+		if len(lerna.Packages) == 0 && len(workspace.Patterns) == 0 {
+			lerna.Packages = []string{"packages/*"}
+		}
+		workspace.Patterns = append(workspace.Patterns, lerna.Packages...)
+		if manager, ok := nodePackageManagers[lerna.NpmClient]; ok && !fileExists("pnpm-workspace.yaml") {
+			workspace.Manager = manager
+		}
+	}
+	// The packageManager field pins the manager through Corepack, e.g. "pnpm@9.1.0"
+	if manager, ok := nodePackageManagers[strings.SplitN(root.PackageManager, "@", 2)[0]]; ok {
+		workspace.Manager = manager
+	}
+	for _, file := range []string{"turbo.json", "nx.json"} {
+		if !fileExists(file) {
+			continue
+		}
+		var config map[string]interface{}
+		content, err := os.ReadFile(file)
+		if err == nil {
+			err = json.Unmarshal(content, &config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", file, err)
+		}
+		if file == "turbo.json" {
+			workspace.Tools = append(workspace.Tools, "Turborepo")
+			// Turborepo 2 renamed pipeline to tasks
+			tasks := yamlMap(config["tasks"])
+			if tasks == nil {
+				tasks = yamlMap(config["pipeline"])
+			}
+			for _, task := range sortedKeys(tasks) {
+				workspace.Tasks = appendUnique(workspace.Tasks, "turbo "+task)
+			}
+		} else {
+			workspace.Tools = append(workspace.Tools, "Nx")
+			for _, target := range sortedKeys(yamlMap(config["targetDefaults"])) {
+				workspace.Tasks = appendUnique(workspace.Tasks, "nx "+target)
+			}
+		}
+	}
+
+	if len(workspace.Patterns) == 0 && !fileExists("nx.json") {
+		return nil, nil
+	}
+	var patterns []string
+	for _, pattern := range workspace.Patterns {
+		patterns = appendUnique(patterns, pattern)
+	}
+	workspace.Patterns = patterns
+
+	// Nx projects are defined by project.json files, with or without a package.json. Other tools use
+	// project.json too, so outside the workspace globs it only counts when nx.json is present.
+	manifests := findRepositoryFiles(func(name string) bool { return name == "package.json" || name == "project.json" })
+	nx := fileExists("nx.json")
+
+	packages := make(map[string]*NodeWorkspacePackage)
+	for _, file := range manifests {
+		dir := path.Dir(file)
+		if dir == "." || !(matchesWorkspacePatterns(workspace.Patterns, dir) || (nx && path.Base(file) == "project.json")) {
+			continue
+		}
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &NodeWorkspacePackage{Dir: dir}
+			packages[dir] = pkg
+		}
+		if path.Base(file) == "project.json" {
+			var project struct {
+				Name    string                 `json:"name"`
+				Targets map[string]interface{} `json:"targets"`
+			}
+			if content, err := os.ReadFile(file); err == nil && json.Unmarshal(content, &project) == nil {
+				if pkg.Name == "" {
+					pkg.Name = project.Name
+				}
+				for _, target := range sortedKeys(project.Targets) {
+					pkg.Scripts = appendUnique(pkg.Scripts, target)
+				}
+			}
+			continue
+		}
+
+		manifest, err := readNodePackageManifest(file)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", file, err)
+		}
+		if manifest.Name != "" {
+			pkg.Name = manifest.Name
+		}
+		pkg.Version, pkg.Private = manifest.Version, manifest.Private
+		for _, script := range sortedKeys(manifest.Scripts) {
+			pkg.Scripts = appendUnique(pkg.Scripts, script)
+		}
+		for _, framework := range nodeFrameworks {
+			if manifest.Dependencies[framework.dependency] != nil || manifest.DevDependencies[framework.dependency] != nil {
+				pkg.Frameworks = append(pkg.Frameworks, framework.name)
+			}
+		}
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+	for _, dir := range sortedKeys(packages) {
+		if packages[dir].Name == "" {
+			packages[dir].Name = path.Base(dir)
+		}
+		workspace.Packages = append(workspace.Packages, packages[dir])
+	}
+
+	workspace.resolveInternalDependencies()
+	return workspace, nil
+}
+
+// Workspace globs select package directories exactly; "!" patterns exclude
+func matchesWorkspacePatterns(patterns []string, dir string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./"), "/")
+		if !strings.Contains(pattern, "/") {
+			pattern = "/" + pattern
+		}
+		if globToRegexp(pattern).FindString(dir) == dir {
+			matched = !negated
+		}
+	}
+	return matched
+}
+
+func (workspace *NodeWorkspace) resolveInternalDependencies() {
+	byName := make(map[string]*NodeWorkspacePackage)
+	for _, pkg := range workspace.Packages {
+		byName[pkg.Name] = pkg
+	}
+
+	edges := make(map[string][]string)
+	for _, pkg := range workspace.Packages {
+		manifest, err := readNodePackageManifest(filepath.Join(pkg.Dir, "package.json"))
+		if err != nil {
+			continue
+		}
+		sections := []struct {
+			name  string
+			specs map[string]interface{}
+		}{
+			{"dependencies", manifest.Dependencies}, {"devDependencies", manifest.DevDependencies},
+			{"peerDependencies", manifest.PeerDependencies}, {"optionalDependencies", manifest.OptionalDependencies},
+		}
+		for _, section := range sections {
+			for _, name := range sortedKeys(section.specs) {
+				if _, ok := byName[name]; !ok || name == pkg.Name {
+					continue
+				}
+				pkg.Internal = append(pkg.Internal, NodeWorkspaceDependency{Name: name, Spec: yamlString(section.specs[name]), Section: section.name})
+				// Peer dependencies are satisfied by the consumer and do not order builds
+				if section.name != "peerDependencies" {
+					edges[pkg.Name] = appendUnique(edges[pkg.Name], name)
+				}
+			}
+		}
+	}
+
+	for _, component := range stronglyConnectedComponents(edges) {
+		workspace.Cycles = append(workspace.Cycles, findCycle(edges, component))
+	}
+
+	// Build layers: every package comes after the workspace packages it depends on
+	remaining := make(map[string]int)
+	dependents := make(map[string][]string)
+	for _, pkg := range workspace.Packages {
+		remaining[pkg.Name] = len(edges[pkg.Name])
+		for _, dependency := range edges[pkg.Name] {
+			dependents[dependency] = append(dependents[dependency], pkg.Name)
+		}
+	}
+	var layer []string
+	for _, name := range sortedKeys(remaining) {
+		if remaining[name] == 0 {
+			layer = append(layer, name)
+		}
+	}
+	for len(layer) > 0 {
+		workspace.BuildOrder = append(workspace.BuildOrder, layer)
+		var next []string
+		for _, name := range layer {
+			for _, dependent := range dependents[name] {
+				if remaining[dependent]--; remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		sort.Strings(next)
+		layer = next
+	}
+}
+
+// The workspace packages as dependencies, so depgraph can render their graph
+func (workspace *NodeWorkspace) dependencies() []Dependency {
+	var deps []Dependency
+	for _, pkg := range workspace.Packages {
+		dep := Dependency{Name: pkg.Name, Version: pkg.Version, Ecosystem: ecosystemNpm, Source: "workspace", Direct: true, Scope: scopeProd}
+		for _, internal := range pkg.Internal {
+			if internal.Section != "peerDependencies" {
+				dep.Requires = appendUnique(dep.Requires, internal.Name)
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+func (workspace *NodeWorkspace) describe() string {
+	return strings.Join(append([]string{workspace.Manager + " workspaces"}, workspace.Tools...), ", ")
+}
+
+func writeNodeWorkspace(output io.StringWriter, workspace *NodeWorkspace) {
+	output.WriteString(fmt.Sprintf("Monorepo: %s, %d packages\n", workspace.describe(), len(workspace.Packages)))
+	if len(workspace.Patterns) > 0 {
+		output.WriteString(fmt.Sprintf("Workspace patterns: %s\n", strings.Join(workspace.Patterns, ", ")))
+	}
+	if len(workspace.Tasks) > 0 {
+		output.WriteString(fmt.Sprintf("Pipeline tasks: %s\n", strings.Join(workspace.Tasks, ", ")))
+	}
+
+	output.WriteString("Packages:\n")
+	for _, pkg := range workspace.Packages {
+		line := fmt.Sprintf("- %s", pkg.Name)
+		if pkg.Version != "" {
+			line += "@" + pkg.Version
+		}
+		line += " (" + pkg.Dir
+		if pkg.Private {
+			line += ", private"
+		}
+		output.WriteString(line + ")\n")
+		if len(pkg.Frameworks) > 0 {
+			output.WriteString(fmt.Sprintf("    frameworks: %s\n", strings.Join(pkg.Frameworks, ", ")))
+		}
+		if len(pkg.Scripts) > 0 {
+			output.WriteString(fmt.Sprintf("    scripts: %s\n", strings.Join(pkg.Scripts, ", ")))
+		}
+		if len(pkg.Internal) > 0 {
+			var internal []string
+			for _, dependency := range pkg.Internal {
+				entry := dependency.Name + " " + dependency.Spec
+				if dependency.Section != "dependencies" {
+					entry += " (" + dependency.Section + ")"
+				}
+				internal = append(internal, entry)
+			}
+			output.WriteString(fmt.Sprintf("    workspace dependencies: %s\n", strings.Join(internal, ", ")))
+		}
+	}
+
+	for _, cycle := range workspace.Cycles {
+		output.WriteString(fmt.Sprintf("Workspace dependency cycle: %s\n", strings.Join(cycle, " -> ")))
+	}
+	if len(workspace.BuildOrder) > 1 {
+		output.WriteString("Build order:\n")
+		for i, layer := range workspace.BuildOrder {
+			output.WriteString(fmt.Sprintf("  %d. %s\n", i+1, strings.Join(layer, ", ")))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package grabitsh
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func nodeWorkspaceDirs(t *testing.T, files map[string]string) []string {
+	t.Helper()
+	chdirTestFiles(t, files)
+	workspace, err := loadNodeWorkspace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workspace == nil {
+		return nil
+	}
+	var dirs []string
+	for _, pkg := range workspace.Packages {
+		dirs = append(dirs, pkg.Dir)
+	}
+	return dirs
+}
+
+func TestLoadNodeWorkspaceNxProjects(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "project.json without nx.json outside the globs",
+			files: map[string]string{
+				"package.json":               `{"workspaces": ["packages/*"]}`,
+				"packages/a/package.json":    `{"name": "a"}`,
+				"tools/schemas/project.json": `{"$schema": "https://json.schemastore.org/tsconfig"}`,
+			},
+			want: []string{"packages/a"},
+		},
+		{
+			name: "project.json inside the globs",
+			files: map[string]string{
+				"package.json":            `{"workspaces": ["packages/*"]}`,
+				"packages/a/project.json": `{"name": "a", "targets": {"build": {}}}`,
+			},
+			want: []string{"packages/a"},
+		},
+		{
+			name: "Nx projects anywhere with nx.json",
+			files: map[string]string{
+				"nx.json":               `{"targetDefaults": {"build": {}}}`,
+				"package.json":          `{"name": "root"}`,
+				"apps/web/project.json": `{"name": "web", "targets": {"serve": {}}}`,
+				"libs/ui/project.json":  `{"name": "ui"}`,
+			},
+			want: []string{"apps/web", "libs/ui"},
+		},
+		{
+			name: "no workspace",
+			files: map[string]string{
+				"package.json":        `{"name": "app"}`,
+				"config/project.json": `{"name": "not nx"}`,
+			},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := nodeWorkspaceDirs(t, test.files); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("packages = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWorkspacePatternsShared(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifest  string
+		patterns  []string
+		manifests []string
+	}{
+		{"list", `{"workspaces": ["packages/*", "apps/*"]}`, []string{"packages/*", "apps/*"},
+			[]string{"apps/web/package.json", "package.json", "packages/a/package.json"}},
+		{"yarn classic object", `{"workspaces": {"packages": ["packages/*"], "nohoist": ["**/react"]}}`, []string{"packages/*"},
+			[]string{"package.json", "packages/a/package.json"}},
+		{"none", `{"name": "app"}`, nil, []string{"package.json"}},
+	}
+	for _, test := range tests {
+		dir := writeTestFiles(t, map[string]string{
+			"package.json":            test.manifest,
+			"packages/a/package.json": `{"name": "a", "dependencies": {"lodash": "^4.17.0"}}`,
+			"apps/web/package.json":   `{"name": "web"}`,
+		})
+		manifest, err := readNodePackageManifest(filepath.Join(dir, "package.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := manifest.workspacePatterns(); !reflect.DeepEqual(got, test.patterns) {
+			t.Errorf("%s: workspacePatterns() = %v, want %v", test.name, got, test.patterns)
+		}
+
+		// The lockfile parsers attribute dependencies to the same workspace packages
+		var manifests []string
+		for file := range readWorkspaceManifests(dir) {
+			rel, _ := filepath.Rel(dir, file)
+			manifests = append(manifests, filepath.ToSlash(rel))
+		}
+		sort.Strings(manifests)
+		if !reflect.DeepEqual(manifests, test.manifests) {
+			t.Errorf("%s: readWorkspaceManifests() = %v, want %v", test.name, manifests, test.manifests)
+		}
+	}
+}
@@ -0,0 +1,545 @@
+package grabitsh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outdatedFormat     string
+	outdatedDirectOnly bool
+)
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Compare pinned dependency versions against npm, Go proxy, PyPI and crates registries or local mirrors",
+	Run:   runOutdated,
+}
+
+func init() {
+	outdatedCmd.Flags().StringVar(&outdatedFormat, "format", "text", "Report format: text or json")
+	outdatedCmd.Flags().BoolVar(&outdatedDirectOnly, "direct", false, "Only check direct dependencies")
+}
+
+// Public endpoints used for ecosystems without a configured registry
+var defaultRegistries = map[string]string{
+	ecosystemNpm:    "https://registry.npmjs.org",
+	ecosystemGo:     "https://proxy.golang.org",
+	ecosystemPyPI:   "https://pypi.org/simple",
+	ecosystemCrates: "https://index.crates.io",
+}
+
+const (
+	defaultRegistryTimeout = 10 * time.Second
+	registryWorkers        = 8
+)
+
+var errRegistryNotFound = errors.New("package not found in registry")
+
+type OutdatedDependency struct {
+	Dependency Dependency `json:"dependency"`
+	Latest     string     `json:"latest,omitempty"`
+	// major, minor, patch or current
+	Lag string `json:"lag,omitempty"`
+	// Newer stable releases than the pinned one
+	Behind         int        `json:"behind"`
+	PinnedReleased *time.Time `json:"pinned_released,omitempty"`
+	LatestReleased *time.Time `json:"latest_released,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+type registryReleases struct {
+	Versions []string
+	Latest   string
+	Released map[string]time.Time
+}
+
+type registryClient struct {
+	endpoints map[string]string
+	http      *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*registryLookup
+}
+
+type registryLookup struct {
+	once     sync.Once
+	releases registryReleases
+	err      error
+}
+
+// Configured registries override the public defaults
+func newRegistryClient(config RegistriesConfig) *registryClient {
+	client := &registryClient{endpoints: make(map[string]string), cache: make(map[string]*registryLookup)}
+	for ecosystem, endpoint := range defaultRegistries {
+		client.endpoints[ecosystem] = endpoint
+	}
+	// A Go proxy from the environment is usually the mirror the build uses
+	if proxy := strings.Split(os.Getenv("GOPROXY"), ",")[0]; strings.HasPrefix(proxy, "http") || strings.HasPrefix(proxy, "file://") {
+		client.endpoints[ecosystemGo] = proxy
+	}
+	for ecosystem, endpoint := range map[string]string{ecosystemNpm: config.Npm, ecosystemGo: config.Go, ecosystemPyPI: config.PyPI, ecosystemCrates: config.Crates} {
+		if endpoint != "" {
+			client.endpoints[ecosystem] = endpoint
+		}
+	}
+	timeout := defaultRegistryTimeout
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+	client.http = &http.Client{Timeout: timeout}
+	return client
+}
+
+func runOutdated(cmd *cobra.Command, args []string) {
+	config, err := loadGrabitConfig()
+	if err != nil {
+		color.Red("Error loading configuration: %v", err)
+		return
+	}
+	dependencies, errs := collectDependencies()
+	for _, err := range errs {
+		color.Yellow("Skipping lockfile: %v", err)
+	}
+
+	results := checkOutdatedDependencies(newRegistryClient(config.Registries), dependencies)
+	var output strings.Builder
+	switch outdatedFormat {
+	case "text":
+		writeOutdatedReport(&output, results)
+	case "json":
+		content, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			color.Red("Failed to render outdated report: %v", err)
+			return
+		}
+		output.Write(content)
+		output.WriteString("\n")
+	default:
+		color.Red("Invalid format. Choose text or json.")
+		return
+	}
+	finalizeOutput(output.String())
+}
+
+// Look up every dependency with a registry endpoint, each package only once
+func checkOutdatedDependencies(client *registryClient, dependencies []Dependency) []OutdatedDependency {
+	var pending []Dependency
+	seen := make(map[string]bool)
+	for _, dep := range dependencies {
+		key := dep.Ecosystem + "\x00" + dep.Name + "@" + dep.Version
+		if client.endpoints[dep.Ecosystem] == "" || dep.Local != "" || seen[key] || (outdatedDirectOnly && !dep.Direct) {
+			continue
+		}
+		seen[key] = true
+		pending = append(pending, dep)
+	}
+
+	results := make([]OutdatedDependency, len(pending))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < registryWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = client.check(pending[i])
+			}
+		}()
+	}
+	for i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	lagOrder := map[string]int{"major": 0, "minor": 1, "patch": 2, "current": 3, "": 4}
+	sort.SliceStable(results, func(i, j int) bool {
+		if lagOrder[results[i].Lag] != lagOrder[results[j].Lag] {
+			return lagOrder[results[i].Lag] < lagOrder[results[j].Lag]
+		}
+		return results[i].Dependency.Name < results[j].Dependency.Name
+	})
+	return results
+}
+
+func (client *registryClient) check(dep Dependency) OutdatedDependency {
+	result := OutdatedDependency{Dependency: dep}
+	releases, err := client.releases(dep.Ecosystem, dep.Name)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// Pre-releases only count when the pinned version is one itself
+	_, pinnedPre := splitPrerelease(normalizeVersion(dep.Version))
+	var stable []string
+	for _, version := range releases.Versions {
+		if _, pre := splitPrerelease(normalizeVersion(version)); pre == "" || pinnedPre != "" {
+			stable = append(stable, version)
+		}
+	}
+	// The registry's latest tag wins over the highest version, which may be a backport or an untagged major
+	latest := releases.Latest
+	if latest == "" {
+		for _, version := range stable {
+			if latest == "" || compareVersions(version, latest) > 0 {
+				latest = version
+			}
+		}
+	}
+	if latest == "" {
+		result.Error = "no releases found"
+		return result
+	}
+	for _, version := range stable {
+		if compareVersions(version, dep.Version) > 0 && compareVersions(version, latest) <= 0 {
+			result.Behind++
+		}
+	}
+
+	result.Latest = latest
+	result.Lag = versionLag(dep.Version, latest)
+	released := func(version string) *time.Time {
+		if at, ok := releases.Released[version]; ok {
+			return &at
+		}
+		// The Go proxy serves release times per version rather than with the version list
+		if dep.Ecosystem == ecosystemGo {
+			if _, at, ok := client.goVersionInfo(client.endpoints[ecosystemGo], dep.Name, goModuleCachePath(version)+".info"); ok {
+				return &at
+			}
+		}
+		return nil
+	}
+	result.PinnedReleased = released(dep.Version)
+	result.LatestReleased = released(latest)
+	return result
+}
+
+func versionLag(pinned, latest string) string {
+	if compareVersions(latest, pinned) <= 0 {
+		return "current"
+	}
+	pinnedMajor, pinnedMinor, pinnedPatch := versionCore(pinned)
+	latestMajor, latestMinor, latestPatch := versionCore(latest)
+	switch {
+	case latestMajor != pinnedMajor:
+		return "major"
+	case latestMinor != pinnedMinor:
+		return "minor"
+	case latestPatch != pinnedPatch:
+		return "patch"
+	}
+	// Only the pre- or post-release part differs
+	return "patch"
+}
+
+func (client *registryClient) releases(ecosystem, name string) (registryReleases, error) {
+	client.mu.Lock()
+	lookup, ok := client.cache[ecosystem+"\x00"+name]
+	if !ok {
+		lookup = &registryLookup{}
+		client.cache[ecosystem+"\x00"+name] = lookup
+	}
+	client.mu.Unlock()
+
+	lookup.once.Do(func() {
+		endpoint := client.endpoints[ecosystem]
+		switch ecosystem {
+		case ecosystemNpm:
+			lookup.releases, lookup.err = client.npmReleases(endpoint, name)
+		case ecosystemGo:
+			lookup.releases, lookup.err = client.goProxyReleases(endpoint, name)
+		case ecosystemPyPI:
+			lookup.releases, lookup.err = client.pypiReleases(endpoint, name)
+		case ecosystemCrates:
+			lookup.releases, lookup.err = client.cratesReleases(endpoint, name)
+		default:
+			lookup.err = fmt.Errorf("no registry support for %s", ecosystem)
+		}
+	})
+	return lookup.releases, lookup.err
+}
+
+func isHTTPEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://")
+}
+
+// Fetch a registry document over HTTP, or from a file:// URL or directory mirroring the registry layout.
+// In a file mirror a directory stands for the document of the same name, read from its index file.
+func (client *registryClient) fetch(endpoint, documentPath, accept string) ([]byte, error) {
+	if isHTTPEndpoint(endpoint) {
+		request, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/"+documentPath, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		response, err := client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+		switch {
+		case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+			return nil, errRegistryNotFound
+		case response.StatusCode != http.StatusOK:
+			return nil, fmt.Errorf("%s returned %s", request.URL.Redacted(), response.Status)
+		}
+		return io.ReadAll(io.LimitReader(response.Body, 64<<20))
+	}
+
+	// Package names come from the scanned lockfiles and must not walk out of the mirror
+	root := filepath.Clean(strings.TrimPrefix(endpoint, "file://"))
+	file := filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(documentPath, "/")))
+	if !isInsideMirror(root, documentPath, file) {
+		return nil, fmt.Errorf("refusing to read %q outside the mirror %s", documentPath, root)
+	}
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		found := false
+		for _, index := range []string{"index.json", "package.json", "index.html"} {
+			if fileExists(filepath.Join(file, index)) {
+				file, found = filepath.Join(file, index), true
+				break
+			}
+		}
+		if !found {
+			return nil, errRegistryNotFound
+		}
+	}
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, errRegistryNotFound
+	}
+	return content, err
+}
+
+func isInsideMirror(root, documentPath, file string) bool {
+	for _, segment := range strings.Split(documentPath, "/") {
+		if segment == ".." || strings.Contains(segment, "\\") {
+			return false
+		}
+	}
+	relative, err := filepath.Rel(root, file)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// npm packuments list every version and its publish time
+func (client *registryClient) npmReleases(endpoint, name string) (registryReleases, error) {
+	documentPath := name
+	if isHTTPEndpoint(endpoint) {
+		documentPath = url.PathEscape(name)
+	}
+	content, err := client.fetch(endpoint, documentPath, "application/json")
+	if err != nil {
+		return registryReleases{}, err
+	}
+	var packument struct {
+		DistTags map[string]string      `json:"dist-tags"`
+		Versions map[string]interface{} `json:"versions"`
+		Time     map[string]string      `json:"time"`
+	}
+	if err := json.Unmarshal(content, &packument); err != nil {
+		return registryReleases{}, err
+	}
+
+	releases := registryReleases{Latest: packument.DistTags["latest"], Released: make(map[string]time.Time)}
+	for version := range packument.Versions {
+		releases.Versions = append(releases.Versions, version)
+	}
+	for version, published := range packument.Time {
+		if released, err := time.Parse(time.RFC3339, published); err == nil {
+			releases.Released[version] = released
+		}
+	}
+	return releases, nil
+}
+
+// GOPROXY protocol: @v/list for versions and @v/<version>.info for release times
+func (client *registryClient) goProxyReleases(endpoint, module string) (registryReleases, error) {
+	content, err := client.fetch(endpoint, goModuleCachePath(module)+"/@v/list", "")
+	if err != nil {
+		return registryReleases{}, err
+	}
+	releases := registryReleases{Versions: strings.Fields(string(content)), Released: make(map[string]time.Time)}
+	// Modules with only pseudo-versions have an empty list
+	if len(releases.Versions) == 0 {
+		if version, released, ok := client.goVersionInfo(endpoint, module, "@latest"); ok {
+			releases.Versions = []string{version}
+			releases.Released[version] = released
+		}
+	}
+	return releases, nil
+}
+
+func (client *registryClient) goVersionInfo(endpoint, module, document string) (string, time.Time, bool) {
+	content, err := client.fetch(endpoint, goModuleCachePath(module)+"/@v/"+document, "")
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	var info struct {
+		Version string
+		Time    time.Time
+	}
+	if json.Unmarshal(content, &info) != nil || info.Time.IsZero() {
+		return "", time.Time{}, false
+	}
+	return info.Version, info.Time, true
+}
+
+var (
+	simpleIndexLinkRegex   = regexp.MustCompile(`(?i)<a\s[^>]*>([^<]+)</a>`)
+	pythonArchiveExtension = regexp.MustCompile(`(\.tar\.gz|\.tar\.bz2|\.tgz|\.zip|\.whl|\.egg)$`)
+)
+
+// Version from a distribution file name: name-1.0.tar.gz (sdist) or name-1.0-py3-none-any.whl (wheel)
+func pythonFileVersion(filename string) string {
+	base := pythonArchiveExtension.ReplaceAllString(filename, "")
+	if base == filename {
+		return ""
+	}
+	if strings.HasSuffix(filename, ".whl") || strings.HasSuffix(filename, ".egg") {
+		parts := strings.Split(base, "-")
+		if len(parts) < 2 {
+			return ""
+		}
+		return parts[1]
+	}
+	if i := strings.LastIndex(base, "-"); i > 0 {
+		return base[i+1:]
+	}
+	return ""
+}
+
+// PyPI simple index, JSON (PEP 691/700) when the index offers it and HTML (PEP 503) otherwise
+func (client *registryClient) pypiReleases(endpoint, name string) (registryReleases, error) {
+	content, err := client.fetch(endpoint, normalizePythonName(name)+"/", "application/vnd.pypi.simple.v1+json, text/html;q=0.1")
+	if err != nil {
+		return registryReleases{}, err
+	}
+
+	releases := registryReleases{Released: make(map[string]time.Time)}
+	var index struct {
+		Versions []string `json:"versions"`
+		Files    []struct {
+			Filename   string      `json:"filename"`
+			UploadTime string      `json:"upload-time"`
+			Yanked     interface{} `json:"yanked"`
+		} `json:"files"`
+	}
+	if json.Unmarshal(content, &index) == nil {
+		releases.Versions = index.Versions
+		for _, file := range index.Files {
+			version := pythonFileVersion(file.Filename)
+			if version == "" {
+				continue
+			}
+			if len(index.Versions) == 0 {
+				releases.Versions = appendUnique(releases.Versions, version)
+			}
+			// A release dates from its first uploaded file
+			if uploaded, err := time.Parse(time.RFC3339, file.UploadTime); err == nil {
+				if earliest, ok := releases.Released[version]; !ok || uploaded.Before(earliest) {
+					releases.Released[version] = uploaded
+				}
+			}
+		}
+		return releases, nil
+	}
+
+	for _, match := range simpleIndexLinkRegex.FindAllStringSubmatch(string(content), -1) {
+		if version := pythonFileVersion(strings.TrimSpace(match[1])); version != "" {
+			releases.Versions = appendUnique(releases.Versions, version)
+		}
+	}
+	return releases, nil
+}
+
+// crates.io index files (sparse HTTP or a git checkout) hold one JSON line per published version
+func (client *registryClient) cratesReleases(endpoint, name string) (registryReleases, error) {
+	lower := strings.ToLower(name)
+	var documentPath string
+	switch len(lower) {
+	case 1:
+		documentPath = "1/" + lower
+	case 2:
+		documentPath = "2/" + lower
+	case 3:
+		documentPath = "3/" + lower[:1] + "/" + lower
+	default:
+		documentPath = lower[:2] + "/" + lower[2:4] + "/" + lower
+	}
+	content, err := client.fetch(endpoint, documentPath, "")
+	if err != nil {
+		return registryReleases{}, err
+	}
+
+	var releases registryReleases
+	for _, line := range strings.Split(string(content), "\n") {
+		var entry struct {
+			Version string `json:"vers"`
+			Yanked  bool   `json:"yanked"`
+		}
+		if json.Unmarshal([]byte(line), &entry) == nil && entry.Version != "" && !entry.Yanked {
+			releases.Versions = append(releases.Versions, entry.Version)
+		}
+	}
+	return releases, nil
+}
+
+func formatAge(since time.Time) string {
+	days := int(time.Since(since).Hours() / 24)
+	switch {
+	case days < 60:
+		return fmt.Sprintf("%dd", days)
+	case days < 730:
+		return fmt.Sprintf("%dmo", days/30)
+	}
+	return fmt.Sprintf("%.1fy", float64(days)/365)
+}
+
+func writeOutdatedReport(output io.StringWriter, results []OutdatedDependency) {
+	output.WriteString("Outdated dependencies (registry comparison):\n")
+	if len(results) == 0 {
+		output.WriteString("  No dependencies with a configured registry.\n")
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		dep := result.Dependency
+		if result.Error != "" {
+			counts["error"]++
+			output.WriteString(fmt.Sprintf("  Error checking %s %s@%s: %s\n", dep.Ecosystem, dep.Name, dep.Version, result.Error))
+			continue
+		}
+		counts[result.Lag]++
+		if result.Lag == "current" {
+			continue
+		}
+		age := "release date unknown"
+		if result.PinnedReleased != nil {
+			age = "pinned release " + formatAge(*result.PinnedReleased) + " old"
+		}
+		output.WriteString(fmt.Sprintf("  [%s] %s %s %s -> %s, %d newer releases, %s (%s)\n", result.Lag, dep.Ecosystem, dep.Name,
+			dep.Version, result.Latest, result.Behind, age, dep.Source))
+	}
+	output.WriteString(fmt.Sprintf("  %d checked: %d major, %d minor, %d patch behind, %d current, %d errors\n", len(results),
+		counts["major"], counts["minor"], counts["patch"], counts["current"], counts["error"]))
+}
@@ -0,0 +1,38 @@
+package grabitsh
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryFetchStaysInsideFileMirror(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"secret":                       "outside",
+		"mirror/left-pad":              `{"name": "left-pad"}`,
+		"mirror/@scope/pkg/index.json": `{"name": "@scope/pkg"}`,
+	})
+	endpoint := "file://" + filepath.Join(dir, "mirror")
+	client := &registryClient{}
+
+	tests := []struct {
+		documentPath string
+		want         string
+		wantErr      bool
+	}{
+		{"left-pad", `{"name": "left-pad"}`, false},
+		{"@scope/pkg", `{"name": "@scope/pkg"}`, false},
+		{"../secret", "", true},
+		{"../../etc/passwd", "", true},
+		{"a/../../secret", "", true},
+		{`..\secret`, "", true},
+	}
+	for _, test := range tests {
+		content, err := client.fetch(endpoint, test.documentPath, "")
+		if (err != nil) != test.wantErr || string(content) != test.want {
+			t.Errorf("fetch(%q) = %q, %v, want %q, error %v", test.documentPath, content, err, test.want, test.wantErr)
+		}
+	}
+	if _, err := client.fetch(endpoint, "missing", ""); err != errRegistryNotFound {
+		t.Errorf("fetch(missing) error = %v, want errRegistryNotFound", err)
+	}
+}
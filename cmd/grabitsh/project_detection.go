@@ -2,7 +2,6 @@ package grabitsh
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -30,6 +29,20 @@ func DetectProjectTypes() []string {
 		}
 	}
 
+	// Frameworks used by workspace packages count as project types of the monorepo
+	if workspace, _ := loadNodeWorkspace(); workspace != nil {
+		projectTypes = append(projectTypes, fmt.Sprintf("Node.js monorepo (%s)", workspace.describe()))
+		for _, pkg := range workspace.Packages {
+			for _, framework := range pkg.Frameworks {
+				projectType, ok := nodeFrameworkProjectTypes[framework]
+				if !ok {
+					projectType = framework + " project"
+				}
+				projectTypes = appendUnique(projectTypes, projectType)
+			}
+		}
+	}
+
 	// Ruby on Rails detection
 	if fileExists("config/application.rb") && dirExists("app") && dirExists("config") {
 		projectTypes = append(projectTypes, "Ruby on Rails project")
@@ -135,6 +148,7 @@ func AnalyzeRepository() string {
 	analyzeImportantDirs(&output)
 	analyzeImportantFiles(&output)
 	analyzeGoProject(&output)
+	analyzeNodeWorkspace(&output)
 	analyzeDependencies(&output)
 	analyzeConfiguration(&output)
 	analyzeDocumentation(&output)
@@ -260,24 +274,11 @@ func analyzeImportantDirs(output *strings.Builder) {
 			analyzeDirectory(dir, output, 0, 1)
 		}
 	}
-
-	if dirExists("terraform") {
-		output.WriteString("\n### Terraform Files ###\n")
-		tfFiles, _ := filepath.Glob("terraform/*.tf")
-		for _, file := range tfFiles {
-			content, err := os.ReadFile(file)
-			if err == nil {
-				output.WriteString(fmt.Sprintf("File: %s\n", filepath.Base(file)))
-				output.WriteString(truncateContent(string(content)))
-				output.WriteString("\n\n")
-			}
-		}
-	}
 }
 
 func analyzeImportantFiles(output *strings.Builder) {
 	importantFiles := []string{
-		".dockerignore", ".gitignore", "Dockerfile",
+		".dockerignore", ".gitignore",
 		"Procfile", "Rakefile", "Makefile", ".env", "package.json",
 		"Gemfile", "requirements.txt", "go.mod", "go.sum", "main.go", "README.md", "LICENSE",
 		"Vagrantfile", "ansible.cfg", "Jenkinsfile", "cloudbuild.yaml", "serverless.yml", "Chart.yaml",
@@ -300,11 +301,9 @@ func analyzeImportantFiles(output *strings.Builder) {
 	}
 
 	multiExtensionFiles := map[string][]string{
-		"docker-compose": {".yml", ".yaml"},
-		"compose":        {".yml", ".yaml"},
-		"vite.config":    {".js", ".ts", ".mjs", ".mts"},
-		"astro.config":   {".js", ".ts", ".mjs", ".mts"},
-		"next.config":    {".js", ".ts", ".mjs", ".mts"},
+		"vite.config":  {".js", ".ts", ".mjs", ".mts"},
+		"astro.config": {".js", ".ts", ".mjs", ".mts"},
+		"next.config":  {".js", ".ts", ".mjs", ".mts"},
 	}
 
 	for baseName, extensions := range multiExtensionFiles {
@@ -324,68 +323,39 @@ func analyzeImportantFiles(output *strings.Builder) {
 }
 
 func analyzeGoProject(output *strings.Builder) {
-	if fileExists("go.mod") {
+	project, graph, err := loadGoAnalysis()
+	if project != nil {
 		output.WriteString("\n### Go Project Analysis ###\n")
-
-		// Analyze go.mod
-		modContent, _ := os.ReadFile("go.mod")
-		output.WriteString("go.mod contents:\n")
-		output.WriteString(truncateContent(string(modContent)))
-		output.WriteString("\n\n")
-
-		// Analyze main.go if it exists
-		if fileExists("main.go") {
-			mainContent, _ := os.ReadFile("main.go")
-			output.WriteString("main.go contents:\n")
-			output.WriteString(truncateContent(string(mainContent)))
-			output.WriteString("\n\n")
-		}
-
-		// List all Go files
-		output.WriteString("Go files in the project:\n")
-		err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() && strings.HasSuffix(info.Name(), ".go") {
-				output.WriteString(fmt.Sprintf("- %s\n", path))
-			}
-			return nil
-		})
+		writeGoProject(output, project)
 		if err != nil {
-			output.WriteString(fmt.Sprintf("Error walking the path: %v\n", err))
+			output.WriteString(fmt.Sprintf("Error loading layering rules: %v\n", err))
 		}
+		writeGoImportGraph(output, graph)
+	}
+}
+
+func analyzeNodeWorkspace(output *strings.Builder) {
+	workspace, err := loadNodeWorkspace()
+	if err != nil {
+		output.WriteString(fmt.Sprintf("\n### Node.js Workspace Analysis ###\nError detecting workspace: %v\n", err))
+	} else if workspace != nil {
+		output.WriteString("\n### Node.js Workspace Analysis ###\n")
+		writeNodeWorkspace(output, workspace)
 	}
 }
 
 func analyzeDependencies(output *strings.Builder) {
 	output.WriteString("\n### Dependencies Analysis ###\n")
 
-	// Analyze package.json for Node.js projects
-	if fileExists("package.json") {
-		content, _ := os.ReadFile("package.json")
-		var packageJSON map[string]interface{}
-		if err := json.Unmarshal(content, &packageJSON); err == nil {
-			if deps, ok := packageJSON["dependencies"].(map[string]interface{}); ok {
-				output.WriteString("Node.js Dependencies:\n")
-				for dep, version := range deps {
-					output.WriteString(fmt.Sprintf("- %s: %v\n", dep, version))
-				}
-			}
-		}
+	dependencies, errs := collectDependencies()
+	for _, err := range errs {
+		output.WriteString(fmt.Sprintf("%v\n", err))
 	}
-
-	// Analyze go.mod for Go projects
-	if fileExists("go.mod") {
-		content, _ := os.ReadFile("go.mod")
-		output.WriteString("Go Dependencies:\n")
-		lines := strings.Split(string(content), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "\t") && !strings.Contains(line, "=>") {
-				output.WriteString(fmt.Sprintf("- %s\n", strings.TrimSpace(line)))
-			}
-		}
+	writeDependencySummary(output, dependencies)
+	if len(dependencies) > 0 {
+		writeDependencyGraphSummary(output, buildDependencyGraph(dependencies))
 	}
+	writeLicenseReport(output, dependencies)
 }
 
 func analyzeConfiguration(output *strings.Builder) {
@@ -413,6 +383,10 @@ func analyzeConfiguration(output *strings.Builder) {
 	yamlFiles = append(yamlFiles, ymlFiles...)
 
 	for _, file := range yamlFiles {
+		// Compose files hold inline credentials; the Docker Compose section summarizes them instead
+		if isComposeFileName(file) {
+			continue
+		}
 		content, err := os.ReadFile(file)
 		if err != nil {
 			output.WriteString(fmt.Sprintf("Error reading file %s: %v\n", file, err))
@@ -439,13 +413,6 @@ func analyzeDocumentation(output *strings.Builder) {
 		output.WriteString("\n\n")
 	}
 
-	if fileExists("LICENSE") {
-		content, _ := os.ReadFile("LICENSE")
-		output.WriteString("LICENSE contents:\n")
-		output.WriteString(truncateContent(string(content)))
-		output.WriteString("\n\n")
-	}
-
 	// Check for other documentation files
 	docFiles, _ := filepath.Glob("docs/*.md")
 	for _, file := range docFiles {
@@ -459,46 +426,36 @@ func analyzeDocumentation(output *strings.Builder) {
 func analyzeContainerization(output *strings.Builder) {
 	output.WriteString("\n### Containerization Analysis ###\n")
 
-	if fileExists("Dockerfile") {
-		content, _ := os.ReadFile("Dockerfile")
-		output.WriteString("Dockerfile found:\n")
-		output.WriteString(truncateContent(string(content)))
-		output.WriteString("\n\n")
+	for _, dockerfile := range findDockerfiles() {
+		analysis, err := analyzeDockerfile(dockerfile)
+		if err != nil {
+			output.WriteString(fmt.Sprintf("Error reading %s: %v\n", dockerfile, err))
+			continue
+		}
+		writeDockerfileAnalysis(output, analysis)
+		output.WriteString("\n")
 	}
 
-	composeFiles := []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
-	for _, file := range composeFiles {
-		if fileExists(file) {
-			content, _ := os.ReadFile(file)
-			output.WriteString(fmt.Sprintf("%s found:\n", file))
-			output.WriteString(truncateContent(string(content)))
-			output.WriteString("\n\n")
-			break
+	for _, project := range findComposeProjects() {
+		if err := loadComposeProject(project); err != nil {
+			output.WriteString(fmt.Sprintf("Error parsing compose files in %s: %v\n", project.Dir, err))
+			continue
 		}
+		writeComposeProject(output, project)
+		output.WriteString("\n")
 	}
 }
 
 func analyzeInfrastructureAsCode(output *strings.Builder) {
 	output.WriteString("\n### Infrastructure as Code Analysis ###\n")
 
-	if dirExists("terraform") {
-		output.WriteString("Terraform configuration found.\n")
-		tfFiles, _ := filepath.Glob("terraform/*.tf")
-		for _, file := range tfFiles {
-			content, _ := os.ReadFile(file)
-			output.WriteString(fmt.Sprintf("File: %s\n", filepath.Base(file)))
-			output.WriteString(truncateContent(string(content)))
-			output.WriteString("\n\n")
-		}
-	}
+	writeTerraformModules(output, loadTerraformModules())
+	writeTerraformFindings(output, terraformFindings())
 
-	if fileExists("serverless.yml") || fileExists("serverless.yaml") {
-		output.WriteString("Serverless Framework configuration found.\n")
-	}
+	writeServerlessApps(output, findServerlessApps())
+	writeAnsibleProject(output, loadAnsibleProject())
 
-	if fileExists("Chart.yaml") {
-		output.WriteString("Helm Chart found.\n")
-	}
+	writeHelmCharts(output, loadHelmCharts())
 }
 
 func analyzeCICDPipelines(output *strings.Builder) {
@@ -0,0 +1,87 @@
+package grabitsh
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redactedPlaceholder = "<redacted>"
+
+var (
+	// A URL whose authority may carry user:password@; the password can contain / and @, so the
+	// userinfo is split off in urlUserinfoEnd rather than by the pattern
+	urlCredentialsRegex = regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9+.-]*://)([^/\s:@'"]+)([:@][^\s'"]*)`)
+	urlHostRegex        = regexp.MustCompile(`^(?:[A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)*|\[[0-9A-Fa-f:.]+\])(?::\d*)?$`)
+	urlPortPathRegex    = regexp.MustCompile(`^:\d*(?:[/?#]|$)`)
+	authHeaderRegex     = regexp.MustCompile(`(?i)\b((?:proxy-)?authorization\s*[:=]\s*)(?:(basic|bearer|token|digest|negotiate)\s+)?[^\s"',;]+`)
+	npmrcAuthRegex      = regexp.MustCompile(`(?im)^(\s*(?:\S*:)?_(?:authToken|auth|password)\s*=\s*)(.+)$`)
+	extraHeaderRegex    = regexp.MustCompile(`(?im)^(\s*(?:http\.\S*)?extraheader\s*=\s*)(.+)$`)
+	envReferenceRegex   = regexp.MustCompile(`^\$\{?[A-Za-z_][A-Za-z0-9_]*\}?$`)
+)
+
+// Mask credentials before report output leaves the process (stdout, clipboard, files or LLM chunks)
+func redactSensitiveOutput(content string) string {
+	content = extraHeaderRegex.ReplaceAllString(content, "${1}"+redactedPlaceholder)
+
+	content = npmrcAuthRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := npmrcAuthRegex.FindStringSubmatch(match)
+		if envReferenceRegex.MatchString(strings.TrimSpace(parts[2])) {
+			return match
+		}
+		return parts[1] + redactedPlaceholder
+	})
+
+	content = authHeaderRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := authHeaderRegex.FindStringSubmatch(match)
+		if parts[2] != "" {
+			return parts[1] + parts[2] + " " + redactedPlaceholder
+		}
+		return parts[1] + redactedPlaceholder
+	})
+
+	return redactURLCredentials(content)
+}
+
+func redactURLCredentials(content string) string {
+	return urlCredentialsRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := urlCredentialsRegex.FindStringSubmatch(match)
+		rest := parts[2] + parts[3]
+		end := urlUserinfoEnd(rest)
+		// A bare user name on an SSH URL (ssh://git@host) is not a credential
+		if end < 0 || (end == len(parts[2]) && strings.Contains(strings.ToLower(parts[1]), "ssh")) {
+			// The match runs to the end of the token, which may hold another URL (?next=https://...)
+			return parts[1] + parts[2] + redactURLCredentials(parts[3])
+		}
+		return parts[1] + redactedPlaceholder + rest[end:end+1] + redactURLCredentials(rest[end+1:])
+	})
+}
+
+// The index of the @ ending the userinfo of a URL written without its scheme, or -1 when there is none.
+// It is the last @ followed by a host; one followed by a dotted host, a port or localhost wins over
+// a bare name, so npm scopes in the path (/@scope/pkg) are not taken for the host.
+func urlUserinfoEnd(rest string) int {
+	// host:port/path has no userinfo, even with an @ further along the path
+	if i := strings.IndexAny(rest, ":@"); i >= 0 && rest[i] == ':' && urlPortPathRegex.MatchString(rest[i:]) {
+		return -1
+	}
+	end := -1
+	for i := strings.LastIndex(rest, "@"); i > 0; i = strings.LastIndex(rest[:i], "@") {
+		if strings.ContainsAny(rest[:i], "?#") {
+			continue
+		}
+		host := rest[i+1:]
+		if j := strings.IndexAny(host, "/?#"); j >= 0 {
+			host = host[:j]
+		}
+		if !urlHostRegex.MatchString(host) {
+			continue
+		}
+		if strings.ContainsAny(host, ".:[") || host == "localhost" {
+			return i
+		}
+		if end < 0 {
+			end = i
+		}
+	}
+	return end
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 
 	"github.com/atotto/clipboard"
@@ -16,6 +15,7 @@ import (
 var (
 	outputMethod string
 	outputFile   string
+	outputFormat string
 	chunkSize    int
 	rootCmd      *cobra.Command
 )
@@ -28,11 +28,18 @@ func init() {
 		Run:   runGrabit,
 	}
 
-	rootCmd.Flags().StringVarP(&outputMethod, "output", "o", "stdout", "Output method: stdout, clipboard, file, or llm-chunks")
-	rootCmd.Flags().StringVarP(&outputFile, "file", "f", "", "Output file path (required if output method is file)")
-	rootCmd.Flags().IntVarP(&chunkSize, "chunk-size", "c", 100000, "Token size for LLM chunks (default 100000)")
+	rootCmd.PersistentFlags().StringVarP(&outputMethod, "output", "o", "stdout", "Output method: stdout, clipboard, file, or llm-chunks")
+	rootCmd.PersistentFlags().StringVarP(&outputFile, "file", "f", "", "Output file path (required if output method is file)")
+	rootCmd.PersistentFlags().IntVarP(&chunkSize, "chunk-size", "c", 100000, "Token size for LLM chunks (default 100000)")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "text", "Report format: text, or sarif for security and quality findings")
+	rootCmd.Flags().StringVar(&vulnDBPath, "vulndb", "", vulnDBFlagUsage)
 
 	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(secretsCmd)
+	rootCmd.AddCommand(vulndbCmd)
+	rootCmd.AddCommand(sbomCmd)
+	rootCmd.AddCommand(depgraphCmd)
+	rootCmd.AddCommand(outdatedCmd)
 }
 
 func Execute() error {
@@ -40,6 +47,21 @@ func Execute() error {
 }
 
 func runGrabit(cmd *cobra.Command, args []string) {
+	switch outputFormat {
+	case "text":
+	case "sarif":
+		sarif, err := renderSARIF(collectFindings())
+		if err != nil {
+			color.Red("Failed to generate SARIF: %v", err)
+			return
+		}
+		finalizeOutput(sarif)
+		return
+	default:
+		color.Red("Invalid format. Choose text or sarif.")
+		return
+	}
+
 	var outputBuffer bytes.Buffer
 
 	// Collect all sections
@@ -84,7 +106,12 @@ func collectGitInfo(buffer *bytes.Buffer) {
 	buffer.WriteString("\nRemote Repositories:\n")
 	buffer.WriteString(runCommand("git", "remote", "-v"))
 	buffer.WriteString("\nGit Status:\n")
-	buffer.WriteString(runCommand("git", "status", "--short"))
+	status, err := collectGitStatus()
+	if err != nil {
+		buffer.WriteString(fmt.Sprintf("Error collecting git status: %v\n", err))
+		return
+	}
+	writeGitStatus(buffer, status)
 }
 
 func collectProjectAnalysis(buffer *bytes.Buffer) {
@@ -113,6 +140,12 @@ func collectProjectTypes(buffer *bytes.Buffer) {
 	for _, projectType := range projectTypes {
 		buffer.WriteString(fmt.Sprintf("- %s\n", projectType))
 	}
+
+	// Project roots below the repository root, e.g. services/api/go.mod or web/package.json
+	projects := detectSubProjects()
+	if len(projects) > 1 || (len(projects) == 1 && projects[0].Path != ".") {
+		writeSubProjects(buffer, projects)
+	}
 }
 
 func collectTODOs(buffer *bytes.Buffer) {
@@ -136,27 +169,19 @@ func collectTODOs(buffer *bytes.Buffer) {
 func collectSecurityAnalysis(buffer *bytes.Buffer) {
 	buffer.WriteString("\n### Security Analysis ###\n")
 
-	// Check for sensitive files
-	sensitiveFiles := []string{".env", "id_rsa", "id_dsa", "*.pem", "*.key"}
-	for _, pattern := range sensitiveFiles {
-		files, _ := filepath.Glob(pattern)
-		if len(files) > 0 {
-			for _, file := range files {
-				if file == ".env" {
-					// Output .env file as sanitized example
-					content, _ := os.ReadFile(file)
-					buffer.WriteString(fmt.Sprintf("Sanitized .env Example:\n%s\n", sanitizeEnvFile(string(content))))
-				} else {
-					buffer.WriteString(fmt.Sprintf("Warning: Sensitive file detected: %s\n", file))
-				}
-			}
-		}
+	config, err := loadGrabitConfig()
+	if err != nil {
+		buffer.WriteString(fmt.Sprintf("Error loading configuration: %v\n", err))
 	}
 
-	// Check for outdated dependencies (example for Node.js projects)
-	if fileExists("package.json") {
-		buffer.WriteString(runCommand("npm", "audit"))
-	}
+	// Check for sensitive files anywhere in the tree
+	writeSensitiveFiles(buffer, detectSensitiveFiles(config.SensitiveFiles))
+
+	// Scan tracked files for secrets
+	writeSecretScanResult(buffer, scanWorkingTreeForSecrets())
+
+	// Match lockfile dependencies against the local OSV database
+	writeVulnerabilityReport(buffer)
 }
 
 func collectPerformanceMetrics(buffer *bytes.Buffer) {
@@ -176,6 +201,8 @@ func collectPerformanceMetrics(buffer *bytes.Buffer) {
 }
 
 func finalizeOutput(content string) {
+	content = redactSensitiveOutput(content)
+
 	switch outputMethod {
 	case "stdout":
 		color.Green(content)
@@ -0,0 +1,154 @@
+package grabitsh
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// Render findings as a SARIF 2.1.0 log with one rule per distinct rule ID
+func renderSARIF(findings []Finding) (string, error) {
+	driver := sarifDriver{
+		Name:           "grabitsh",
+		InformationURI: "https://github.com/loftwah/grabitsh",
+		Rules:          []sarifRule{},
+	}
+	results := []sarifResult{}
+	ruleIndex := make(map[string]int)
+
+	for _, finding := range findings {
+		level := sarifLevel(finding.Severity)
+
+		index, ok := ruleIndex[finding.RuleID]
+		if !ok {
+			properties := map[string]interface{}{"tags": []string{finding.Category}}
+			if finding.Category == categorySecurity {
+				properties["security-severity"] = securitySeverityScore(finding.Severity)
+			}
+			index = len(driver.Rules)
+			ruleIndex[finding.RuleID] = index
+			driver.Rules = append(driver.Rules, sarifRule{
+				ID:                   finding.RuleID,
+				ShortDescription:     sarifMessage{Text: finding.RuleDescription},
+				DefaultConfiguration: sarifConfiguration{Level: level},
+				Properties:           properties,
+			})
+		}
+
+		result := sarifResult{
+			RuleID:              finding.RuleID,
+			RuleIndex:           index,
+			Level:               level,
+			Message:             sarifMessage{Text: finding.Message},
+			PartialFingerprints: map[string]string{"grabitsh/v1": finding.Fingerprint},
+		}
+		if finding.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: finding.File, URIBaseID: "%SRCROOT%"},
+			}}
+			if finding.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	content, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding SARIF: %w", err)
+	}
+	return string(content) + "\n", nil
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	}
+	return "note"
+}
+
+// Numeric score used by code scanning dashboards to bucket security results
+func securitySeverityScore(severity string) string {
+	switch severity {
+	case "critical":
+		return "9.5"
+	case "high":
+		return "8.0"
+	case "medium":
+		return "5.5"
+	}
+	return "2.0"
+}
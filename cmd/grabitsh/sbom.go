@@ -0,0 +1,447 @@
+package grabitsh
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var sbomFormat string
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Generate a software bill of materials from the repository's lockfiles",
+	Run:   runSBOM,
+}
+
+func init() {
+	sbomCmd.Flags().StringVar(&sbomFormat, "format", "cyclonedx-json", "SBOM format: cyclonedx-json (CycloneDX 1.5) or spdx-json (SPDX 2.3)")
+}
+
+func runSBOM(cmd *cobra.Command, args []string) {
+	dependencies, errs := collectDependencies()
+	for _, err := range errs {
+		color.Yellow("Skipping lockfile: %v", err)
+	}
+	sbom := buildSBOM(dependencies)
+
+	var document interface{}
+	switch sbomFormat {
+	case "cyclonedx-json":
+		document = renderCycloneDX(sbom)
+	case "spdx-json":
+		document = renderSPDX(sbom)
+	default:
+		color.Red("Invalid format. Choose cyclonedx-json or spdx-json.")
+		return
+	}
+
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		color.Red("Failed to generate SBOM: %v", err)
+		return
+	}
+	finalizeOutput(string(content) + "\n")
+}
+
+type sbomComponent struct {
+	Dependency
+	PURL      string
+	DependsOn []string
+}
+
+type sbomDocument struct {
+	Name       string
+	Version    string
+	Components []*sbomComponent
+	// Direct dependencies of the repository itself, by purl
+	Direct    []string
+	DirectDev []string
+}
+
+// Merge the dependencies of every lockfile into components keyed by purl
+func buildSBOM(dependencies []Dependency) sbomDocument {
+	sbom := sbomDocument{Name: sbomProjectName()}
+	if version, err := runCommandOutput("git", "describe", "--tags", "--always"); err == nil {
+		sbom.Version = strings.TrimSpace(version)
+	}
+
+	byPURL := make(map[string]*sbomComponent)
+	for _, dep := range dependencies {
+		purl := dependencyPURL(dep)
+		component, ok := byPURL[purl]
+		if !ok {
+			component = &sbomComponent{Dependency: dep, PURL: purl}
+			byPURL[purl] = component
+			sbom.Components = append(sbom.Components, component)
+		}
+		// A package that is production anywhere is production in the merged document
+		if dep.Scope == scopeProd {
+			component.Scope = scopeProd
+		}
+		component.Direct = component.Direct || dep.Direct
+		// Keep the first integrity value that maps to a standard hash algorithm
+		if algorithm, _ := dependencyHash(component.Integrity); algorithm == "" {
+			component.Integrity = dep.Integrity
+		}
+		if len(component.Licenses) == 0 {
+			component.Licenses = dep.Licenses
+		}
+	}
+
+	for purl, targets := range dependencyEdges(dependencies) {
+		byPURL[purl].DependsOn = targets
+	}
+
+	sort.Slice(sbom.Components, func(i, j int) bool { return sbom.Components[i].PURL < sbom.Components[j].PURL })
+	for _, component := range sbom.Components {
+		sort.Strings(component.DependsOn)
+		if !component.Direct {
+			continue
+		}
+		if component.Scope == scopeDev {
+			sbom.DirectDev = append(sbom.DirectDev, component.PURL)
+		} else {
+			sbom.Direct = append(sbom.Direct, component.PURL)
+		}
+	}
+	return sbom
+}
+
+func sbomProjectName() string {
+	if root, err := runCommandOutput("git", "rev-parse", "--show-toplevel"); err == nil && strings.TrimSpace(root) != "" {
+		return filepath.Base(strings.TrimSpace(root))
+	}
+	if dir, err := os.Getwd(); err == nil {
+		return filepath.Base(dir)
+	}
+	return "project"
+}
+
+// Package URL (purl) for a dependency, following the purl type definitions for each ecosystem
+func dependencyPURL(dep Dependency) string {
+	escape := func(segments ...string) string {
+		for i, segment := range segments {
+			segments[i] = strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+		}
+		return strings.Join(segments, "/")
+	}
+
+	var purl string
+	switch dep.Ecosystem {
+	case ecosystemNpm:
+		purl = "pkg:npm/" + escape(strings.SplitN(dep.Name, "/", 2)...)
+	case ecosystemGo:
+		purl = "pkg:golang/" + escape(strings.Split(dep.Name, "/")...)
+	case ecosystemPyPI:
+		purl = "pkg:pypi/" + escape(normalizePythonName(dep.Name))
+	case ecosystemRubyGems:
+		purl = "pkg:gem/" + escape(dep.Name)
+	case ecosystemCrates:
+		purl = "pkg:cargo/" + escape(dep.Name)
+	case ecosystemPackagist:
+		purl = "pkg:composer/" + escape(strings.SplitN(dep.Name, "/", 2)...)
+	case ecosystemMaven:
+		purl = "pkg:maven/" + escape(strings.SplitN(dep.Name, ":", 2)...)
+	default:
+		purl = "pkg:generic/" + escape(dep.Name)
+	}
+	if dep.Version != "" {
+		purl += "@" + url.PathEscape(dep.Version)
+	}
+	return purl
+}
+
+var hexDigestRegex = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// Convert a lockfile integrity value into a hash algorithm and hex digest; unknown notations
+// (Go's h1: dirhash, yarn berry cache checksums) return empty strings
+func dependencyHash(integrity string) (string, string) {
+	algorithms := map[string]string{"sha1": "SHA-1", "sha256": "SHA-256", "sha384": "SHA-384", "sha512": "SHA-512"}
+
+	// Subresource integrity (npm, yarn classic, pnpm): sha512-<base64>
+	if name, encoded, found := strings.Cut(integrity, "-"); found {
+		if algorithm, ok := algorithms[name]; ok {
+			if digest, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+				return algorithm, hex.EncodeToString(digest)
+			}
+		}
+	}
+	// sha256:<hex> (pip, Poetry, Pipenv) and sha256=<hex> (Bundler checksums)
+	for _, separator := range []string{":", "="} {
+		if name, digest, found := strings.Cut(integrity, separator); found && hexDigestRegex.MatchString(digest) {
+			if algorithm, ok := algorithms[name]; ok {
+				return algorithm, strings.ToLower(digest)
+			}
+		}
+	}
+	// Bare hex digests: Cargo checksums are SHA-256, Composer dist shasums SHA-1
+	if hexDigestRegex.MatchString(integrity) {
+		switch len(integrity) {
+		case 64:
+			return "SHA-256", strings.ToLower(integrity)
+		case 40:
+			return "SHA-1", strings.ToLower(integrity)
+		}
+	}
+	return "", ""
+}
+
+var spdxLicenseTokenRegex = regexp.MustCompile(`^(LicenseRef-)?[A-Za-z0-9][A-Za-z0-9.+-]*$`)
+
+// Combine declared licenses into one SPDX expression, or "" when they are not valid SPDX
+func licenseExpression(licenses []string) string {
+	expression := strings.Join(licenses, " OR ")
+	if len(licenses) > 1 {
+		expression = "(" + expression + ")"
+	}
+	tokens := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression))
+	if len(tokens) == 0 {
+		return ""
+	}
+	for _, token := range tokens {
+		if token != "AND" && token != "OR" && token != "WITH" && !spdxLicenseTokenRegex.MatchString(token) {
+			return ""
+		}
+	}
+	// Valid identifiers can still be missing the operator between them
+	if _, err := parseSPDXExpression(expression); err != nil {
+		return ""
+	}
+	return expression
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type       string                   `json:"type"`
+	BOMRef     string                   `json:"bom-ref,omitempty"`
+	Group      string                   `json:"group,omitempty"`
+	Name       string                   `json:"name"`
+	Version    string                   `json:"version,omitempty"`
+	Scope      string                   `json:"scope,omitempty"`
+	Hashes     []cycloneDXHash          `json:"hashes,omitempty"`
+	Licenses   []cycloneDXLicenseChoice `json:"licenses,omitempty"`
+	PURL       string                   `json:"purl,omitempty"`
+	Properties []cycloneDXProperty      `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cycloneDXLicenseChoice struct {
+	License    *cycloneDXLicense `json:"license,omitempty"`
+	Expression string            `json:"expression,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func renderCycloneDX(sbom sbomDocument) cycloneDXBOM {
+	const rootRef = "root"
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     cycloneDXTools{Components: []cycloneDXComponent{{Type: "application", Name: "grabitsh"}}},
+			Component: cycloneDXComponent{Type: "application", BOMRef: rootRef, Name: sbom.Name, Version: sbom.Version},
+		},
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{{Ref: rootRef, DependsOn: append(append([]string{}, sbom.Direct...), sbom.DirectDev...)}},
+	}
+
+	for _, component := range sbom.Components {
+		entry := cycloneDXComponent{Type: "library", BOMRef: component.PURL, Name: component.Name, Version: component.Version, PURL: component.PURL, Scope: "required"}
+		switch {
+		case component.Ecosystem == ecosystemMaven && strings.Contains(component.Name, ":"):
+			entry.Group, entry.Name, _ = strings.Cut(component.Name, ":")
+		case component.Ecosystem == ecosystemNpm && strings.HasPrefix(component.Name, "@"):
+			entry.Group, entry.Name, _ = strings.Cut(component.Name, "/")
+		}
+		if component.Scope == scopeDev {
+			entry.Scope = "optional"
+		}
+		if algorithm, digest := dependencyHash(component.Integrity); algorithm != "" {
+			entry.Hashes = []cycloneDXHash{{Algorithm: algorithm, Content: digest}}
+		}
+		if expression := licenseExpression(component.Licenses); expression != "" {
+			if len(component.Licenses) == 1 && !strings.Contains(expression, " ") {
+				entry.Licenses = []cycloneDXLicenseChoice{{License: &cycloneDXLicense{ID: expression}}}
+			} else {
+				entry.Licenses = []cycloneDXLicenseChoice{{Expression: expression}}
+			}
+		} else {
+			for _, license := range component.Licenses {
+				entry.Licenses = append(entry.Licenses, cycloneDXLicenseChoice{License: &cycloneDXLicense{Name: license}})
+			}
+		}
+		entry.Properties = []cycloneDXProperty{
+			{Name: "grabitsh:source", Value: component.Source},
+			{Name: "grabitsh:direct", Value: fmt.Sprintf("%t", component.Direct)},
+		}
+		bom.Components = append(bom.Components, entry)
+		bom.Dependencies = append(bom.Dependencies, cycloneDXDependency{Ref: component.PURL, DependsOn: append([]string{}, component.DependsOn...)})
+	}
+	return bom
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDInvalidRegex = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+func renderSPDX(sbom sbomDocument) spdxDocument {
+	const rootID = "SPDXRef-Package-root"
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              sbom.Name,
+		DocumentNamespace: fmt.Sprintf("https://github.com/loftwah/grabitsh/spdx/%s-%s", url.PathEscape(sbom.Name), newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: grabitsh"},
+		},
+		Packages: []spdxPackage{{
+			Name: sbom.Name, SPDXID: rootID, VersionInfo: sbom.Version, DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: "NOASSERTION", CopyrightText: "NOASSERTION", PrimaryPackagePurpose: "APPLICATION",
+		}},
+		Relationships: []spdxRelationship{{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: rootID}},
+	}
+
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	for _, component := range sbom.Components {
+		id := "SPDXRef-Package-" + strings.Trim(spdxIDInvalidRegex.ReplaceAllString(component.Ecosystem+"-"+component.Name+"-"+component.Version, "-"), "-")
+		for base, n := id, 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		ids[component.PURL] = id
+
+		entry := spdxPackage{
+			Name: component.Name, SPDXID: id, VersionInfo: component.Version, DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: "NOASSERTION", CopyrightText: "NOASSERTION", PrimaryPackagePurpose: "LIBRARY",
+			ExternalRefs: []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: component.PURL}},
+		}
+		if expression := licenseExpression(component.Licenses); expression != "" {
+			entry.LicenseDeclared = expression
+		}
+		if algorithm, digest := dependencyHash(component.Integrity); algorithm != "" {
+			entry.Checksums = []spdxChecksum{{Algorithm: strings.ReplaceAll(algorithm, "-", ""), ChecksumValue: digest}}
+		}
+		document.Packages = append(document.Packages, entry)
+	}
+
+	for _, purl := range sbom.Direct {
+		document.Relationships = append(document.Relationships, spdxRelationship{SPDXElementID: rootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: ids[purl]})
+	}
+	for _, purl := range sbom.DirectDev {
+		document.Relationships = append(document.Relationships, spdxRelationship{SPDXElementID: ids[purl], RelationshipType: "DEV_DEPENDENCY_OF", RelatedSPDXElement: rootID})
+	}
+	for _, component := range sbom.Components {
+		for _, purl := range component.DependsOn {
+			document.Relationships = append(document.Relationships, spdxRelationship{SPDXElementID: ids[component.PURL], RelationshipType: "DEPENDS_ON", RelatedSPDXElement: ids[purl]})
+		}
+	}
+	return document
+}
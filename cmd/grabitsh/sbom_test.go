@@ -0,0 +1,114 @@
+package grabitsh
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Dependencies from two lockfiles that share a package, covering scoped npm names, dev scope,
+// SRI and hex hashes, license expressions and requirement edges
+func sbomTestDependencies() []Dependency {
+	return []Dependency{
+		{Name: "@babel/core", Version: "7.24.0", Ecosystem: ecosystemNpm, Source: "package-lock.json", Direct: true, Scope: scopeProd,
+			Integrity: "sha512-3q2+7w==", Licenses: []string{"MIT"}, Requires: []string{"debug"}},
+		{Name: "debug", Version: "4.3.4", Ecosystem: ecosystemNpm, Source: "package-lock.json", Scope: scopeProd,
+			Integrity: "sha1-3q2+7w==", Licenses: []string{"MIT", "Apache-2.0"}},
+		{Name: "jest", Version: "29.7.0", Ecosystem: ecosystemNpm, Source: "package-lock.json", Direct: true, Scope: scopeDev,
+			Licenses: []string{"Custom License"}, Requires: []string{"debug"}},
+		{Name: "Flask_Cors", Version: "4.0.0", Ecosystem: ecosystemPyPI, Source: "poetry.lock", Direct: true, Scope: scopeDev,
+			Integrity: "sha256:DEADBEEF", Licenses: []string{"MIT OR Apache-2.0"}},
+		{Name: "Flask_Cors", Version: "4.0.0", Ecosystem: ecosystemPyPI, Source: "requirements.txt", Scope: scopeProd},
+	}
+}
+
+// Compare a rendered document against testdata/<name>, rewriting the file with -update
+func checkGolden(t *testing.T, name string, document interface{}) {
+	t.Helper()
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = append(content, '\n')
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(want) {
+		t.Errorf("%s differs from the golden file:\n%s", name, content)
+	}
+}
+
+func testSBOM(t *testing.T) sbomDocument {
+	t.Helper()
+	sbom := buildSBOM(sbomTestDependencies())
+	// The project name and version come from git and the working directory
+	sbom.Name, sbom.Version = "demo", "v1.0.0"
+	return sbom
+}
+
+func TestRenderCycloneDXGolden(t *testing.T) {
+	bom := renderCycloneDX(testSBOM(t))
+	bom.SerialNumber = "urn:uuid:00000000-0000-4000-8000-000000000000"
+	bom.Metadata.Timestamp = "2024-01-01T00:00:00Z"
+	checkGolden(t, "sbom.cdx.json", bom)
+}
+
+func TestRenderSPDXGolden(t *testing.T) {
+	document := renderSPDX(testSBOM(t))
+	document.DocumentNamespace = "https://github.com/loftwah/grabitsh/spdx/demo-00000000-0000-4000-8000-000000000000"
+	document.CreationInfo.Created = "2024-01-01T00:00:00Z"
+	checkGolden(t, "sbom.spdx.json", document)
+}
+
+func TestDependencyPURL(t *testing.T) {
+	tests := []struct {
+		dep  Dependency
+		want string
+	}{
+		{Dependency{Name: "@babel/core", Version: "7.24.0", Ecosystem: ecosystemNpm}, "pkg:npm/%40babel/core@7.24.0"},
+		{Dependency{Name: "github.com/spf13/cobra", Version: "v1.8.0", Ecosystem: ecosystemGo}, "pkg:golang/github.com/spf13/cobra@v1.8.0"},
+		{Dependency{Name: "Flask_Cors", Version: "4.0.0", Ecosystem: ecosystemPyPI}, "pkg:pypi/flask-cors@4.0.0"},
+		{Dependency{Name: "rails", Version: "7.1.0", Ecosystem: ecosystemRubyGems}, "pkg:gem/rails@7.1.0"},
+		{Dependency{Name: "serde", Version: "1.0.0", Ecosystem: ecosystemCrates}, "pkg:cargo/serde@1.0.0"},
+		{Dependency{Name: "symfony/console", Version: "v6.4.0", Ecosystem: ecosystemPackagist}, "pkg:composer/symfony/console@v6.4.0"},
+		{Dependency{Name: "org.slf4j:slf4j-api", Version: "2.0.9", Ecosystem: ecosystemMaven}, "pkg:maven/org.slf4j/slf4j-api@2.0.9"},
+		{Dependency{Name: "local-lib", Ecosystem: "other"}, "pkg:generic/local-lib"},
+	}
+	for _, test := range tests {
+		if got := dependencyPURL(test.dep); got != test.want {
+			t.Errorf("dependencyPURL(%s) = %q, want %q", test.dep.Name, got, test.want)
+		}
+	}
+}
+
+func TestDependencyHash(t *testing.T) {
+	tests := []struct {
+		integrity, algorithm, digest string
+	}{
+		{"sha512-3q2+7w==", "SHA-512", "deadbeef"},
+		{"sha256:DEADBEEF", "SHA-256", "deadbeef"},
+		{"sha256=deadbeef", "SHA-256", "deadbeef"},
+		{"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "SHA-256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"da39a3ee5e6b4b0d3255bfef95601890afd80709", "SHA-1", "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{"h1:abc=", "", ""},
+		{"md5-3q2+7w==", "", ""},
+		{"", "", ""},
+	}
+	for _, test := range tests {
+		algorithm, digest := dependencyHash(test.integrity)
+		if algorithm != test.algorithm || digest != test.digest {
+			t.Errorf("dependencyHash(%q) = %q, %q, want %q, %q", test.integrity, algorithm, digest, test.algorithm, test.digest)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package grabitsh
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	secretsAllowlistFile = ".grabitsh-allowlist"
+	maxSecretScanSize    = 2 * 1024 * 1024
+	inlineAllowMarker    = "grabitsh:allow"
+)
+
+type SecretRule struct {
+	ID          string
+	Description string
+	Severity    string
+	Pattern     *regexp.Regexp
+	// Index of the capture group holding the secret (0 for the whole match)
+	SecretGroup int
+	// Minimum Shannon entropy of the secret, 0 to disable the check
+	MinEntropy float64
+}
+
+type SecretFinding struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	RuleID      string `json:"rule_id"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Secret      string `json:"secret"`
+	Fingerprint string `json:"fingerprint"`
+	// Path-independent hash of the raw secret, used to follow a secret across files and commits
+	valueHash string
+}
+
+type SecretScanResult struct {
+	FilesScanned int             `json:"files_scanned"`
+	Findings     []SecretFinding `json:"findings"`
+}
+
+var secretRules = []SecretRule{
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Severity:    "high",
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[0-9A-Z]{16})\b`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Severity:    "critical",
+		Pattern:     regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Severity:    "critical",
+		Pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{60,255})\b`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack incoming webhook URL",
+		Severity:    "high",
+		Pattern:     regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack API token",
+		Severity:    "high",
+		Pattern:     regexp.MustCompile(`\b(xox[abposr]-[0-9A-Za-z-]{10,})\b`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "private-key",
+		Description: "Private key block",
+		Severity:    "critical",
+		Pattern:     regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		ID:          "jwt",
+		Description: "JSON Web Token",
+		Severity:    "medium",
+		Pattern:     regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "generic-high-entropy-secret",
+		Description: "High-entropy value assigned to a secret-like name",
+		Severity:    "medium",
+		Pattern:     regexp.MustCompile(`(?i)[\w.-]*(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key|private[_-]?key|auth|credential)[\w.-]*["']?\s*(?::=|=>|[:=])\s*["']?([^"'\s,;]{16,})`),
+		SecretGroup: 1,
+		MinEntropy:  3.5,
+	},
+}
+
+type secretsAllowlist struct {
+	paths        []string
+	pathRules    map[string][]string
+	fingerprints map[string]bool
+}
+
+// Load the allowlist for known false positives. Each line is one of:
+//
+//	path/glob              ignore every finding in matching files
+//	path/glob:rule-id      ignore a single rule in matching files
+//	fingerprint:<hex>      ignore one specific finding
+func loadSecretsAllowlist(filename string) secretsAllowlist {
+	allowlist := secretsAllowlist{pathRules: make(map[string][]string), fingerprints: make(map[string]bool)}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return allowlist
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "fingerprint:") {
+			allowlist.fingerprints[strings.TrimSpace(strings.TrimPrefix(line, "fingerprint:"))] = true
+			continue
+		}
+		if pattern, ruleID, found := strings.Cut(line, ":"); found {
+			allowlist.pathRules[pattern] = append(allowlist.pathRules[pattern], strings.TrimSpace(ruleID))
+			continue
+		}
+		allowlist.paths = append(allowlist.paths, line)
+	}
+
+	return allowlist
+}
+
+func (a secretsAllowlist) allows(finding SecretFinding) bool {
+	if a.fingerprints[finding.Fingerprint] {
+		return true
+	}
+	for _, pattern := range a.paths {
+		if matchesPathPattern(pattern, finding.File) {
+			return true
+		}
+	}
+	for pattern, ruleIDs := range a.pathRules {
+		if !matchesPathPattern(pattern, finding.File) {
+			continue
+		}
+		for _, ruleID := range ruleIDs {
+			if ruleID == finding.RuleID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func scanWorkingTreeForSecrets() SecretScanResult {
+	var result SecretScanResult
+	allowlist := loadSecretsAllowlist(secretsAllowlistFile)
+
+	for _, file := range listRepositoryFiles() {
+		info, err := os.Stat(file)
+		if err != nil || info.Size() > maxSecretScanSize {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil || isBinaryContent(content) {
+			continue
+		}
+		result.FilesScanned++
+
+		for _, finding := range scanContentForSecrets(file, content) {
+			if !allowlist.allows(finding) {
+				result.Findings = append(result.Findings, finding)
+			}
+		}
+	}
+
+	return result
+}
+
+func scanContentForSecrets(path string, content []byte) []SecretFinding {
+	var findings []SecretFinding
+
+	lineNumber := 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), maxSecretScanSize)
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if strings.Contains(line, inlineAllowMarker) {
+			continue
+		}
+
+		// Several rules can match the same value (e.g. a JWT assigned to "token"); report it once
+		seen := make(map[string]bool)
+		for _, rule := range secretRules {
+			for _, match := range rule.Pattern.FindAllStringSubmatch(line, -1) {
+				secret := match[rule.SecretGroup]
+				if seen[secret] {
+					continue
+				}
+				if rule.MinEntropy > 0 && !looksLikeRandomSecret(secret, rule.MinEntropy) {
+					continue
+				}
+				seen[secret] = true
+				findings = append(findings, SecretFinding{
+					File:        path,
+					Line:        lineNumber,
+					RuleID:      rule.ID,
+					Description: rule.Description,
+					Severity:    rule.Severity,
+					Secret:      maskSecret(secret),
+					Fingerprint: secretFingerprint(rule.ID, path, secret),
+					valueHash:   secretFingerprint(rule.ID, "", secret),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// Filter out references such as ${VAR} or config.apiKey that only look like assignments
+func looksLikeRandomSecret(value string, minEntropy float64) bool {
+	if strings.ContainsAny(value, "${}()<>") {
+		return false
+	}
+	if !strings.ContainsAny(value, "0123456789") || strings.IndexFunc(value, isASCIILetter) == -1 {
+		return false
+	}
+	return shannonEntropy(value) >= minEntropy
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range value {
+		counts[r]++
+	}
+	var entropy float64
+	length := float64(len([]rune(value)))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func maskSecret(secret string) string {
+	if strings.HasPrefix(secret, "-----BEGIN") {
+		return secret
+	}
+	visible := 4
+	if len(secret) <= 8 {
+		visible = 0
+	}
+	return secret[:visible] + strings.Repeat("*", 8)
+}
+
+func secretFingerprint(ruleID, path, secret string) string {
+	sum := sha256.Sum256([]byte(ruleID + "\x00" + path + "\x00" + secret))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func writeSecretScanResult(buffer *bytes.Buffer, result SecretScanResult) {
+	buffer.WriteString(fmt.Sprintf("Secret scan (%d files scanned):\n", result.FilesScanned))
+	if len(result.Findings) == 0 {
+		buffer.WriteString("  No secrets detected.\n")
+		return
+	}
+	for _, finding := range result.Findings {
+		buffer.WriteString(fmt.Sprintf("  [%s] %s:%d %s - %s (fingerprint %s)\n",
+			finding.Severity, finding.File, finding.Line, finding.Secret, finding.Description, finding.Fingerprint))
+	}
+	buffer.WriteString(fmt.Sprintf("  Add known false positives to %s (path glob, path:rule-id or fingerprint:<id>).\n", secretsAllowlistFile))
+}
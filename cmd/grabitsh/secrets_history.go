@@ -0,0 +1,321 @@
+package grabitsh
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanHistory     bool
+	scanUnreachable bool
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Scan the working tree, and optionally git history, for secrets",
+	Run:   runSecretsScan,
+}
+
+func init() {
+	secretsCmd.Flags().BoolVar(&scanHistory, "history", false, "Scan every blob reachable from all refs")
+	secretsCmd.Flags().BoolVar(&scanUnreachable, "unreachable", false, "Also scan unreachable objects (implies --history)")
+}
+
+type HistorySecretFinding struct {
+	SecretFinding
+	Commit      string `json:"commit,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Date        string `json:"date,omitempty"`
+	StillAtHEAD bool   `json:"still_at_head"`
+	Unreachable bool   `json:"unreachable"`
+}
+
+type HistoryScanResult struct {
+	CommitsScanned int                    `json:"commits_scanned"`
+	BlobsScanned   int                    `json:"blobs_scanned"`
+	Findings       []HistorySecretFinding `json:"findings"`
+}
+
+type historyBlob struct {
+	sha         string
+	path        string
+	commit      string
+	author      string
+	date        string
+	unreachable bool
+}
+
+func runSecretsScan(cmd *cobra.Command, args []string) {
+	var outputBuffer bytes.Buffer
+
+	outputBuffer.WriteString("### Secret Scan ###\n")
+	writeSecretScanResult(&outputBuffer, scanWorkingTreeForSecrets())
+
+	if scanHistory || scanUnreachable {
+		outputBuffer.WriteString("\n### Git History Secret Scan ###\n")
+		result, err := scanHistoryForSecrets(scanUnreachable)
+		if err != nil {
+			outputBuffer.WriteString(fmt.Sprintf("Error scanning git history: %v\n", err))
+		} else {
+			writeHistoryScanResult(&outputBuffer, result)
+		}
+	}
+
+	finalizeOutput(outputBuffer.String())
+}
+
+// Scan every blob reachable from any ref, reporting where each secret was first introduced
+func scanHistoryForSecrets(includeUnreachable bool) (HistoryScanResult, error) {
+	var result HistoryScanResult
+
+	blobs, commits, err := listHistoryBlobs()
+	if err != nil {
+		return result, err
+	}
+	result.CommitsScanned = commits
+
+	if includeUnreachable {
+		unreachable, err := listUnreachableBlobs()
+		if err != nil {
+			return result, err
+		}
+		blobs = append(blobs, unreachable...)
+	}
+
+	headBlobs, err := listHEADBlobs()
+	if err != nil {
+		return result, err
+	}
+
+	allowlist := loadSecretsAllowlist(secretsAllowlistFile)
+	atHEAD := make(map[string]bool)
+	reported := make(map[string]int)
+
+	err = readBlobs(blobs, func(blob historyBlob, content []byte) {
+		result.BlobsScanned++
+		for _, finding := range scanContentForSecrets(blob.path, content) {
+			if allowlist.allows(finding) {
+				continue
+			}
+			key := finding.RuleID + ":" + finding.valueHash
+			if headBlobs[blob.sha] {
+				atHEAD[key] = true
+			}
+			if _, ok := reported[key]; ok {
+				continue
+			}
+			reported[key] = len(result.Findings)
+			result.Findings = append(result.Findings, HistorySecretFinding{
+				SecretFinding: finding,
+				Commit:        blob.commit,
+				Author:        blob.author,
+				Date:          blob.date,
+				Unreachable:   blob.unreachable,
+			})
+		}
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for key, index := range reported {
+		result.Findings[index].StillAtHEAD = atHEAD[key]
+	}
+
+	return result, nil
+}
+
+// Walk all commits oldest first and record the first commit that introduced each blob
+func listHistoryBlobs() ([]historyBlob, int, error) {
+	out, err := runCommandOutput("git", "-c", "core.quotePath=false", "log", "--all", "--reverse", "--topo-order",
+		"-m", "--raw", "--no-abbrev", "--no-renames",
+		"--format=commit %H%x09%an <%ae>%x09%aI")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing git history: %w", err)
+	}
+
+	var blobs []historyBlob
+	seen := make(map[string]bool)
+	commits := make(map[string]bool)
+	var current historyBlob
+
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "commit ") {
+			fields := strings.SplitN(strings.TrimPrefix(line, "commit "), "\t", 3)
+			current = historyBlob{commit: fields[0]}
+			if len(fields) == 3 {
+				current.author, current.date = fields[1], fields[2]
+			}
+			commits[current.commit] = true
+			continue
+		}
+		if !strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		// :<old mode> <new mode> <old sha> <new sha> <status>\t<path>
+		meta, path, found := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !found || len(fields) < 5 || strings.HasPrefix(fields[1], "160000") {
+			continue
+		}
+		// Deleted files have an all-zero new sha; filtering them in git log would also drop the commit
+		sha := fields[3]
+		if seen[sha] || strings.Trim(sha, "0") == "" {
+			continue
+		}
+		seen[sha] = true
+
+		if strings.HasPrefix(path, `"`) {
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+		}
+		blob := current
+		blob.sha, blob.path = sha, path
+		blobs = append(blobs, blob)
+	}
+
+	return blobs, len(commits), nil
+}
+
+func listUnreachableBlobs() ([]historyBlob, error) {
+	out, err := runCommandOutput("git", "fsck", "--unreachable", "--no-reflogs", "--no-progress")
+	if err != nil {
+		return nil, fmt.Errorf("error listing unreachable objects: %w", err)
+	}
+
+	var blobs []historyBlob
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "unreachable" && fields[1] == "blob" {
+			blobs = append(blobs, historyBlob{
+				sha:         fields[2],
+				path:        fmt.Sprintf("(unreachable blob %s)", fields[2]),
+				unreachable: true,
+			})
+		}
+	}
+	return blobs, nil
+}
+
+func listHEADBlobs() (map[string]bool, error) {
+	blobs := make(map[string]bool)
+	out, err := runCommandOutput("git", "ls-tree", "-r", "-z", "HEAD")
+	if err != nil {
+		// A repository without commits has nothing at HEAD
+		return blobs, nil
+	}
+	for _, record := range strings.Split(out, "\x00") {
+		// <mode> <type> <sha>\t<path>
+		fields := strings.Fields(strings.SplitN(record, "\t", 2)[0])
+		if len(fields) == 3 && fields[1] == "blob" {
+			blobs[fields[2]] = true
+		}
+	}
+	return blobs, nil
+}
+
+// Stream blob contents through a single git cat-file --batch process
+func readBlobs(blobs []historyBlob, handle func(historyBlob, []byte)) error {
+	if len(blobs) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command("git", "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting git cat-file: %w", err)
+	}
+
+	go func() {
+		defer stdin.Close()
+		for _, blob := range blobs {
+			fmt.Fprintln(stdin, blob.sha)
+		}
+	}()
+
+	if err := readBatchOutput(bufio.NewReader(stdout), blobs, handle); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+
+	return cmd.Wait()
+}
+
+func readBatchOutput(reader *bufio.Reader, blobs []historyBlob, handle func(historyBlob, []byte)) error {
+	for _, blob := range blobs {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("error reading blob %s: %w", blob.sha, err)
+		}
+		// <sha> <type> <size>, or <sha> missing
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing blob header %q: %w", header, err)
+		}
+
+		content := make([]byte, size)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return fmt.Errorf("error reading blob %s: %w", blob.sha, err)
+		}
+		if _, err := reader.Discard(1); err != nil {
+			return err
+		}
+
+		if size <= maxSecretScanSize && !isBinaryContent(content) {
+			handle(blob, content)
+		}
+	}
+	return nil
+}
+
+func writeHistoryScanResult(buffer *bytes.Buffer, result HistoryScanResult) {
+	buffer.WriteString(fmt.Sprintf("Scanned %d unique blobs across %d commits.\n", result.BlobsScanned, result.CommitsScanned))
+	if len(result.Findings) == 0 {
+		buffer.WriteString("  No secrets found in history.\n")
+		return
+	}
+
+	for _, finding := range result.Findings {
+		state := "removed from HEAD (still present in history)"
+		if finding.StillAtHEAD {
+			state = "still present at HEAD"
+		}
+		buffer.WriteString(fmt.Sprintf("  [%s] %s %s - %s (fingerprint %s)\n",
+			finding.Severity, finding.File, finding.Secret, finding.Description, finding.Fingerprint))
+		if finding.Unreachable {
+			buffer.WriteString(fmt.Sprintf("      found in an unreachable object, %s\n", state))
+		} else {
+			buffer.WriteString(fmt.Sprintf("      introduced in %s by %s on %s (line %d), %s\n",
+				shortSHA(finding.Commit), finding.Author, finding.Date, finding.Line, state))
+		}
+	}
+	buffer.WriteString("  Secrets removed in later commits are still leaked: rotate them before publishing this repository.\n")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
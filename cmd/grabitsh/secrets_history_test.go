@@ -0,0 +1,125 @@
+package grabitsh
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Run git in the working directory with a fixed identity, returning its trimmed output
+func runTestGit(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "commit.gpgsign=false"}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com", "GIT_AUTHOR_DATE=2024-01-02T03:04:05Z",
+		"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com", "GIT_COMMITTER_DATE=2024-01-02T03:04:05Z",
+		"GIT_CONFIG_NOSYSTEM=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestScanHistoryForSecrets(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// Fixtures are split so the repository itself does not carry complete tokens
+	removed := "aws_access_key_id = AKIA" + "IOSFODNN7EXAMPLE\n"
+	kept := "token: gh" + "p_0123456789abcdefghijABCDEFGHIJ0123456789\n"
+	dangling := "xox" + "b-1234567890-abcdefghij\n"
+
+	dir := chdirTestFiles(t, map[string]string{"config.env": removed, "app.env": kept})
+	runTestGit(t, "init", "-q", dir)
+	runTestGit(t, "add", ".")
+	runTestGit(t, "commit", "-q", "-m", "add config")
+	introduced := runTestGit(t, "rev-parse", "HEAD")
+	if err := os.WriteFile("config.env", []byte("aws_access_key_id = ${AWS_ACCESS_KEY_ID}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The same key copied elsewhere later is still reported once, at the commit that first added it
+	if err := os.WriteFile("copy.env", []byte("# copied\n"+removed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, "add", ".")
+	runTestGit(t, "commit", "-q", "-m", "move the key out")
+	if err := os.Remove("copy.env"); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, "commit", "-q", "-am", "drop the copy")
+	if err := os.WriteFile("stash.txt", []byte(dangling), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, "hash-object", "-w", "stash.txt")
+	if err := os.Remove("stash.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scanHistoryForSecrets(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CommitsScanned != 3 {
+		t.Errorf("CommitsScanned = %d, want 3", result.CommitsScanned)
+	}
+	byRule := make(map[string]HistorySecretFinding)
+	for _, finding := range result.Findings {
+		if _, ok := byRule[finding.RuleID]; ok {
+			t.Errorf("%s reported more than once", finding.RuleID)
+		}
+		byRule[finding.RuleID] = finding
+	}
+	if len(byRule) != 2 {
+		t.Fatalf("findings %+v, want the AWS key and the GitHub token", result.Findings)
+	}
+	if finding := byRule["aws-access-key-id"]; finding.File != "config.env" || finding.Commit != introduced || finding.StillAtHEAD ||
+		finding.Author != "Alice <alice@example.com>" || !strings.HasPrefix(finding.Date, "2024-01-02") {
+		t.Errorf("removed key = %+v, want config.env in %s, not at HEAD", finding, introduced)
+	}
+	if finding := byRule["github-token"]; finding.File != "app.env" || !finding.StillAtHEAD {
+		t.Errorf("kept token = %+v, want app.env still at HEAD", finding)
+	}
+
+	result, err = scanHistoryForSecrets(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, finding := range result.Findings {
+		if finding.RuleID == "slack-token" {
+			found = true
+			if !finding.Unreachable || finding.Commit != "" || !strings.HasPrefix(finding.File, "(unreachable blob ") {
+				t.Errorf("dangling blob finding = %+v", finding)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("findings %+v, want the token in the unreachable blob", result.Findings)
+	}
+}
+
+func TestReadBatchOutput(t *testing.T) {
+	output := "aaaa blob 12\nsecret = one\n" +
+		"bbbb missing\n" +
+		"cccc blob 4\nab\x00c\n" +
+		"dddd blob 0\n\n"
+	blobs := []historyBlob{{sha: "aaaa"}, {sha: "bbbb"}, {sha: "cccc"}, {sha: "dddd"}}
+	var handled []string
+	err := readBatchOutput(bufio.NewReader(strings.NewReader(output)), blobs, func(blob historyBlob, content []byte) {
+		handled = append(handled, blob.sha+"="+string(content))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Missing objects are skipped and binary content is not handed to the scanner
+	if got := strings.Join(handled, ","); got != "aaaa=secret = one,dddd=" {
+		t.Errorf("handled %q", got)
+	}
+
+	if err := readBatchOutput(bufio.NewReader(strings.NewReader("aaaa blob 100\nshort\n")), blobs[:1], func(historyBlob, []byte) {}); err == nil {
+		t.Error("a truncated blob should be an error")
+	}
+}
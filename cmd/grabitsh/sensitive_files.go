@@ -0,0 +1,175 @@
+package grabitsh
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type SensitiveFile struct {
+	Path     string `json:"path"`
+	Category string `json:"category"`
+	// tracked, ignored, untracked, or unknown outside a git repository
+	Status string `json:"status"`
+}
+
+type sensitiveFilePattern struct {
+	pattern  string
+	category string
+}
+
+var defaultSensitiveFilePatterns = []sensitiveFilePattern{
+	{".env", "environment file"},
+	{".env.*", "environment file"},
+	{"*.env", "environment file"},
+	{"id_rsa", "private key"},
+	{"id_dsa", "private key"},
+	{"id_ecdsa", "private key"},
+	{"id_ed25519", "private key"},
+	{"*.pem", "private key or certificate"},
+	{"*.key", "private key"},
+	{"*.ppk", "private key"},
+	{"*.p12", "keystore"},
+	{"*.pfx", "keystore"},
+	{"*.jks", "keystore"},
+	{"*.keystore", "keystore"},
+	{"*.bks", "keystore"},
+	{"kubeconfig", "kubeconfig"},
+	{"*.kubeconfig", "kubeconfig"},
+	{"**/.kube/config", "kubeconfig"},
+	{"credentials.json", "cloud credentials"},
+	{"client_secret*.json", "cloud credentials"},
+	{"**/.aws/credentials", "cloud credentials"},
+	{"*.tfstate", "terraform state"},
+	{"*.tfstate.backup", "terraform state"},
+	{".netrc", "credentials file"},
+	{".git-credentials", "credentials file"},
+	{".pypirc", "credentials file"},
+	{".htpasswd", "credentials file"},
+}
+
+var defaultSensitiveFileExcludes = []string{
+	"*.example", "*.sample", "*.template", "*.dist", ".env.*.example", ".env.*.sample",
+}
+
+// Walk the whole tree for files matching the sensitive patterns and report whether git tracks or ignores each one
+func detectSensitiveFiles(config SensitiveFilesConfig) []SensitiveFile {
+	patterns := defaultSensitiveFilePatterns
+	if config.ReplaceDefaults {
+		patterns = nil
+	}
+	for _, pattern := range config.Patterns {
+		patterns = append(patterns, sensitiveFilePattern{pattern, "configured pattern"})
+	}
+	excludes := append(append([]string{}, defaultSensitiveFileExcludes...), config.Exclude...)
+
+	var files []SensitiveFile
+	_ = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != "." && (info.Name() == ".git" || shouldExcludeDir(info.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		path = filepath.ToSlash(path)
+		for _, exclude := range excludes {
+			if matchesPathPattern(exclude, path) {
+				return nil
+			}
+		}
+		for _, p := range patterns {
+			if matchesPathPattern(p.pattern, path) {
+				files = append(files, SensitiveFile{Path: path, Category: p.category})
+				break
+			}
+		}
+		return nil
+	})
+
+	classifyGitStatus(files)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+func classifyGitStatus(files []SensitiveFile) {
+	if len(files) == 0 {
+		return
+	}
+
+	out, err := runCommandOutput("git", "ls-files", "-z")
+	if err != nil {
+		for i := range files {
+			files[i].Status = "unknown"
+		}
+		return
+	}
+	tracked := make(map[string]bool)
+	for _, file := range strings.Split(out, "\x00") {
+		tracked[file] = true
+	}
+
+	var candidates []string
+	for _, file := range files {
+		if !tracked[file.Path] {
+			candidates = append(candidates, file.Path)
+		}
+	}
+	ignored := make(map[string]bool)
+	if len(candidates) > 0 {
+		cmd := exec.Command("git", "check-ignore", "--stdin", "-z")
+		cmd.Stdin = strings.NewReader(strings.Join(candidates, "\x00") + "\x00")
+		// check-ignore exits 1 when nothing is ignored, so only the output matters
+		out, _ := cmd.Output()
+		for _, file := range strings.Split(string(out), "\x00") {
+			ignored[file] = true
+		}
+	}
+
+	for i, file := range files {
+		switch {
+		case tracked[file.Path]:
+			files[i].Status = "tracked"
+		case ignored[file.Path]:
+			files[i].Status = "ignored"
+		default:
+			files[i].Status = "untracked"
+		}
+	}
+}
+
+func writeSensitiveFiles(buffer *bytes.Buffer, files []SensitiveFile) {
+	buffer.WriteString("Sensitive files:\n")
+	if len(files) == 0 {
+		buffer.WriteString("  No sensitive files detected.\n")
+		return
+	}
+
+	for _, file := range files {
+		note := ""
+		switch file.Status {
+		case "tracked":
+			note = " - committed to the repository"
+		case "untracked":
+			note = " - not ignored, could be committed by accident"
+		}
+		buffer.WriteString(fmt.Sprintf("  [%s] %s (%s)%s\n", file.Status, file.Path, file.Category, note))
+	}
+
+	for _, file := range files {
+		if file.Category != "environment file" {
+			continue
+		}
+		content, err := os.ReadFile(file.Path)
+		if err == nil {
+			buffer.WriteString(fmt.Sprintf("Sanitized %s:\n%s\n", file.Path, sanitizeEnvFile(string(content))))
+		}
+	}
+}
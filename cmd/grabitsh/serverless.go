@@ -0,0 +1,662 @@
+package grabitsh
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type ServerlessFunction struct {
+	Name     string   `json:"name"`
+	Handler  string   `json:"handler,omitempty"`
+	Runtime  string   `json:"runtime,omitempty"`
+	Triggers []string `json:"triggers,omitempty"`
+	// Source file for functions found by scanning CDK or Pulumi programs
+	File string `json:"file,omitempty"`
+}
+
+type ServerlessStage struct {
+	Name string `json:"name"`
+	// Configuration keys only, values may be secrets
+	Config []string `json:"config,omitempty"`
+}
+
+// ServerlessApp is a Serverless Framework service, SAM/CloudFormation template, CDK app or Pulumi project
+type ServerlessApp struct {
+	Framework string               `json:"framework"`
+	File      string               `json:"file"`
+	Name      string               `json:"name,omitempty"`
+	Details   []string             `json:"details,omitempty"`
+	Functions []ServerlessFunction `json:"functions,omitempty"`
+	// Triggers found in CDK/Pulumi source that cannot be tied to a single function
+	Triggers      []string          `json:"triggers,omitempty"`
+	Stages        []ServerlessStage `json:"stages,omitempty"`
+	ResourceTypes map[string]int    `json:"resource_types,omitempty"`
+	Errors        []string          `json:"errors,omitempty"`
+}
+
+const cloudFormationMaxTemplateSize = 1 << 20
+
+var (
+	serverlessFileNames = map[string]bool{"serverless.yml": true, "serverless.yaml": true, "serverless.json": true}
+
+	programSourceExtensions = map[string]bool{".ts": true, ".js": true, ".mjs": true, ".py": true, ".go": true, ".java": true, ".cs": true}
+	programExcludedDirs     = map[string]bool{"node_modules": true, "cdk.out": true, "venv": true, ".venv": true, "__pycache__": true, "obj": true, "target": true}
+
+	cdkFunctionRegex    = regexp.MustCompile(`(\w*Function)(?:\.Builder\.create)?\s*\(\s*(?:this|self|scope|stack)\s*,\s*["']([^"']+)["']`)
+	cdkStackRegex       = regexp.MustCompile(`(\w+Stack)\s*\(\s*(?:app|scope|this|self)\s*,\s*["']([^"']+)["']`)
+	pulumiFunctionRegex = regexp.MustCompile(`(?:lambda_?\.(?:New)?Function|lambda\.CallbackFunction|lambda_\.Function)\s*\(\s*(?:ctx\s*,\s*)?["']([^"']+)["']`)
+	programHandlerRegex = regexp.MustCompile(`(?i)\bhandler\s*[:=]\s*["']([^"']+)["']`)
+	programEntryRegex   = regexp.MustCompile(`\bentry\s*[:=]\s*[^,\n]*["']([^"']+)["']`)
+	// Top-level keys every CloudFormation template declares, in YAML or JSON form
+	cloudFormationKeyRegex = regexp.MustCompile(`(?m)(?:^|[{,])\s*["']?(?:AWSTemplateFormatVersion|Resources)["']?\s*:`)
+	programRuntimeRegex    = regexp.MustCompile(`(?i)\bruntime\s*[:=]\s*(?:[\w.]*Runtime\.(\w+)|["']([\w.]+)["'])`)
+
+	// Source patterns that reveal how functions are invoked in CDK and Pulumi programs
+	programTriggerPatterns = []struct {
+		pattern *regexp.Regexp
+		trigger string
+	}{
+		{regexp.MustCompile(`LambdaRestApi|LambdaIntegration|HttpLambdaIntegration|HttpApi\(|apigateway\.RestAPI|apigatewayv2\.`), "http"},
+		{regexp.MustCompile(`SqsEventSource|sqs\.Queue.*onEvent|\.onEvent\(`), "queue (SQS)"},
+		{regexp.MustCompile(`SnsEventSource|LambdaSubscription|onEvent.*sns`), "topic (SNS)"},
+		{regexp.MustCompile(`S3EventSource|onObjectCreated|addEventNotification`), "storage (S3)"},
+		{regexp.MustCompile(`DynamoEventSource|KinesisEventSource|EventSourceMapping`), "stream"},
+		{regexp.MustCompile(`Schedule\.(?:rate|cron|expression)\(|onSchedule\(|scheduleExpression`), "schedule"},
+		{regexp.MustCompile(`events\.Rule\(|EventBridge|cloudwatch\.EventRule`), "event rule"},
+	}
+)
+
+// Find every serverless / IaC application definition in the repository
+func findServerlessApps() []*ServerlessApp {
+	var apps []*ServerlessApp
+	for _, path := range findRepositoryFiles(func(name string) bool { return serverlessFileNames[name] }) {
+		apps = append(apps, parseServerlessFramework(path))
+	}
+	for _, path := range findRepositoryFiles(isCloudFormationCandidate) {
+		if strings.Contains("/"+path, "/cdk.out/") {
+			continue
+		}
+		if app := parseCloudFormationTemplate(path); app != nil {
+			apps = append(apps, app)
+		}
+	}
+	for _, path := range findRepositoryFiles(func(name string) bool { return name == "cdk.json" }) {
+		apps = append(apps, parseCDKApp(path))
+	}
+	for _, path := range findRepositoryFiles(func(name string) bool { return name == "Pulumi.yaml" || name == "Pulumi.yml" }) {
+		apps = append(apps, parsePulumiProject(path))
+	}
+	return apps
+}
+
+func isCloudFormationCandidate(name string) bool {
+	if serverlessFileNames[name] || name == "package.json" || name == "package-lock.json" || name == "tsconfig.json" {
+		return false
+	}
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml" || ext == ".json" || ext == ".template"
+}
+
+func readStructuredFile(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	// YAML is a superset of JSON; short-form intrinsic tags (!Ref, !GetAtt) decode to their plain values
+	if err := yaml.Unmarshal(content, &value); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return yamlMap(normalizeYAML(value)), nil
+}
+
+func parseServerlessFramework(path string) *ServerlessApp {
+	app := &ServerlessApp{Framework: "Serverless Framework", File: path}
+	config, err := readStructuredFile(path)
+	if err != nil {
+		app.Errors = append(app.Errors, err.Error())
+		return app
+	}
+
+	app.Name = yamlString(config["service"])
+	if service := yamlMap(config["service"]); service != nil {
+		app.Name = yamlString(service["name"])
+	}
+	provider := yamlMap(config["provider"])
+	defaultRuntime := yamlString(provider["runtime"])
+	if provider != nil {
+		details := []string{"provider " + orDash(yamlString(provider["name"]))}
+		if defaultRuntime != "" {
+			details = append(details, "runtime "+defaultRuntime)
+		}
+		if stage := yamlString(provider["stage"]); stage != "" {
+			details = append(details, "default stage "+stage)
+		}
+		if region := yamlString(provider["region"]); region != "" {
+			details = append(details, "region "+region)
+		}
+		app.Details = append(app.Details, strings.Join(details, ", "))
+	}
+	if plugins := yamlKeysOrItems(config["plugins"]); len(plugins) > 0 {
+		app.Details = append(app.Details, "plugins: "+strings.Join(plugins, ", "))
+	}
+
+	functions := yamlMap(config["functions"])
+	for _, name := range sortedKeys(functions) {
+		definition := yamlMap(functions[name])
+		function := ServerlessFunction{
+			Name:    name,
+			Handler: yamlString(definition["handler"]),
+			Runtime: orDefault(yamlString(definition["runtime"]), defaultRuntime),
+		}
+		if function.Handler == "" && definition["image"] != nil {
+			function.Handler = "image " + compactValue(definition["image"])
+		}
+		for _, item := range yamlList(definition["events"]) {
+			for eventType, event := range yamlMap(item) {
+				function.Triggers = append(function.Triggers, serverlessEventTrigger(eventType, event))
+			}
+		}
+		app.Functions = append(app.Functions, function)
+	}
+
+	// Serverless v3 stage parameters, plus per-stage config files next to the service
+	params := yamlMap(config["params"])
+	for _, stage := range sortedKeys(params) {
+		app.Stages = append(app.Stages, ServerlessStage{Name: stage, Config: yamlKeysOrItems(yamlMap(params[stage]))})
+	}
+	for _, stage := range yamlKeysOrItems(yamlMap(config["custom"])["stages"]) {
+		app.addStage(stage, nil)
+	}
+	dir := filepath.Dir(path)
+	for _, pattern := range []string{"config.*.yml", "config.*.yaml", "config.*.json", ".env.*"} {
+		files, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, file := range files {
+			base := filepath.Base(file)
+			stage := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(base, "config."), ".env."), filepath.Ext(base))
+			if strings.HasPrefix(base, ".env.") {
+				stage = strings.TrimPrefix(base, ".env.")
+			}
+			app.addStage(stage, []string{"file " + filepath.ToSlash(file)})
+		}
+	}
+
+	app.ResourceTypes = cloudFormationResourceTypes(yamlMap(yamlMap(config["resources"])["Resources"]))
+	return app
+}
+
+func (app *ServerlessApp) addStage(name string, config []string) {
+	for i := range app.Stages {
+		if app.Stages[i].Name == name {
+			app.Stages[i].Config = append(app.Stages[i].Config, config...)
+			return
+		}
+	}
+	app.Stages = append(app.Stages, ServerlessStage{Name: name, Config: config})
+}
+
+func serverlessEventTrigger(eventType string, event interface{}) string {
+	settings := yamlMap(event)
+	switch eventType {
+	case "http", "httpApi":
+		if settings != nil {
+			return strings.TrimSpace(fmt.Sprintf("%s %s %s", eventType, strings.ToUpper(yamlString(settings["method"])), yamlString(settings["path"])))
+		}
+		return eventType + " " + yamlString(event)
+	case "schedule":
+		if settings != nil {
+			return "schedule " + compactValue(settings["rate"])
+		}
+	case "s3":
+		if settings != nil {
+			return strings.TrimSpace(fmt.Sprintf("s3 %s %s", compactValue(settings["bucket"]), yamlString(settings["event"])))
+		}
+	case "sqs", "sns", "stream", "kafka", "activemq", "rabbitmq", "msk":
+		if settings != nil {
+			return eventType + " " + compactValue(orFirst(settings, "arn", "topicName", "queue", "topic"))
+		}
+	case "eventBridge", "cloudwatchEvent":
+		if settings != nil {
+			if schedule := settings["schedule"]; schedule != nil {
+				return eventType + " schedule " + compactValue(schedule)
+			}
+			return eventType + " pattern " + compactValue(settings["pattern"])
+		}
+	case "websocket":
+		if settings != nil {
+			return "websocket " + yamlString(settings["route"])
+		}
+	}
+	if settings != nil {
+		return eventType
+	}
+	return eventType + " " + compactValue(event)
+}
+
+func orFirst(settings map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := settings[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// Short single-line rendering of a config value, resolving CloudFormation intrinsic maps
+func compactValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, compactValue(item))
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		if ref := cloudFormationRef(v); ref != "" {
+			return ref
+		}
+		content, _ := json.Marshal(v)
+		return truncateLine(string(content), 80)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// Logical ID referenced by Ref / Fn::GetAtt (long or short form), or the plain string
+func cloudFormationRef(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSuffix(v, ".Arn")
+	case []interface{}:
+		if len(v) > 0 {
+			return yamlString(v[0])
+		}
+	case map[string]interface{}:
+		if ref, ok := v["Ref"]; ok {
+			return yamlString(ref)
+		}
+		if attribute, ok := v["Fn::GetAtt"]; ok {
+			return cloudFormationRef(attribute)
+		}
+		if sub, ok := v["Fn::Sub"]; ok {
+			return compactValue(sub)
+		}
+	}
+	return ""
+}
+
+func cloudFormationResourceTypes(resources map[string]interface{}) map[string]int {
+	if len(resources) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, resource := range resources {
+		if resourceType := yamlString(yamlMap(resource)["Type"]); resourceType != "" {
+			counts[resourceType]++
+		}
+	}
+	return counts
+}
+
+// Parse SAM and plain CloudFormation templates; other YAML/JSON files return nil
+func parseCloudFormationTemplate(path string) *ServerlessApp {
+	// Templates are at most 1 MB, so larger data files are not read
+	if info, err := os.Stat(path); err != nil || info.Size() > cloudFormationMaxTemplateSize {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || !cloudFormationKeyRegex.Match(content) {
+		return nil
+	}
+	template, err := readStructuredFile(path)
+	resources := yamlMap(template["Resources"])
+	if err != nil || resources == nil {
+		return nil
+	}
+
+	app := &ServerlessApp{Framework: "CloudFormation", File: path, Name: yamlString(template["Description"])}
+	for _, transform := range yamlKeysOrItems(template["Transform"]) {
+		if strings.HasPrefix(transform, "AWS::Serverless") {
+			app.Framework = "AWS SAM"
+		}
+	}
+	if parameters := yamlKeysOrItems(yamlMap(template["Parameters"])); len(parameters) > 0 {
+		app.Details = append(app.Details, "parameters: "+strings.Join(parameters, ", "))
+	}
+	app.ResourceTypes = cloudFormationResourceTypes(resources)
+
+	globals := yamlMap(yamlMap(template["Globals"])["Function"])
+	byLogicalID := make(map[string]int)
+	byName := make(map[string]int)
+	for _, logicalID := range sortedKeys(resources) {
+		resource := yamlMap(resources[logicalID])
+		properties := yamlMap(resource["Properties"])
+		switch yamlString(resource["Type"]) {
+		case "AWS::Serverless::Function", "AWS::Lambda::Function":
+			function := ServerlessFunction{
+				Name:    logicalID,
+				Handler: orDefault(yamlString(properties["Handler"]), yamlString(globals["Handler"])),
+				Runtime: orDefault(yamlString(properties["Runtime"]), yamlString(globals["Runtime"])),
+			}
+			if yamlString(properties["PackageType"]) == "Image" {
+				function.Handler = "container image"
+			}
+			events := yamlMap(properties["Events"])
+			for _, eventName := range sortedKeys(events) {
+				function.Triggers = append(function.Triggers, samEventTrigger(yamlMap(events[eventName])))
+			}
+			byLogicalID[logicalID] = len(app.Functions)
+			if name := yamlString(properties["FunctionName"]); name != "" {
+				byName[name] = len(app.Functions)
+			}
+			app.Functions = append(app.Functions, function)
+		}
+	}
+
+	// Plain CloudFormation wires triggers through separate resources pointing at the function
+	attach := func(target interface{}, trigger string) {
+		ref := cloudFormationRef(target)
+		if index, ok := byLogicalID[ref]; ok {
+			app.Functions[index].Triggers = append(app.Functions[index].Triggers, trigger)
+		} else if index, ok := byName[ref]; ok {
+			app.Functions[index].Triggers = append(app.Functions[index].Triggers, trigger)
+		}
+	}
+	for _, logicalID := range sortedKeys(resources) {
+		resource := yamlMap(resources[logicalID])
+		properties := yamlMap(resource["Properties"])
+		switch yamlString(resource["Type"]) {
+		case "AWS::Lambda::EventSourceMapping":
+			attach(properties["FunctionName"], "event source "+compactValue(properties["EventSourceArn"]))
+		case "AWS::Events::Rule":
+			trigger := "event rule " + logicalID
+			if schedule := yamlString(properties["ScheduleExpression"]); schedule != "" {
+				trigger = "schedule " + schedule
+			}
+			for _, target := range yamlList(properties["Targets"]) {
+				attach(yamlMap(target)["Arn"], trigger)
+			}
+		case "AWS::Lambda::Permission":
+			principal := strings.TrimSuffix(yamlString(properties["Principal"]), ".amazonaws.com")
+			attach(properties["FunctionName"], "invoked by "+principal)
+		}
+	}
+
+	if app.Framework == "AWS SAM" {
+		app.loadSAMConfig(filepath.Dir(path))
+	} else if len(app.Functions) == 0 && !strings.Contains(string(content), "AWSTemplateFormatVersion") {
+		// Without the version marker and any functions this is most likely not a template
+		return nil
+	}
+	return app
+}
+
+func samEventTrigger(event map[string]interface{}) string {
+	properties := yamlMap(event["Properties"])
+	eventType := yamlString(event["Type"])
+	switch eventType {
+	case "Api", "HttpApi":
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", strings.ToLower(eventType), strings.ToUpper(yamlString(properties["Method"])), yamlString(properties["Path"])))
+	case "Schedule", "ScheduleV2":
+		return "schedule " + compactValue(orFirst(properties, "Schedule", "ScheduleExpression"))
+	case "SQS":
+		return "sqs " + compactValue(properties["Queue"])
+	case "SNS":
+		return "sns " + compactValue(properties["Topic"])
+	case "S3":
+		return "s3 " + compactValue(properties["Bucket"])
+	case "DynamoDB", "Kinesis", "MSK":
+		return strings.ToLower(eventType) + " stream " + compactValue(properties["Stream"])
+	case "EventBridgeRule", "CloudWatchEvent":
+		return "event rule " + compactValue(properties["Pattern"])
+	}
+	return strings.ToLower(eventType)
+}
+
+// samconfig.toml environments act as stages, each with its own deploy parameters
+func (app *ServerlessApp) loadSAMConfig(dir string) {
+	config, err := readTOMLFile(filepath.Join(dir, "samconfig.toml"))
+	if err != nil {
+		return
+	}
+	for _, environment := range sortedKeys(config) {
+		table := yamlMap(config[environment])
+		if table == nil || environment == "version" {
+			continue
+		}
+		var keys []string
+		for _, command := range sortedKeys(table) {
+			parameters := yamlMap(yamlMap(table[command])["parameters"])
+			for _, key := range sortedKeys(parameters) {
+				keys = appendUnique(keys, key)
+			}
+		}
+		app.Stages = append(app.Stages, ServerlessStage{Name: environment, Config: keys})
+	}
+}
+
+func parseCDKApp(path string) *ServerlessApp {
+	app := &ServerlessApp{Framework: "AWS CDK", File: path}
+	config, err := readStructuredFile(path)
+	if err != nil {
+		app.Errors = append(app.Errors, err.Error())
+		return app
+	}
+	command := yamlString(config["app"])
+	app.Details = append(app.Details, "app command: "+orDash(command))
+
+	dir := filepath.Dir(path)
+	var entry string
+	for _, token := range strings.Fields(command) {
+		if programSourceExtensions[filepath.Ext(token)] && fileExists(filepath.Join(dir, token)) {
+			entry = filepath.ToSlash(filepath.Join(dir, token))
+		}
+	}
+	if entry != "" {
+		app.Details = append(app.Details, "entry: "+entry)
+	}
+
+	// Context keys other than feature flags usually carry per-environment settings
+	context := yamlMap(config["context"])
+	for _, key := range sortedKeys(context) {
+		if strings.HasPrefix(key, "@aws-cdk") || strings.HasPrefix(key, "aws-cdk:") {
+			continue
+		}
+		var keys []string
+		if values := yamlMap(context[key]); values != nil {
+			keys = sortedKeys(values)
+		}
+		app.Stages = append(app.Stages, ServerlessStage{Name: key, Config: keys})
+	}
+
+	app.scanProgram(dir, cdkFunctionRegex, 2)
+	return app
+}
+
+func parsePulumiProject(path string) *ServerlessApp {
+	app := &ServerlessApp{Framework: "Pulumi", File: path}
+	project, err := readStructuredFile(path)
+	if err != nil {
+		app.Errors = append(app.Errors, err.Error())
+		return app
+	}
+	app.Name = yamlString(project["name"])
+	runtime := yamlString(project["runtime"])
+	if settings := yamlMap(project["runtime"]); settings != nil {
+		runtime = yamlString(settings["name"])
+	}
+	app.Details = append(app.Details, "runtime "+orDash(runtime))
+	if description := yamlString(project["description"]); description != "" {
+		app.Details = append(app.Details, description)
+	}
+
+	dir := filepath.Dir(path)
+	stacks, _ := filepath.Glob(filepath.Join(dir, "Pulumi.*.y*ml"))
+	sort.Strings(stacks)
+	for _, stackFile := range stacks {
+		stack := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(stackFile), "Pulumi."), filepath.Ext(stackFile))
+		settings, err := readStructuredFile(stackFile)
+		if err != nil {
+			app.Errors = append(app.Errors, err.Error())
+			continue
+		}
+		config := yamlMap(settings["config"])
+		var keys []string
+		for _, key := range sortedKeys(config) {
+			if yamlMap(config[key])["secure"] != nil {
+				key += " (secret)"
+			}
+			keys = append(keys, key)
+		}
+		app.Stages = append(app.Stages, ServerlessStage{Name: stack, Config: keys})
+	}
+
+	programDir := dir
+	if main := yamlString(project["main"]); main != "" {
+		programDir = filepath.Join(dir, main)
+	}
+	app.scanProgram(programDir, pulumiFunctionRegex, 1)
+	return app
+}
+
+// Scan CDK / Pulumi program sources for function constructs, their handlers and runtimes, and trigger wiring
+func (app *ServerlessApp) scanProgram(dir string, functionRegex *regexp.Regexp, nameGroup int) {
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != dir && (programExcludedDirs[info.Name()] || strings.HasPrefix(info.Name(), ".") || shouldExcludeDir(info.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !programSourceExtensions[filepath.Ext(path)] || strings.HasSuffix(path, ".d.ts") {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+
+		var lines []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		relative := filepath.ToSlash(path)
+
+		for i, line := range lines {
+			if match := cdkStackRegex.FindStringSubmatch(line); match != nil && functionRegex == cdkFunctionRegex {
+				app.Details = appendUnique(app.Details, fmt.Sprintf("stack %s (%s)", match[2], match[1]))
+			}
+			match := functionRegex.FindStringSubmatch(line)
+			if match == nil {
+				for _, trigger := range programTriggerPatterns {
+					if trigger.pattern.MatchString(line) {
+						app.Triggers = appendUnique(app.Triggers, fmt.Sprintf("%s (%s)", trigger.trigger, relative))
+					}
+				}
+				continue
+			}
+
+			function := ServerlessFunction{Name: match[nameGroup], File: fmt.Sprintf("%s:%d", relative, i+1)}
+			// Construct properties follow within the next few lines
+			end := i + 15
+			if end > len(lines) {
+				end = len(lines)
+			}
+			for _, next := range lines[i:end] {
+				if handler := programHandlerRegex.FindStringSubmatch(next); handler != nil && function.Handler == "" {
+					function.Handler = handler[1]
+				}
+				if entry := programEntryRegex.FindStringSubmatch(next); entry != nil && function.Handler == "" {
+					function.Handler = entry[1]
+				}
+				if runtime := programRuntimeRegex.FindStringSubmatch(next); runtime != nil && function.Runtime == "" {
+					function.Runtime = strings.ToLower(runtime[1] + runtime[2])
+				}
+			}
+			app.Functions = append(app.Functions, function)
+		}
+		return nil
+	})
+}
+
+// Frameworks of the applications that define at least one function
+func serverlessFrameworks() []string {
+	var frameworks []string
+	for _, app := range findServerlessApps() {
+		if len(app.Functions) > 0 {
+			frameworks = appendUnique(frameworks, app.Framework)
+		}
+	}
+	return frameworks
+}
+
+func writeServerlessApps(output io.StringWriter, apps []*ServerlessApp) {
+	for _, app := range apps {
+		title := fmt.Sprintf("%s: %s", app.Framework, app.File)
+		if app.Name != "" {
+			title = fmt.Sprintf("%s app %q: %s", app.Framework, app.Name, app.File)
+		}
+		output.WriteString(title + "\n")
+		for _, detail := range app.Details {
+			output.WriteString("  " + detail + "\n")
+		}
+
+		if len(app.Functions) > 0 {
+			output.WriteString(fmt.Sprintf("  Functions (%d):\n", len(app.Functions)))
+			for _, function := range app.Functions {
+				line := fmt.Sprintf("    - %s: %s", function.Name, orDash(function.Handler))
+				if function.Runtime != "" {
+					line += " [" + function.Runtime + "]"
+				}
+				if function.File != "" {
+					line += " (" + function.File + ")"
+				}
+				output.WriteString(line + "\n")
+				if len(function.Triggers) > 0 {
+					output.WriteString("        triggers: " + strings.Join(function.Triggers, "; ") + "\n")
+				}
+			}
+		}
+		if len(app.Triggers) > 0 {
+			output.WriteString("  Triggers in source: " + strings.Join(app.Triggers, ", ") + "\n")
+		}
+		if len(app.Stages) > 0 {
+			output.WriteString("  Stages:\n")
+			for _, stage := range app.Stages {
+				line := "    - " + stage.Name
+				if len(stage.Config) > 0 {
+					line += ": " + strings.Join(stage.Config, ", ")
+				}
+				output.WriteString(line + "\n")
+			}
+		}
+		if len(app.ResourceTypes) > 0 {
+			var types []string
+			for _, resourceType := range sortedKeys(app.ResourceTypes) {
+				types = append(types, fmt.Sprintf("%s x%d", resourceType, app.ResourceTypes[resourceType]))
+			}
+			output.WriteString("  Resources: " + strings.Join(types, ", ") + "\n")
+		}
+		for _, parseError := range app.Errors {
+			output.WriteString("  Error: " + parseError + "\n")
+		}
+		output.WriteString("\n")
+	}
+}
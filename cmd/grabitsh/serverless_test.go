@@ -0,0 +1,113 @@
+package grabitsh
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// One line per app and function: "framework file name", then "  function handler runtime [triggers]"
+func serverlessSummary(apps []*ServerlessApp) []string {
+	var summary []string
+	for _, app := range apps {
+		summary = append(summary, fmt.Sprintf("%s %s %s", app.Framework, app.File, app.Name))
+		for _, function := range app.Functions {
+			summary = append(summary, fmt.Sprintf("  %s %s %s [%s]", function.Name, function.Handler, function.Runtime, strings.Join(function.Triggers, "; ")))
+		}
+	}
+	return summary
+}
+
+func TestFindServerlessApps(t *testing.T) {
+	chdirTestFiles(t, map[string]string{
+		"api/serverless.yml": `service: orders
+provider:
+  name: aws
+  runtime: nodejs20.x
+  region: eu-west-1
+functions:
+  create:
+    handler: src/create.handler
+    events:
+      - httpApi:
+          method: post
+          path: /orders
+  nightly:
+    handler: src/nightly.handler
+    runtime: python3.12
+    events:
+      - schedule: rate(1 day)
+params:
+  prod:
+    DB_URL: secret
+`,
+		"sam/template.yaml": `AWSTemplateFormatVersion: "2010-09-09"
+Transform: AWS::Serverless-2016-10-31
+Description: thumbnails
+Globals:
+  Function:
+    Runtime: python3.12
+Resources:
+  Resize:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+      Events:
+        Upload:
+          Type: S3
+          Properties:
+            Bucket: !Ref Images
+  Images:
+    Type: AWS::S3::Bucket
+`,
+		"cfn/stack.json": `{"Resources": {
+  "Worker": {"Type": "AWS::Lambda::Function", "Properties": {"Handler": "index.handler", "Runtime": "nodejs20.x"}},
+  "Tick": {"Type": "AWS::Events::Rule", "Properties": {"ScheduleExpression": "rate(5 minutes)", "Targets": [{"Arn": {"Fn::GetAtt": ["Worker", "Arn"]}}]}}
+}}`,
+		// Mentions AWS resource types but is not a template
+		"docs/iam.yaml":                        "notes:\n  - AWS::IAM::Role is created by hand\n",
+		"config/app.json":                      `{"name": "app", "resources": ["AWS::S3::Bucket"]}`,
+		"node_modules/pkg/template.yaml":       "AWSTemplateFormatVersion: \"2010-09-09\"\nResources:\n  F:\n    Type: AWS::Lambda::Function\n",
+		"vendor/example.com/mod/template.yaml": "AWSTemplateFormatVersion: \"2010-09-09\"\nResources:\n  F:\n    Type: AWS::Lambda::Function\n",
+	})
+
+	want := []string{
+		"Serverless Framework api/serverless.yml orders",
+		"  create src/create.handler nodejs20.x [httpApi POST /orders]",
+		"  nightly src/nightly.handler python3.12 [schedule rate(1 day)]",
+		"CloudFormation cfn/stack.json ",
+		"  Worker index.handler nodejs20.x [schedule rate(5 minutes)]",
+		"AWS SAM sam/template.yaml thumbnails",
+		"  Resize app.handler python3.12 [s3 Images]",
+	}
+	apps := findServerlessApps()
+	if got := serverlessSummary(apps); !reflect.DeepEqual(got, want) {
+		t.Errorf("findServerlessApps():\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(apps) > 0 {
+		stages := apps[0].Stages
+		if len(stages) != 1 || stages[0].Name != "prod" || !reflect.DeepEqual(stages[0].Config, []string{"DB_URL"}) {
+			t.Errorf("stages = %+v, want prod with the DB_URL key only", stages)
+		}
+	}
+}
+
+func TestCloudFormationKeyRegex(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"AWSTemplateFormatVersion: '2010-09-09'\n", true},
+		{"Description: x\nResources:\n  A: {}\n", true},
+		{`{"Resources": {}}`, true},
+		{"{\n  \"AWSTemplateFormatVersion\" : \"2010-09-09\"\n}", true},
+		{"spec:\n  resources:\n    limits: {}\n", false},
+		{"notes: AWS::Lambda::Function\n", false},
+	}
+	for _, test := range tests {
+		if got := cloudFormationKeyRegex.MatchString(test.content); got != test.want {
+			t.Errorf("cloudFormationKeyRegex.MatchString(%q) = %v, want %v", test.content, got, test.want)
+		}
+	}
+}
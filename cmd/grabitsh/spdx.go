@@ -0,0 +1,167 @@
+package grabitsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expressions with more alternatives than this are cut short rather than expanded further
+const spdxMaxChoices = 1024
+
+// An SPDX license expression: a single license when Operator is empty, otherwise AND or OR over Operands
+type spdxExpression struct {
+	Operator string
+	Operands []*spdxExpression
+	License  string
+}
+
+type spdxParser struct {
+	tokens []string
+	pos    int
+}
+
+// Parse an SPDX license expression. AND binds tighter than OR, parentheses group, and the
+// exception in "<license> WITH <exception>" is dropped because policies match on the license.
+func parseSPDXExpression(expression string) (*spdxExpression, error) {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	parser := &spdxParser{tokens: tokens}
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected %q in license expression %q", tokens[parser.pos], expression)
+	}
+	return node, nil
+}
+
+func (parser *spdxParser) peek() string {
+	if parser.pos < len(parser.tokens) {
+		return parser.tokens[parser.pos]
+	}
+	return ""
+}
+
+func (parser *spdxParser) parseOr() (*spdxExpression, error) {
+	return parser.parseBinary("OR", parser.parseAnd)
+}
+
+func (parser *spdxParser) parseAnd() (*spdxExpression, error) {
+	return parser.parseBinary("AND", parser.parseTerm)
+}
+
+func (parser *spdxParser) parseBinary(operator string, operand func() (*spdxExpression, error)) (*spdxExpression, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	node := &spdxExpression{Operator: operator, Operands: []*spdxExpression{first}}
+	for strings.EqualFold(parser.peek(), operator) {
+		parser.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		node.Operands = append(node.Operands, next)
+	}
+	if len(node.Operands) == 1 {
+		return first, nil
+	}
+	return node, nil
+}
+
+func (parser *spdxParser) parseTerm() (*spdxExpression, error) {
+	token := parser.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("license expression ends unexpectedly")
+	case token == "(":
+		parser.pos++
+		node, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if parser.peek() != ")" {
+			return nil, fmt.Errorf("missing ) in license expression")
+		}
+		parser.pos++
+		return node, nil
+	case token == ")" || isSPDXOperator(token):
+		return nil, fmt.Errorf("unexpected %q in license expression", token)
+	}
+	parser.pos++
+	if strings.EqualFold(parser.peek(), "WITH") {
+		parser.pos++
+		if exception := parser.peek(); exception == "" || exception == "(" || exception == ")" || isSPDXOperator(exception) {
+			return nil, fmt.Errorf("missing exception after WITH in license expression")
+		}
+		parser.pos++
+	}
+	return &spdxExpression{License: token}, nil
+}
+
+func isSPDXOperator(token string) bool {
+	return strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR") || strings.EqualFold(token, "WITH")
+}
+
+// Every set of licenses that satisfies the expression: one entry per OR alternative, with AND
+// operands combined into the same set
+func (node *spdxExpression) choices() [][]string {
+	switch node.Operator {
+	case "":
+		return [][]string{{node.License}}
+	case "OR":
+		var choices [][]string
+		for _, operand := range node.Operands {
+			choices = append(choices, operand.choices()...)
+			if len(choices) >= spdxMaxChoices {
+				return choices[:spdxMaxChoices]
+			}
+		}
+		return choices
+	}
+	choices := [][]string{nil}
+	for _, operand := range node.Operands {
+		var combined [][]string
+		for _, choice := range choices {
+			for _, alternative := range operand.choices() {
+				licenses := append(append([]string{}, choice...), alternative...)
+				combined = append(combined, licenses)
+				if len(combined) >= spdxMaxChoices {
+					break
+				}
+			}
+			if len(combined) >= spdxMaxChoices {
+				break
+			}
+		}
+		choices = combined
+	}
+	return choices
+}
+
+// The license choices for a dependency's declared licenses, any one of which may be used. An
+// expression that does not parse is treated as requiring every identifier it mentions.
+func dependencyLicenseChoices(licenses []string) [][]string {
+	var choices [][]string
+	for _, license := range licenses {
+		node, err := parseSPDXExpression(license)
+		if err != nil {
+			var ids []string
+			for _, token := range strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(license)) {
+				if !isSPDXOperator(token) {
+					ids = append(ids, token)
+				}
+			}
+			if len(ids) > 0 {
+				choices = append(choices, ids)
+			}
+			continue
+		}
+		choices = append(choices, node.choices()...)
+	}
+	return choices
+}
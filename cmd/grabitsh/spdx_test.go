@@ -0,0 +1,55 @@
+package grabitsh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSPDXExpressionChoices(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       [][]string
+	}{
+		{"MIT", [][]string{{"MIT"}}},
+		{"MIT OR Apache-2.0", [][]string{{"MIT"}, {"Apache-2.0"}}},
+		{"MIT AND BSD-3-Clause OR Apache-2.0", [][]string{{"MIT", "BSD-3-Clause"}, {"Apache-2.0"}}},
+		{"MIT OR BSD-3-Clause AND Apache-2.0", [][]string{{"MIT"}, {"BSD-3-Clause", "Apache-2.0"}}},
+		{"(MIT OR GPL-3.0-only) AND AGPL-3.0-only", [][]string{{"MIT", "AGPL-3.0-only"}, {"GPL-3.0-only", "AGPL-3.0-only"}}},
+		{"(MIT OR ISC) AND (Apache-2.0 OR BSD-2-Clause)", [][]string{{"MIT", "Apache-2.0"}, {"MIT", "BSD-2-Clause"}, {"ISC", "Apache-2.0"}, {"ISC", "BSD-2-Clause"}}},
+		{"GPL-2.0-only WITH Classpath-exception-2.0 OR MIT", [][]string{{"GPL-2.0-only"}, {"MIT"}}},
+		{"((MIT))", [][]string{{"MIT"}}},
+		{"mit or apache-2.0", [][]string{{"mit"}, {"apache-2.0"}}},
+	}
+	for _, test := range tests {
+		node, err := parseSPDXExpression(test.expression)
+		if err != nil {
+			t.Errorf("%q: %v", test.expression, err)
+			continue
+		}
+		if got := node.choices(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: choices %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestParseSPDXExpressionErrors(t *testing.T) {
+	for _, expression := range []string{"", "MIT OR", "(MIT", "MIT)", "AND MIT", "MIT WITH", "MIT Apache-2.0"} {
+		if _, err := parseSPDXExpression(expression); err == nil {
+			t.Errorf("%q: parsed without error", expression)
+		}
+	}
+}
+
+func TestSPDXChoicesAreCapped(t *testing.T) {
+	expression := "(A OR B)"
+	for i := 0; i < 20; i++ {
+		expression += " AND (A OR B)"
+	}
+	node, err := parseSPDXExpression(expression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(node.choices()); got != spdxMaxChoices {
+		t.Errorf("got %d choices, want %d", got, spdxMaxChoices)
+	}
+}
@@ -0,0 +1,439 @@
+package grabitsh
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SubProject is a project root found anywhere in the tree, such as services/api/go.mod or web/package.json
+type SubProject struct {
+	Path           string   `json:"path"`
+	Language       string   `json:"language"`
+	Frameworks     []string `json:"frameworks,omitempty"`
+	BuildTool      string   `json:"build_tool"`
+	TestFrameworks []string `json:"test_frameworks,omitempty"`
+}
+
+type dependencyLabel struct {
+	dependency, name string
+}
+
+var (
+	goFrameworks = []dependencyLabel{
+		{"github.com/gin-gonic/gin", "Gin"}, {"github.com/labstack/echo", "Echo"}, {"github.com/gofiber/fiber", "Fiber"},
+		{"github.com/go-chi/chi", "chi"}, {"github.com/gorilla/mux", "Gorilla mux"}, {"google.golang.org/grpc", "gRPC"},
+		{"github.com/spf13/cobra", "Cobra"}, {"github.com/urfave/cli", "urfave/cli"},
+	}
+	goTestFrameworks = []dependencyLabel{
+		{"github.com/stretchr/testify", "testify"}, {"github.com/onsi/ginkgo", "Ginkgo"}, {"github.com/onsi/gomega", "Gomega"},
+	}
+	nodeTestFrameworks = []dependencyLabel{
+		{"jest", "Jest"}, {"vitest", "Vitest"}, {"mocha", "Mocha"}, {"jasmine", "Jasmine"}, {"ava", "AVA"},
+		{"@playwright/test", "Playwright"}, {"cypress", "Cypress"},
+	}
+	pythonFrameworks = []dependencyLabel{
+		{"django", "Django"}, {"flask", "Flask"}, {"fastapi", "FastAPI"}, {"starlette", "Starlette"},
+		{"tornado", "Tornado"}, {"pyramid", "Pyramid"}, {"streamlit", "Streamlit"},
+	}
+	pythonTestFrameworks = []dependencyLabel{
+		{"pytest", "pytest"}, {"nose2", "nose2"}, {"behave", "behave"}, {"tox", "tox"},
+	}
+	rustFrameworks = []dependencyLabel{
+		{"actix-web", "Actix Web"}, {"axum", "Axum"}, {"rocket", "Rocket"}, {"warp", "warp"}, {"tauri", "Tauri"},
+		{"clap", "clap"},
+	}
+	rustTestFrameworks = []dependencyLabel{
+		{"rstest", "rstest"}, {"proptest", "proptest"}, {"criterion", "Criterion"},
+	}
+	rubyFrameworks = []dependencyLabel{
+		{"rails", "Rails"}, {"sinatra", "Sinatra"}, {"hanami", "Hanami"}, {"grape", "Grape"},
+	}
+	rubyTestFrameworks = []dependencyLabel{
+		{"rspec", "RSpec"}, {"rspec-rails", "RSpec"}, {"minitest", "Minitest"}, {"cucumber", "Cucumber"},
+	}
+	phpFrameworks = []dependencyLabel{
+		{"laravel/framework", "Laravel"}, {"symfony/framework-bundle", "Symfony"}, {"slim/slim", "Slim"},
+		{"cakephp/cakephp", "CakePHP"}, {"yiisoft/yii2", "Yii"},
+	}
+	phpTestFrameworks = []dependencyLabel{
+		{"phpunit/phpunit", "PHPUnit"}, {"pestphp/pest", "Pest"}, {"codeception/codeception", "Codeception"},
+	}
+	// Maven and Gradle coordinates are matched on their group
+	jvmFrameworks = []dependencyLabel{
+		{"org.springframework.boot", "Spring Boot"}, {"io.quarkus", "Quarkus"}, {"io.micronaut", "Micronaut"},
+		{"io.dropwizard", "Dropwizard"}, {"io.ktor", "Ktor"}, {"io.vertx", "Vert.x"},
+	}
+	jvmTestFrameworks = []dependencyLabel{
+		{"junit", "JUnit"}, {"org.junit.jupiter", "JUnit"}, {"org.testng", "TestNG"}, {"io.kotest", "Kotest"},
+		{"org.spockframework", "Spock"},
+	}
+	dotnetTestFrameworks = []dependencyLabel{
+		{"xunit", "xUnit"}, {"NUnit", "NUnit"}, {"MSTest.TestFramework", "MSTest"},
+	}
+
+	gradlePluginRegex  = regexp.MustCompile(`\bid\s*\(?\s*["']([\w.\-]+)["']`)
+	csprojPackageRegex = regexp.MustCompile(`<PackageReference\s+Include="([^"]+)"`)
+)
+
+// Labels whose dependency is declared; Go module paths match below a major version suffix and JVM groups any artifact
+func matchDependencyLabels(names []string, labels []dependencyLabel) []string {
+	var matched []string
+	for _, label := range labels {
+		for _, name := range names {
+			if name == label.dependency || strings.HasPrefix(name, label.dependency+"/") || strings.HasPrefix(name, label.dependency+":") {
+				matched = appendUnique(matched, label.name)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+var (
+	subProjectsOnce sync.Once
+	subProjects     []SubProject
+)
+
+// The tree is walked once per run; project types, architecture and the advanced analysis share the result
+func detectSubProjects() []SubProject {
+	subProjectsOnce.Do(func() {
+		subProjects = findSubProjects()
+	})
+	return subProjects
+}
+
+// Find every project root in the tree by its manifest and describe the project built there
+func findSubProjects() []SubProject {
+	markers := map[string]bool{
+		"go.mod": true, "package.json": true, "Cargo.toml": true, "pyproject.toml": true, "setup.py": true,
+		"requirements.txt": true, "Pipfile": true, "Gemfile": true, "composer.json": true, "pom.xml": true,
+		"build.gradle": true, "build.gradle.kts": true,
+	}
+	files := findRepositoryFiles(func(name string) bool {
+		return markers[name] || strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, ".csproj") ||
+			strings.HasSuffix(name, ".fsproj") || strings.HasSuffix(name, ".tftest.hcl")
+	})
+	byDir := make(map[string]map[string]bool)
+	for _, file := range files {
+		dir := path.Dir(file)
+		if byDir[dir] == nil {
+			byDir[dir] = make(map[string]bool)
+		}
+		byDir[dir][path.Base(file)] = true
+	}
+	// Files below a directory, for test files that live away from the manifest
+	under := func(dir, suffix string) bool {
+		for _, file := range files {
+			if strings.HasSuffix(file, suffix) && (dir == "." || strings.HasPrefix(file, dir+"/")) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var projects []SubProject
+	for _, dir := range sortedKeys(byDir) {
+		present := byDir[dir]
+		if present["go.mod"] {
+			projects = append(projects, detectGoSubProject(dir, under(dir, "_test.go")))
+		}
+		if present["package.json"] {
+			projects = append(projects, detectNodeSubProject(dir))
+		}
+		if present["Cargo.toml"] {
+			projects = append(projects, detectRustSubProject(dir))
+		}
+		if present["pyproject.toml"] || present["setup.py"] || present["requirements.txt"] || present["Pipfile"] {
+			projects = append(projects, detectPythonSubProject(dir, present))
+		}
+		if present["Gemfile"] {
+			projects = append(projects, detectRubySubProject(dir))
+		}
+		if present["composer.json"] {
+			projects = append(projects, detectPHPSubProject(dir))
+		}
+		if present["pom.xml"] || present["build.gradle"] || present["build.gradle.kts"] {
+			projects = append(projects, detectJVMSubProject(dir, present))
+		}
+		for _, name := range sortedKeys(present) {
+			if strings.HasSuffix(name, ".csproj") || strings.HasSuffix(name, ".fsproj") {
+				projects = append(projects, detectDotnetSubProject(dir, name))
+			}
+		}
+	}
+
+	// Terraform root modules; modules called by another one are part of that project
+	for _, module := range loadTerraformModules() {
+		if !module.Root {
+			continue
+		}
+		project := SubProject{Path: module.Dir, Language: "Terraform", BuildTool: "Terraform CLI"}
+		for _, provider := range module.Providers {
+			project.Frameworks = append(project.Frameworks, provider.Name+" provider")
+		}
+		if under(module.Dir, ".tftest.hcl") {
+			project.TestFrameworks = []string{"terraform test"}
+		}
+		projects = append(projects, project)
+	}
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].Path < projects[j].Path })
+	return projects
+}
+
+func detectGoSubProject(dir string, hasTests bool) SubProject {
+	project := SubProject{Path: dir, Language: "Go", BuildTool: "Go modules"}
+	var names []string
+	if mod, err := parseGoModFile(filepath.Join(dir, "go.mod")); err == nil {
+		for _, requirement := range mod.Require {
+			names = append(names, requirement.Path)
+		}
+	}
+	project.Frameworks = matchDependencyLabels(names, goFrameworks)
+	if hasTests {
+		project.TestFrameworks = append(project.TestFrameworks, "Go testing")
+	}
+	for _, framework := range matchDependencyLabels(names, goTestFrameworks) {
+		project.TestFrameworks = appendUnique(project.TestFrameworks, framework)
+	}
+	return project
+}
+
+func detectNodeSubProject(dir string) SubProject {
+	project := SubProject{Path: dir, Language: "JavaScript"}
+	names := sortedKeys(readPackageJSONDependencies(dir))
+	if fileExists(filepath.Join(dir, "tsconfig.json")) || matchDependencyLabels(names, []dependencyLabel{{"typescript", "TypeScript"}}) != nil {
+		project.Language = "TypeScript"
+	}
+	project.Frameworks = matchDependencyLabels(names, nodeFrameworks)
+	project.TestFrameworks = matchDependencyLabels(names, nodeTestFrameworks)
+
+	// Workspace packages share the lockfile of the workspace root, so look upwards for it
+	project.BuildTool = "npm"
+	for current := dir; ; current = path.Dir(current) {
+		if manager := nodeDirPackageManager(current); manager != "" {
+			project.BuildTool = manager
+			break
+		}
+		if current == "." {
+			break
+		}
+	}
+	return project
+}
+
+// The manager pinned by packageManager or implied by the lockfile in dir, if any
+func nodeDirPackageManager(dir string) string {
+	manifest, _ := readNodePackageManifest(filepath.Join(dir, "package.json"))
+	if manager, ok := nodePackageManagers[strings.SplitN(manifest.PackageManager, "@", 2)[0]]; ok {
+		return manager
+	}
+	switch {
+	case fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+		return "pnpm"
+	case fileExists(filepath.Join(dir, "yarn.lock")):
+		return "Yarn"
+	case fileExists(filepath.Join(dir, "bun.lockb")) || fileExists(filepath.Join(dir, "bun.lock")):
+		return "Bun"
+	case fileExists(filepath.Join(dir, "package-lock.json")) || fileExists(filepath.Join(dir, "npm-shrinkwrap.json")):
+		return "npm"
+	}
+	return ""
+}
+
+func detectRustSubProject(dir string) SubProject {
+	project := SubProject{Path: dir, Language: "Rust", BuildTool: "Cargo"}
+	manifest, _ := readTOMLFile(filepath.Join(dir, "Cargo.toml"))
+	names := append(sortedKeys(yamlMap(manifest["dependencies"])), sortedKeys(yamlMap(manifest["dev-dependencies"]))...)
+	project.Frameworks = matchDependencyLabels(names, rustFrameworks)
+	if dirExists(filepath.Join(dir, "tests")) {
+		project.TestFrameworks = append(project.TestFrameworks, "cargo test")
+	}
+	for _, framework := range matchDependencyLabels(names, rustTestFrameworks) {
+		project.TestFrameworks = appendUnique(project.TestFrameworks, framework)
+	}
+	return project
+}
+
+func detectPythonSubProject(dir string, present map[string]bool) SubProject {
+	project := SubProject{Path: dir, Language: "Python", BuildTool: "pip"}
+	declared := make(declaredDependencies)
+	var pyproject map[string]interface{}
+	if present["pyproject.toml"] {
+		pyproject, _ = readTOMLFile(filepath.Join(dir, "pyproject.toml"))
+		for name, dependency := range readPyprojectDependencies(filepath.Join(dir, "pyproject.toml")) {
+			declared[name] = dependency
+		}
+	}
+	if pipfile, err := readTOMLFile(filepath.Join(dir, "Pipfile")); err == nil {
+		for _, section := range []string{"packages", "dev-packages"} {
+			for name := range yamlMap(pipfile[section]) {
+				declared.add(map[string]interface{}{normalizePythonName(name): ""}, scopeProd)
+			}
+		}
+	}
+	if file, err := os.Open(filepath.Join(dir, "requirements.txt")); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if match := pep508NameRegex.FindStringSubmatch(line); match != nil && !strings.HasPrefix(line, "#") {
+				declared.add(map[string]interface{}{normalizePythonName(match[1]): ""}, scopeProd)
+			}
+		}
+		file.Close()
+	}
+	names := sortedKeys(declared)
+	project.Frameworks = matchDependencyLabels(names, pythonFrameworks)
+	project.TestFrameworks = matchDependencyLabels(names, pythonTestFrameworks)
+	if fileExists(filepath.Join(dir, "pytest.ini")) || fileExists(filepath.Join(dir, "conftest.py")) || yamlMap(yamlMap(pyproject["tool"])["pytest"]) != nil {
+		project.TestFrameworks = appendUnique(project.TestFrameworks, "pytest")
+	}
+
+	tools := yamlMap(pyproject["tool"])
+	switch {
+	case fileExists(filepath.Join(dir, "poetry.lock")) || tools["poetry"] != nil:
+		project.BuildTool = "Poetry"
+	case fileExists(filepath.Join(dir, "uv.lock")) || tools["uv"] != nil:
+		project.BuildTool = "uv"
+	case fileExists(filepath.Join(dir, "pdm.lock")) || tools["pdm"] != nil:
+		project.BuildTool = "PDM"
+	case tools["hatch"] != nil:
+		project.BuildTool = "Hatch"
+	case present["Pipfile"]:
+		project.BuildTool = "Pipenv"
+	case present["setup.py"] || tools["setuptools"] != nil:
+		project.BuildTool = "setuptools"
+	}
+	return project
+}
+
+func detectRubySubProject(dir string) SubProject {
+	project := SubProject{Path: dir, Language: "Ruby", BuildTool: "Bundler"}
+	var names []string
+	if content, err := os.ReadFile(filepath.Join(dir, "Gemfile")); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			if match := gemfileGemRegex.FindStringSubmatch(line); match != nil {
+				names = append(names, match[1])
+			}
+		}
+	}
+	project.Frameworks = matchDependencyLabels(names, rubyFrameworks)
+	project.TestFrameworks = matchDependencyLabels(names, rubyTestFrameworks)
+	if fileExists(filepath.Join(dir, ".rspec")) {
+		project.TestFrameworks = appendUnique(project.TestFrameworks, "RSpec")
+	}
+	return project
+}
+
+func detectPHPSubProject(dir string) SubProject {
+	project := SubProject{Path: dir, Language: "PHP", BuildTool: "Composer"}
+	var manifest struct {
+		Require    map[string]interface{} `json:"require"`
+		RequireDev map[string]interface{} `json:"require-dev"`
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "composer.json")); err == nil {
+		_ = json.Unmarshal(content, &manifest)
+	}
+	names := sortedNames(manifest.Require, manifest.RequireDev)
+	project.Frameworks = matchDependencyLabels(names, phpFrameworks)
+	project.TestFrameworks = matchDependencyLabels(names, phpTestFrameworks)
+	if fileExists(filepath.Join(dir, "phpunit.xml")) || fileExists(filepath.Join(dir, "phpunit.xml.dist")) {
+		project.TestFrameworks = appendUnique(project.TestFrameworks, "PHPUnit")
+	}
+	return project
+}
+
+func detectJVMSubProject(dir string, present map[string]bool) SubProject {
+	project := SubProject{Path: dir, Language: "Java", BuildTool: "Maven"}
+	var names []string
+	if present["pom.xml"] {
+		var pom mavenProject
+		if content, err := os.ReadFile(filepath.Join(dir, "pom.xml")); err == nil && xml.Unmarshal(content, &pom) == nil {
+			// Spring Boot projects usually inherit from its parent POM
+			names = append(names, pom.Parent.GroupID+":parent")
+			for _, dependency := range append(pom.Dependencies, pom.Managed...) {
+				names = append(names, dependency.GroupID+":"+dependency.ArtifactID)
+			}
+		}
+	} else {
+		project.BuildTool = "Gradle"
+		for _, file := range []string{"build.gradle", "build.gradle.kts"} {
+			content, err := os.ReadFile(filepath.Join(dir, file))
+			if err != nil {
+				continue
+			}
+			for _, match := range gradleCoordinateRegex.FindAllStringSubmatch(string(content), -1) {
+				names = append(names, match[1]+":"+match[2])
+			}
+			// Plugins such as org.springframework.boot are matched like a group
+			for _, match := range gradlePluginRegex.FindAllStringSubmatch(string(content), -1) {
+				names = append(names, match[1])
+			}
+		}
+	}
+	if present["build.gradle.kts"] || dirExists(filepath.Join(dir, "src/main/kotlin")) {
+		project.Language = "Kotlin"
+	}
+	project.Frameworks = matchDependencyLabels(names, jvmFrameworks)
+	project.TestFrameworks = matchDependencyLabels(names, jvmTestFrameworks)
+	return project
+}
+
+func detectDotnetSubProject(dir, file string) SubProject {
+	project := SubProject{Path: dir, Language: "C#", BuildTool: ".NET SDK"}
+	if strings.HasSuffix(file, ".fsproj") {
+		project.Language = "F#"
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, file))
+	if strings.Contains(string(content), `Sdk="Microsoft.NET.Sdk.Web"`) {
+		project.Frameworks = append(project.Frameworks, "ASP.NET Core")
+	}
+	var names []string
+	for _, match := range csprojPackageRegex.FindAllStringSubmatch(string(content), -1) {
+		names = append(names, match[1])
+	}
+	project.TestFrameworks = matchDependencyLabels(names, dotnetTestFrameworks)
+	return project
+}
+
+// Languages of the projects, most common first, e.g. "3 projects: Go, TypeScript"
+func summarizeSubProjects(projects []SubProject) string {
+	counts := make(map[string]int)
+	var languages []string
+	for _, project := range projects {
+		if counts[project.Language] == 0 {
+			languages = append(languages, project.Language)
+		}
+		counts[project.Language]++
+	}
+	sort.SliceStable(languages, func(i, j int) bool { return counts[languages[i]] > counts[languages[j]] })
+	noun := "projects"
+	if len(projects) == 1 {
+		noun = "project"
+	}
+	return fmt.Sprintf("%d %s: %s", len(projects), noun, strings.Join(languages, ", "))
+}
+
+func writeSubProjects(output io.StringWriter, projects []SubProject) {
+	output.WriteString(fmt.Sprintf("Sub-projects (%s):\n", summarizeSubProjects(projects)))
+	for _, project := range projects {
+		line := fmt.Sprintf("- %s: %s", project.Path, project.Language)
+		if len(project.Frameworks) > 0 {
+			line += " (" + strings.Join(project.Frameworks, ", ") + ")"
+		}
+		line += ", built with " + project.BuildTool
+		if len(project.TestFrameworks) > 0 {
+			line += ", tested with " + strings.Join(project.TestFrameworks, ", ")
+		}
+		output.WriteString(line + "\n")
+	}
+}
@@ -0,0 +1,80 @@
+package grabitsh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindSubProjects(t *testing.T) {
+	chdirTestFiles(t, map[string]string{
+		"services/api/go.mod":                "module example.com/api\n\ngo 1.21\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.1\n\tgithub.com/stretchr/testify v1.8.4\n)\n",
+		"services/api/handler/x_test.go":     "package handler\n",
+		"web/package.json":                   `{"dependencies": {"next": "14.0.0", "react": "18.2.0"}, "devDependencies": {"vitest": "1.0.0"}}`,
+		"web/tsconfig.json":                  "{}",
+		"package.json":                       `{"private": true, "workspaces": ["web"], "packageManager": "pnpm@8.15.0"}`,
+		"node_modules/left-pad/package.json": `{"name": "left-pad"}`,
+		"ml/pyproject.toml":                  "[tool.poetry]\nname = \"ml\"\n\n[tool.poetry.dependencies]\nfastapi = \"^0.110\"\n\n[tool.pytest.ini_options]\n",
+		"ml/requirements.txt":                "# pinned\nFlask==3.0.0\n",
+		"crates/core/Cargo.toml":             "[package]\nname = \"core\"\n\n[dependencies]\naxum = \"0.7\"\n\n[dev-dependencies]\nproptest = \"1\"\n",
+		"crates/core/tests/it.rs":            "",
+		"app/build.gradle.kts":               "plugins {\n    id(\"org.springframework.boot\") version \"3.2.0\"\n}\ndependencies {\n    testImplementation(\"org.junit.jupiter:junit-jupiter:5.10.0\")\n}\n",
+		"legacy/pom.xml":                     "<project><dependencies><dependency><groupId>io.dropwizard</groupId><artifactId>dropwizard-core</artifactId></dependency></dependencies></project>",
+		"legacy/Gemfile":                     "source \"https://rubygems.org\"\ngem \"sinatra\"\ngem 'rspec', group: :test\n",
+		"legacy/composer.json":               `{"require": {"slim/slim": "^4"}, "require-dev": {"phpunit/phpunit": "^10"}}`,
+		"dotnet/Api/Api.csproj":              `<Project Sdk="Microsoft.NET.Sdk.Web"><ItemGroup><PackageReference Include="xunit" Version="2.6.0" /></ItemGroup></Project>`,
+		"infra/main.tf":                      "provider \"aws\" {\n  region = \"us-east-1\"\n}\n\nmodule \"net\" {\n  source = \"./modules/net\"\n}\n",
+		"infra/modules/net/main.tf":          "resource \"aws_vpc\" \"main\" {\n  cidr_block = \"10.0.0.0/16\"\n}\n",
+		"infra/tests/plan.tftest.hcl":        "run \"plan\" {\n  command = plan\n}\n",
+	})
+
+	want := []SubProject{
+		{Path: ".", Language: "JavaScript", BuildTool: "pnpm"},
+		{Path: "app", Language: "Kotlin", Frameworks: []string{"Spring Boot"}, BuildTool: "Gradle", TestFrameworks: []string{"JUnit"}},
+		{Path: "crates/core", Language: "Rust", Frameworks: []string{"Axum"}, BuildTool: "Cargo", TestFrameworks: []string{"cargo test", "proptest"}},
+		{Path: "dotnet/Api", Language: "C#", Frameworks: []string{"ASP.NET Core"}, BuildTool: ".NET SDK", TestFrameworks: []string{"xUnit"}},
+		{Path: "infra", Language: "Terraform", Frameworks: []string{"aws provider"}, BuildTool: "Terraform CLI", TestFrameworks: []string{"terraform test"}},
+		{Path: "legacy", Language: "Ruby", Frameworks: []string{"Sinatra"}, BuildTool: "Bundler", TestFrameworks: []string{"RSpec"}},
+		{Path: "legacy", Language: "PHP", Frameworks: []string{"Slim"}, BuildTool: "Composer", TestFrameworks: []string{"PHPUnit"}},
+		{Path: "legacy", Language: "Java", Frameworks: []string{"Dropwizard"}, BuildTool: "Maven"},
+		{Path: "ml", Language: "Python", Frameworks: []string{"Flask", "FastAPI"}, BuildTool: "Poetry", TestFrameworks: []string{"pytest"}},
+		{Path: "services/api", Language: "Go", Frameworks: []string{"Gin"}, BuildTool: "Go modules", TestFrameworks: []string{"Go testing", "testify"}},
+		{Path: "web", Language: "TypeScript", Frameworks: []string{"Next.js", "React"}, BuildTool: "pnpm", TestFrameworks: []string{"Vitest"}},
+	}
+	got := findSubProjects()
+	if len(got) != len(want) {
+		t.Fatalf("found %d projects, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("project %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMatchDependencyLabels(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{"github.com/labstack/echo/v4"}, []string{"Echo"}},
+		{[]string{"github.com/labstack/echoes"}, nil},
+		{[]string{"io.quarkus:quarkus-core", "org.junit.jupiter:junit-jupiter"}, []string{"Quarkus"}},
+		{[]string{"fastify", "express", "express"}, []string{"Express", "Fastify"}},
+	}
+	labels := append(append(append([]dependencyLabel{}, goFrameworks...), jvmFrameworks...), nodeFrameworks...)
+	for _, test := range tests {
+		if got := matchDependencyLabels(test.names, labels); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("matchDependencyLabels(%v) = %v, want %v", test.names, got, test.want)
+		}
+	}
+}
+
+func TestSummarizeSubProjects(t *testing.T) {
+	projects := []SubProject{{Language: "TypeScript"}, {Language: "Go"}, {Language: "Go"}}
+	if got, want := summarizeSubProjects(projects), "3 projects: Go, TypeScript"; got != want {
+		t.Errorf("summarizeSubProjects = %q, want %q", got, want)
+	}
+	if got, want := summarizeSubProjects(projects[:1]), "1 project: TypeScript"; got != want {
+		t.Errorf("summarizeSubProjects = %q, want %q", got, want)
+	}
+}
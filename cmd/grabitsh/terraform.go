@@ -0,0 +1,457 @@
+package grabitsh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type TerraformProvider struct {
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Provider configuration blocks, "default" or the alias
+	Configurations []string `json:"configurations,omitempty"`
+}
+
+type TerraformModuleCall struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	// Directory of the called module when the source is a local path
+	LocalDir string `json:"local_dir,omitempty"`
+}
+
+type TerraformVariable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	HasDefault  bool   `json:"has_default"`
+	Sensitive   bool   `json:"sensitive"`
+	Description string `json:"description,omitempty"`
+}
+
+type TerraformOutput struct {
+	Name        string `json:"name"`
+	Sensitive   bool   `json:"sensitive"`
+	Description string `json:"description,omitempty"`
+}
+
+type TerraformBackend struct {
+	Type string `json:"type"`
+	// Literal settings, with credential-like keys left out
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+type TerraformLockPin struct {
+	Provider    string `json:"provider"`
+	Version     string `json:"version"`
+	Constraints string `json:"constraints,omitempty"`
+	Hashes      int    `json:"hashes"`
+}
+
+type TerraformResource struct {
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	Block *HCLBlock `json:"-"`
+}
+
+// TerraformModule is a directory of .tf files
+type TerraformModule struct {
+	Dir             string                `json:"dir"`
+	Files           []string              `json:"files"`
+	RequiredVersion string                `json:"required_version,omitempty"`
+	Providers       []*TerraformProvider  `json:"providers,omitempty"`
+	ModuleCalls     []TerraformModuleCall `json:"module_calls,omitempty"`
+	Resources       []TerraformResource   `json:"resources,omitempty"`
+	Backend         *TerraformBackend     `json:"backend,omitempty"`
+	Variables       []TerraformVariable   `json:"variables,omitempty"`
+	Outputs         []TerraformOutput     `json:"outputs,omitempty"`
+	LockPins        []TerraformLockPin    `json:"lock_pins,omitempty"`
+	// Root modules are not called by any other module in the repository
+	Root   bool     `json:"root"`
+	Errors []string `json:"errors,omitempty"`
+
+	providerBlocks []terraformProviderBlock
+}
+
+type terraformProviderBlock struct {
+	file  string
+	block *HCLBlock
+}
+
+func isTerraformFileName(name string) bool {
+	return strings.HasSuffix(name, ".tf")
+}
+
+// Find and parse every Terraform module, skipping the provider and module caches under .terraform
+func loadTerraformModules() []*TerraformModule {
+	byDir := make(map[string]*TerraformModule)
+	var dirs []string
+	for _, path := range findRepositoryFiles(isTerraformFileName) {
+		if strings.HasPrefix(path, ".terraform/") || strings.Contains(path, "/.terraform/") {
+			continue
+		}
+		dir := filepath.ToSlash(filepath.Dir(path))
+		module, ok := byDir[dir]
+		if !ok {
+			module = &TerraformModule{Dir: dir, Root: true}
+			byDir[dir] = module
+			dirs = append(dirs, dir)
+		}
+		module.Files = append(module.Files, path)
+	}
+	sort.Strings(dirs)
+
+	var modules []*TerraformModule
+	for _, dir := range dirs {
+		module := byDir[dir]
+		for _, file := range module.Files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				module.Errors = append(module.Errors, fmt.Sprintf("%s: %v", file, err))
+				continue
+			}
+			body, err := parseHCL(string(content))
+			if err != nil {
+				module.Errors = append(module.Errors, fmt.Sprintf("%s: %v", file, err))
+			}
+			module.addFile(file, body)
+		}
+		module.loadLockFile()
+		modules = append(modules, module)
+	}
+
+	for _, module := range modules {
+		for _, call := range module.ModuleCalls {
+			if called, ok := byDir[call.LocalDir]; ok && call.LocalDir != module.Dir {
+				called.Root = false
+			}
+		}
+	}
+	return modules
+}
+
+func (module *TerraformModule) provider(name string) *TerraformProvider {
+	for _, provider := range module.Providers {
+		if provider.Name == name {
+			return provider
+		}
+	}
+	provider := &TerraformProvider{Name: name}
+	module.Providers = append(module.Providers, provider)
+	return provider
+}
+
+func (module *TerraformModule) addFile(file string, body *HCLBlock) {
+	for _, block := range body.Blocks {
+		switch block.Type {
+		case "terraform":
+			if version := block.AttributeString("required_version"); version != "" {
+				module.RequiredVersion = version
+			}
+			for _, required := range block.BlocksOfType("required_providers") {
+				for _, name := range required.AttributeNames() {
+					provider := module.provider(name)
+					switch value := required.Attributes[name].Value.(type) {
+					case string:
+						// Terraform 0.12 shorthand: aws = "~> 2.0"
+						provider.Version = value
+					case map[string]interface{}:
+						provider.Source = yamlString(value["source"])
+						provider.Version = yamlString(value["version"])
+					}
+				}
+			}
+			for _, backend := range append(block.BlocksOfType("backend"), block.BlocksOfType("cloud")...) {
+				module.Backend = &TerraformBackend{Type: backend.Label(0), Settings: make(map[string]string)}
+				if backend.Type == "cloud" {
+					module.Backend.Type = "cloud"
+				}
+				for _, name := range backend.AttributeNames() {
+					if _, isExpression := backend.Attributes[name].Value.(HCLExpression); isExpression || dockerSecretNameRegex.MatchString(name) {
+						continue
+					}
+					module.Backend.Settings[name] = backend.AttributeString(name)
+				}
+				for _, nested := range backend.Blocks {
+					for _, name := range nested.AttributeNames() {
+						if !dockerSecretNameRegex.MatchString(name) {
+							module.Backend.Settings[nested.Type+"."+name] = nested.AttributeString(name)
+						}
+					}
+				}
+			}
+		case "provider":
+			configuration := "default"
+			if alias := block.AttributeString("alias"); alias != "" {
+				configuration = alias
+			}
+			provider := module.provider(block.Label(0))
+			provider.Configurations = append(provider.Configurations, configuration)
+			module.providerBlocks = append(module.providerBlocks, terraformProviderBlock{file: file, block: block})
+		case "module":
+			call := TerraformModuleCall{
+				Name:    block.Label(0),
+				Source:  block.AttributeString("source"),
+				Version: block.AttributeString("version"),
+				File:    file,
+				Line:    block.Line,
+			}
+			if strings.HasPrefix(call.Source, "./") || strings.HasPrefix(call.Source, "../") {
+				call.LocalDir = filepath.ToSlash(filepath.Join(module.Dir, call.Source))
+			}
+			module.ModuleCalls = append(module.ModuleCalls, call)
+		case "resource", "data":
+			module.Resources = append(module.Resources, TerraformResource{
+				Mode:  block.Type,
+				Type:  block.Label(0),
+				Name:  block.Label(1),
+				File:  file,
+				Line:  block.Line,
+				Block: block,
+			})
+		case "variable":
+			_, hasDefault := block.Attributes["default"]
+			sensitive, _ := block.Attributes["sensitive"].valueOrNil().(bool)
+			module.Variables = append(module.Variables, TerraformVariable{
+				Name:        block.Label(0),
+				Type:        block.AttributeString("type"),
+				HasDefault:  hasDefault,
+				Sensitive:   sensitive,
+				Description: block.AttributeString("description"),
+			})
+		case "output":
+			sensitive, _ := block.Attributes["sensitive"].valueOrNil().(bool)
+			module.Outputs = append(module.Outputs, TerraformOutput{
+				Name:        block.Label(0),
+				Sensitive:   sensitive,
+				Description: block.AttributeString("description"),
+			})
+		}
+	}
+}
+
+func (attribute *HCLAttribute) valueOrNil() interface{} {
+	if attribute == nil {
+		return nil
+	}
+	return attribute.Value
+}
+
+func (module *TerraformModule) loadLockFile() {
+	path := filepath.Join(module.Dir, ".terraform.lock.hcl")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	body, err := parseHCL(string(content))
+	if err != nil {
+		module.Errors = append(module.Errors, fmt.Sprintf("%s: %v", filepath.ToSlash(path), err))
+	}
+	for _, block := range body.BlocksOfType("provider") {
+		hashes, _ := block.Attributes["hashes"].valueOrNil().([]interface{})
+		module.LockPins = append(module.LockPins, TerraformLockPin{
+			Provider:    block.Label(0),
+			Version:     block.AttributeString("version"),
+			Constraints: block.AttributeString("constraints"),
+			Hashes:      len(hashes),
+		})
+	}
+}
+
+// Resource counts per provider, using the type prefix (aws_s3_bucket -> aws)
+func (module *TerraformModule) resourceCounts() map[string]map[string]int {
+	counts := make(map[string]map[string]int)
+	for _, resource := range module.Resources {
+		if resource.Mode != "resource" {
+			continue
+		}
+		provider := strings.SplitN(resource.Type, "_", 2)[0]
+		if counts[provider] == nil {
+			counts[provider] = make(map[string]int)
+		}
+		counts[provider][resource.Type]++
+	}
+	return counts
+}
+
+func writeTerraformModules(output io.StringWriter, modules []*TerraformModule) {
+	byDir := make(map[string]*TerraformModule)
+	for _, module := range modules {
+		byDir[module.Dir] = module
+	}
+
+	for _, module := range modules {
+		if !module.Root {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("Terraform root module: %s (%d files)\n", module.Dir, len(module.Files)))
+		writeTerraformModule(output, module, "  ")
+
+		// Local child modules are reported under the root that calls them
+		visited := map[string]bool{module.Dir: true}
+		var walk func(parent *TerraformModule, depth int)
+		walk = func(parent *TerraformModule, depth int) {
+			for _, call := range parent.ModuleCalls {
+				child, ok := byDir[call.LocalDir]
+				if !ok || visited[child.Dir] {
+					continue
+				}
+				visited[child.Dir] = true
+				indent := strings.Repeat("  ", depth+1)
+				output.WriteString(fmt.Sprintf("%sLocal module %q: %s\n", indent, call.Name, child.Dir))
+				writeTerraformModule(output, child, indent+"  ")
+				walk(child, depth+1)
+			}
+		}
+		walk(module, 0)
+		output.WriteString("\n")
+	}
+}
+
+func writeTerraformModule(output io.StringWriter, module *TerraformModule, indent string) {
+	if module.RequiredVersion != "" {
+		output.WriteString(fmt.Sprintf("%sRequired Terraform version: %s\n", indent, module.RequiredVersion))
+	}
+	if module.Backend != nil {
+		var settings []string
+		for _, name := range sortedKeys(module.Backend.Settings) {
+			settings = append(settings, name+"="+module.Backend.Settings[name])
+		}
+		line := fmt.Sprintf("%sBackend: %s", indent, module.Backend.Type)
+		if len(settings) > 0 {
+			line += " (" + strings.Join(settings, ", ") + ")"
+		}
+		output.WriteString(line + "\n")
+	}
+
+	if len(module.Providers) > 0 {
+		output.WriteString(indent + "Providers:\n")
+		pins := make(map[string]TerraformLockPin)
+		for _, pin := range module.LockPins {
+			pins[pin.Provider] = pin
+		}
+		for _, provider := range module.Providers {
+			line := fmt.Sprintf("%s  - %s", indent, provider.Name)
+			if provider.Source != "" {
+				line += " (" + provider.Source + ")"
+			}
+			if provider.Version != "" {
+				line += " " + provider.Version
+			}
+			if pin, ok := pins[terraformProviderAddress(provider)]; ok {
+				line += fmt.Sprintf(", locked at %s", pin.Version)
+			}
+			if len(provider.Configurations) > 0 {
+				line += ", configurations: " + strings.Join(provider.Configurations, ", ")
+			}
+			output.WriteString(line + "\n")
+		}
+	}
+	if len(module.LockPins) > 0 {
+		output.WriteString(indent + "Lock file pins (.terraform.lock.hcl):\n")
+		for _, pin := range module.LockPins {
+			output.WriteString(fmt.Sprintf("%s  - %s %s (constraints: %s, %d hashes)\n", indent, pin.Provider, pin.Version, orDash(pin.Constraints), pin.Hashes))
+		}
+	}
+
+	if len(module.ModuleCalls) > 0 {
+		output.WriteString(indent + "Modules:\n")
+		for _, call := range module.ModuleCalls {
+			line := fmt.Sprintf("%s  - %s: %s", indent, call.Name, call.Source)
+			if call.Version != "" {
+				line += " @ " + call.Version
+			}
+			output.WriteString(line + "\n")
+		}
+	}
+
+	counts := module.resourceCounts()
+	if len(counts) > 0 {
+		output.WriteString(indent + "Resources by provider:\n")
+		for _, provider := range sortedKeys(counts) {
+			total := 0
+			var types []string
+			for _, resourceType := range sortedKeys(counts[provider]) {
+				total += counts[provider][resourceType]
+				types = append(types, fmt.Sprintf("%s x%d", resourceType, counts[provider][resourceType]))
+			}
+			output.WriteString(fmt.Sprintf("%s  - %s (%d): %s\n", indent, provider, total, strings.Join(types, ", ")))
+		}
+	}
+	dataSources := 0
+	for _, resource := range module.Resources {
+		if resource.Mode == "data" {
+			dataSources++
+		}
+	}
+	if dataSources > 0 {
+		output.WriteString(fmt.Sprintf("%sData sources: %d\n", indent, dataSources))
+	}
+
+	if len(module.Variables) > 0 {
+		var names []string
+		for _, variable := range module.Variables {
+			name := variable.Name
+			var flags []string
+			if variable.Type != "" {
+				flags = append(flags, variable.Type)
+			}
+			if !variable.HasDefault {
+				flags = append(flags, "required")
+			}
+			if variable.Sensitive {
+				flags = append(flags, "sensitive")
+			}
+			if len(flags) > 0 {
+				name += " (" + strings.Join(flags, ", ") + ")"
+			}
+			names = append(names, name)
+		}
+		output.WriteString(fmt.Sprintf("%sVariables: %s\n", indent, strings.Join(names, ", ")))
+	}
+	if len(module.Outputs) > 0 {
+		var names []string
+		for _, out := range module.Outputs {
+			name := out.Name
+			if out.Sensitive {
+				name += " (sensitive)"
+			}
+			names = append(names, name)
+		}
+		output.WriteString(fmt.Sprintf("%sOutputs: %s\n", indent, strings.Join(names, ", ")))
+	}
+	for _, parseError := range module.Errors {
+		output.WriteString(fmt.Sprintf("%sError parsing %s\n", indent, parseError))
+	}
+}
+
+// Registry address used in .terraform.lock.hcl for a required provider
+func terraformProviderAddress(provider *TerraformProvider) string {
+	source := provider.Source
+	if source == "" {
+		source = "hashicorp/" + provider.Name
+	}
+	if strings.Count(source, "/") == 1 {
+		source = "registry.terraform.io/" + source
+	}
+	return strings.ToLower(source)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,344 @@
+package grabitsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var (
+	publicS3ACLs = map[string]bool{"public-read": true, "public-read-write": true, "authenticated-read": true}
+
+	// Ports that should never be reachable from the whole internet
+	sensitivePorts = map[int]string{22: "SSH", 3389: "RDP", 3306: "MySQL", 5432: "PostgreSQL", 6379: "Redis", 27017: "MongoDB", 9200: "Elasticsearch"}
+
+	// Resource types and the attribute that must be true for encryption at rest
+	terraformEncryptionAttributes = map[string]string{
+		"aws_ebs_volume":                    "encrypted",
+		"aws_efs_file_system":               "encrypted",
+		"aws_db_instance":                   "storage_encrypted",
+		"aws_rds_cluster":                   "storage_encrypted",
+		"aws_docdb_cluster":                 "storage_encrypted",
+		"aws_neptune_cluster":               "storage_encrypted",
+		"aws_redshift_cluster":              "encrypted",
+		"aws_elasticache_replication_group": "at_rest_encryption_enabled",
+	}
+)
+
+// Run the built-in IaC rule set over every Terraform module and flag committed state files
+func terraformFindings() []Finding {
+	var findings []Finding
+	for _, module := range loadTerraformModules() {
+		findings = append(findings, lintTerraformModule(module)...)
+	}
+	findings = append(findings, committedTerraformStateFindings()...)
+	return findings
+}
+
+func lintTerraformModule(module *TerraformModule) []Finding {
+	var findings []Finding
+	for _, resource := range module.Resources {
+		add := func(ruleID, description, severity, message string, line int) {
+			findings = append(findings, newFinding("terraform/"+ruleID, description, categorySecurity, severity,
+				fmt.Sprintf("%s.%s: %s", resource.Type, resource.Name, message), resource.File, line,
+				resource.Mode+"."+resource.Type+"."+resource.Name))
+		}
+		block := resource.Block
+
+		if resource.Mode == "data" {
+			if resource.Type == "aws_iam_policy_document" {
+				for _, statement := range block.BlocksOfType("statement") {
+					policy := map[string]interface{}{
+						"Effect":   orDefault(statement.AttributeString("effect"), "Allow"),
+						"Action":   statement.Attributes["actions"].valueOrNil(),
+						"Resource": statement.Attributes["resources"].valueOrNil(),
+					}
+					for _, problem := range iamStatementProblems(policy) {
+						add("iam-wildcard", "IAM policy grants wildcard permissions", problem.severity, problem.message, statement.Line)
+					}
+				}
+			}
+			continue
+		}
+
+		switch resource.Type {
+		case "aws_s3_bucket", "aws_s3_bucket_acl":
+			if acl := block.AttributeString("acl"); publicS3ACLs[acl] {
+				add("s3-public-bucket", "S3 bucket is publicly accessible", "high",
+					fmt.Sprintf("bucket ACL %q grants public access", acl), block.Attributes["acl"].Line)
+			}
+		case "aws_s3_bucket_public_access_block", "aws_s3_account_public_access_block":
+			for _, setting := range []string{"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"} {
+				if value, ok := block.Attributes[setting].valueOrNil().(bool); ok && !value {
+					add("s3-public-bucket", "S3 bucket is publicly accessible", "medium",
+						fmt.Sprintf("%s is disabled", setting), block.Attributes[setting].Line)
+				}
+			}
+		case "aws_s3_bucket_policy":
+			if attribute := block.Attributes["policy"]; attribute != nil && terraformPolicyHasPublicPrincipal(attribute) {
+				add("s3-public-bucket", "S3 bucket is publicly accessible", "high", "bucket policy allows any principal (*)", attribute.Line)
+			}
+		case "aws_security_group":
+			for _, rule := range block.BlocksOfType("ingress") {
+				if message, severity := openIngress(rule, "cidr_blocks", "ipv6_cidr_blocks"); message != "" {
+					add("open-security-group", "Security group allows ingress from anywhere", severity, message, rule.Line)
+				}
+			}
+		case "aws_security_group_rule":
+			if block.AttributeString("type") == "ingress" {
+				if message, severity := openIngress(block, "cidr_blocks", "ipv6_cidr_blocks"); message != "" {
+					add("open-security-group", "Security group allows ingress from anywhere", severity, message, block.Line)
+				}
+			}
+		case "aws_vpc_security_group_ingress_rule":
+			if message, severity := openIngress(block, "cidr_ipv4", "cidr_ipv6"); message != "" {
+				add("open-security-group", "Security group allows ingress from anywhere", severity, message, block.Line)
+			}
+		case "google_compute_firewall":
+			if direction := block.AttributeString("direction"); (direction == "" || direction == "INGRESS") && len(block.BlocksOfType("allow")) > 0 {
+				if message, severity := openIngress(block, "source_ranges"); message != "" {
+					add("open-security-group", "Firewall allows ingress from anywhere", severity, message, block.Line)
+				}
+			}
+		case "azurerm_network_security_rule":
+			source := strings.ToLower(block.AttributeString("source_address_prefix"))
+			if block.AttributeString("direction") == "Inbound" && block.AttributeString("access") == "Allow" &&
+				(source == "*" || source == "0.0.0.0/0" || source == "internet") {
+				add("open-security-group", "Network security rule allows ingress from anywhere", "high",
+					fmt.Sprintf("inbound rule allows %s to port(s) %s", source, orDash(block.AttributeString("destination_port_range"))), block.Line)
+			}
+		case "aws_iam_policy", "aws_iam_role_policy", "aws_iam_user_policy", "aws_iam_group_policy":
+			if attribute := block.Attributes["policy"]; attribute != nil {
+				for _, statement := range terraformPolicyStatements(attribute) {
+					for _, problem := range iamStatementProblems(statement) {
+						add("iam-wildcard", "IAM policy grants wildcard permissions", problem.severity, problem.message, attribute.Line)
+					}
+				}
+			}
+		}
+
+		if attribute, ok := terraformEncryptionAttributes[resource.Type]; ok {
+			if value, isBool := block.Attributes[attribute].valueOrNil().(bool); block.Attributes[attribute] == nil || (isBool && !value) {
+				add("unencrypted-storage", "Storage is not encrypted at rest", "medium",
+					fmt.Sprintf("%s is not enabled", attribute), block.Line)
+			}
+		}
+		for _, device := range append(block.BlocksOfType("root_block_device"), block.BlocksOfType("ebs_block_device")...) {
+			if value, ok := device.Attributes["encrypted"].valueOrNil().(bool); ok && !value {
+				add("unencrypted-storage", "Storage is not encrypted at rest", "medium",
+					fmt.Sprintf("%s has encrypted = false", device.Type), device.Line)
+			}
+		}
+	}
+
+	// Credentials written directly into provider configuration
+	for _, provider := range module.providerBlocks {
+		for _, name := range provider.block.AttributeNames() {
+			attribute := provider.block.Attributes[name]
+			value, isString := attribute.Value.(string)
+			if !isString || value == "" || strings.Contains(value, "${") || !terraformCredentialAttribute(name) {
+				continue
+			}
+			findings = append(findings, newFinding("terraform/hardcoded-credentials", "Credentials hard-coded in provider configuration",
+				categorySecurity, "critical", fmt.Sprintf("provider %q sets %s to a literal value (%s)", provider.block.Label(0), name, maskSecret(value)),
+				provider.file, attribute.Line, provider.block.Label(0)+"/"+name))
+		}
+	}
+	return findings
+}
+
+func terraformCredentialAttribute(name string) bool {
+	if name == "token" || name == "password" {
+		return true
+	}
+	return dockerSecretNameRegex.MatchString(name) && !strings.HasSuffix(name, "_file") && !strings.HasSuffix(name, "_path")
+}
+
+// Describe an ingress rule open to the internet, or return an empty message
+func openIngress(block *HCLBlock, cidrAttributes ...string) (string, string) {
+	open := ""
+	for _, name := range cidrAttributes {
+		attribute := block.Attributes[name]
+		if attribute == nil {
+			continue
+		}
+		values := []interface{}{attribute.Value}
+		if list, ok := attribute.Value.([]interface{}); ok {
+			values = list
+		}
+		for _, value := range values {
+			if cidr := yamlString(value); cidr == "0.0.0.0/0" || cidr == "::/0" {
+				open = cidr
+			}
+		}
+	}
+	if open == "" {
+		return "", ""
+	}
+
+	if protocol := block.AttributeString("protocol"); protocol == "-1" || protocol == "all" {
+		return fmt.Sprintf("ingress from %s to all ports", open), "high"
+	}
+
+	fromPort, fromKnown := block.Attributes["from_port"].valueOrNil().(int)
+	toPort, toKnown := block.Attributes["to_port"].valueOrNil().(int)
+	for _, allow := range block.BlocksOfType("allow") {
+		ports, _ := allow.Attributes["ports"].valueOrNil().([]interface{})
+		if len(ports) == 0 {
+			return fmt.Sprintf("ingress from %s to all ports", open), "high"
+		}
+		for _, port := range ports {
+			if number, ok := evalHCLLiteral(yamlString(port)); ok {
+				if p, isInt := number.(int); isInt {
+					fromPort, toPort, fromKnown, toKnown = p, p, true, true
+				}
+			}
+		}
+	}
+	if !fromKnown || !toKnown {
+		return fmt.Sprintf("ingress from %s", open), "medium"
+	}
+	if (fromPort == 0 && toPort == 0) || (fromPort <= 1 && toPort == 65535) {
+		return fmt.Sprintf("ingress from %s to all ports", open), "high"
+	}
+	ports := make([]int, 0, len(sensitivePorts))
+	for port := range sensitivePorts {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	for _, port := range ports {
+		if fromPort <= port && port <= toPort {
+			return fmt.Sprintf("ingress from %s to %s port %d", open, sensitivePorts[port], port), "high"
+		}
+	}
+	return fmt.Sprintf("ingress from %s to port(s) %d-%d", open, fromPort, toPort), "medium"
+}
+
+type iamProblem struct {
+	severity string
+	message  string
+}
+
+func iamStatementProblems(statement map[string]interface{}) []iamProblem {
+	if effect := yamlString(statement["Effect"]); effect != "" && effect != "Allow" {
+		return nil
+	}
+	actions := policyValues(statement["Action"])
+	resources := policyValues(statement["Resource"])
+
+	var problems []iamProblem
+	allResources := false
+	for _, resource := range resources {
+		if resource == "*" {
+			allResources = true
+		}
+	}
+	for _, action := range actions {
+		switch {
+		case action == "*" || action == "*:*":
+			problems = append(problems, iamProblem{"high", "policy allows all actions (\"*\")"})
+		case strings.HasSuffix(action, ":*") && allResources:
+			problems = append(problems, iamProblem{"medium", fmt.Sprintf("policy allows %s on all resources", action)})
+		}
+	}
+	return problems
+}
+
+func policyValues(value interface{}) []string {
+	var values []string
+	switch v := value.(type) {
+	case string:
+		values = append(values, v)
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, yamlString(item))
+		}
+	}
+	return values
+}
+
+// Statements of a policy written as a JSON string, heredoc or jsonencode({...})
+func terraformPolicyStatements(attribute *HCLAttribute) []map[string]interface{} {
+	var document map[string]interface{}
+	switch value := attribute.Value.(type) {
+	case string:
+		if err := json.Unmarshal([]byte(value), &document); err != nil {
+			return nil
+		}
+	case HCLExpression:
+		raw := strings.TrimSpace(string(value))
+		if !strings.HasPrefix(raw, "jsonencode(") || !strings.HasSuffix(raw, ")") {
+			return nil
+		}
+		inner, _ := evalHCLLiteral(raw[len("jsonencode(") : len(raw)-1])
+		document = yamlMap(inner)
+	}
+
+	var statements []map[string]interface{}
+	switch statement := document["Statement"].(type) {
+	case map[string]interface{}:
+		statements = append(statements, statement)
+	case []interface{}:
+		for _, item := range statement {
+			if m := yamlMap(item); m != nil {
+				statements = append(statements, m)
+			}
+		}
+	}
+	return statements
+}
+
+func terraformPolicyHasPublicPrincipal(attribute *HCLAttribute) bool {
+	for _, statement := range terraformPolicyStatements(attribute) {
+		if yamlString(statement["Effect"]) != "Allow" {
+			continue
+		}
+		switch principal := statement["Principal"].(type) {
+		case string:
+			if principal == "*" {
+				return true
+			}
+		case map[string]interface{}:
+			for _, value := range policyValues(principal["AWS"]) {
+				if value == "*" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// State files hold every resource attribute in plaintext, including generated passwords and keys
+func committedTerraformStateFindings() []Finding {
+	output, err := runCommandOutput("git", "ls-files", "-z", "--", "*.tfstate", "*.tfstate.backup")
+	if err != nil {
+		return nil
+	}
+	var findings []Finding
+	for _, path := range strings.Split(output, "\x00") {
+		if path == "" {
+			continue
+		}
+		findings = append(findings, newFinding("terraform/committed-state", "Terraform state file committed to the repository",
+			categorySecurity, "high", "Terraform state is committed; it contains resource attributes and secrets in plaintext", path, 0, ""))
+	}
+	return findings
+}
+
+func writeTerraformFindings(output io.StringWriter, findings []Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	output.WriteString("Terraform security issues:\n")
+	for _, finding := range findings {
+		location := finding.File
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", finding.File, finding.Line)
+		}
+		output.WriteString(fmt.Sprintf("  - [%s] %s: %s\n", finding.Severity, location, finding.Message))
+	}
+	output.WriteString("\n")
+}
@@ -0,0 +1,74 @@
+package grabitsh
+
+import "testing"
+
+func lintTerraformTestModule(t *testing.T, content string) []Finding {
+	t.Helper()
+	body, err := parseHCL(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := &TerraformModule{Dir: "."}
+	module.addFile("main.tf", body)
+	return lintTerraformModule(module)
+}
+
+func TestOpenIngressSensitivePortOrder(t *testing.T) {
+	content := `resource "aws_security_group_rule" "wide" {
+  type        = "ingress"
+  from_port   = 0
+  to_port     = 30000
+  protocol    = "tcp"
+  cidr_blocks = ["0.0.0.0/0"]
+}
+`
+	// Several sensitive ports fall in the range; the lowest one is always reported
+	for i := 0; i < 20; i++ {
+		findings := lintTerraformTestModule(t, content)
+		if len(findings) != 1 {
+			t.Fatalf("got %d findings, want 1", len(findings))
+		}
+		if want := "aws_security_group_rule.wide: ingress from 0.0.0.0/0 to SSH port 22"; findings[0].Message != want {
+			t.Fatalf("message = %q, want %q", findings[0].Message, want)
+		}
+	}
+}
+
+func TestTerraformFindingFingerprintIgnoresMessage(t *testing.T) {
+	rule := func(cidr, port string) string {
+		return `resource "aws_security_group_rule" "db" {
+  type        = "ingress"
+  from_port   = ` + port + `
+  to_port     = ` + port + `
+  protocol    = "tcp"
+  cidr_blocks = ["` + cidr + `"]
+}
+`
+	}
+	first := lintTerraformTestModule(t, rule("0.0.0.0/0", "5432"))
+	second := lintTerraformTestModule(t, rule("::/0", "3306"))
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("got %d and %d findings, want 1 each", len(first), len(second))
+	}
+	if first[0].Message == second[0].Message {
+		t.Fatalf("messages should differ, both %q", first[0].Message)
+	}
+	if first[0].Fingerprint != second[0].Fingerprint {
+		t.Errorf("fingerprints differ for the same resource and rule: %s, %s", first[0].Fingerprint, second[0].Fingerprint)
+	}
+}
+
+func TestWithoutCoveredLocations(t *testing.T) {
+	secrets := []Finding{
+		newFinding("secret/aws-access-key-id", "AWS access key ID", categorySecurity, "high", "", "main.tf", 3, ""),
+	}
+	findings := []Finding{
+		newFinding("terraform/hardcoded-credentials", "", categorySecurity, "critical", "access_key", "main.tf", 3, "aws/access_key"),
+		newFinding("terraform/hardcoded-credentials", "", categorySecurity, "critical", "token", "main.tf", 5, "aws/token"),
+		newFinding("terraform/open-security-group", "", categorySecurity, "high", "ingress", "main.tf", 3, "resource.x.y"),
+	}
+	kept := withoutCoveredLocations(findings, secrets, "terraform/hardcoded-credentials")
+	if len(kept) != 2 || kept[0].Line != 5 || kept[1].RuleID != "terraform/open-security-group" {
+		t.Errorf("kept %v, want the token credential and the security group finding", kept)
+	}
+}
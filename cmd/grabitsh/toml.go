@@ -0,0 +1,412 @@
+package grabitsh
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Minimal TOML reader covering what lockfiles and manifests use: tables, arrays of tables,
+// dotted keys, strings, numbers, booleans, arrays and inline tables. Dates are kept as strings.
+type tomlParser struct {
+	input []rune
+	pos   int
+	line  int
+}
+
+func parseTOML(content string) (map[string]interface{}, error) {
+	p := &tomlParser{input: []rune(content), line: 1}
+	root := make(map[string]interface{})
+	current := root
+
+	for {
+		p.skipWhitespaceAndComments(true)
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() == '[' {
+			arrayTable := p.peekAt(1) == '['
+			if arrayTable {
+				p.pos += 2
+			} else {
+				p.pos++
+			}
+			path, err := p.parseKeyPath()
+			if err != nil {
+				return nil, err
+			}
+			closing := "]"
+			if arrayTable {
+				closing = "]]"
+			}
+			if !p.consume(closing) {
+				return nil, p.errorf("expected %q", closing)
+			}
+
+			table, err := tomlResolveTable(root, path, arrayTable)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			current = table
+		} else {
+			path, err := p.parseKeyPath()
+			if err != nil {
+				return nil, err
+			}
+			p.skipWhitespaceAndComments(false)
+			if !p.consume("=") {
+				return nil, p.errorf("expected '=' after key %q", strings.Join(path, "."))
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			parent, err := tomlResolveTable(current, path[:len(path)-1], false)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			parent[path[len(path)-1]] = value
+		}
+
+		p.skipWhitespaceAndComments(false)
+		if !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+			return nil, p.errorf("unexpected character %q", p.peek())
+		}
+	}
+}
+
+func tomlResolveTable(root map[string]interface{}, path []string, appendArray bool) (map[string]interface{}, error) {
+	current := root
+	for i, key := range path {
+		last := i == len(path)-1
+		existing := current[key]
+
+		if last && appendArray {
+			table := make(map[string]interface{})
+			array, _ := existing.([]interface{})
+			current[key] = append(array, table)
+			return table, nil
+		}
+
+		switch value := existing.(type) {
+		case nil:
+			table := make(map[string]interface{})
+			current[key] = table
+			current = table
+		case map[string]interface{}:
+			current = value
+		case []interface{}:
+			if len(value) == 0 {
+				return nil, fmt.Errorf("key %q is an empty array", key)
+			}
+			table, ok := value[len(value)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("key %q is not a table", key)
+			}
+			current = table
+		default:
+			return nil, fmt.Errorf("key %q is already defined as a value", key)
+		}
+	}
+	return current, nil
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *tomlParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *tomlParser) peekAt(offset int) rune {
+	if p.pos+offset >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos+offset]
+}
+
+func (p *tomlParser) consume(s string) bool {
+	runes := []rune(s)
+	if p.pos+len(runes) > len(p.input) || string(p.input[p.pos:p.pos+len(runes)]) != s {
+		return false
+	}
+	p.pos += len(runes)
+	return true
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) skipWhitespaceAndComments(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.line++
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) parseKeyPath() ([]string, error) {
+	var path []string
+	for {
+		p.skipWhitespaceAndComments(false)
+		var key string
+		switch p.peek() {
+		case '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("expected key")
+			}
+			key = string(p.input[start:p.pos])
+		}
+		path = append(path, key)
+
+		p.skipWhitespaceAndComments(false)
+		if p.peek() != '.' {
+			return path, nil
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKeyChar(c rune) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	p.skipWhitespaceAndComments(false)
+	switch c := p.peek(); {
+	case c == '"':
+		return p.parseBasicString()
+	case c == '\'':
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	default:
+		start := p.pos
+		for !p.eof() && !strings.ContainsRune(",]}#\n\r", p.peek()) {
+			p.pos++
+		}
+		token := strings.TrimSpace(string(p.input[start:p.pos]))
+		switch token {
+		case "":
+			return nil, p.errorf("expected value")
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		cleaned := strings.ReplaceAll(token, "_", "")
+		if i, err := strconv.ParseInt(cleaned, 0, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(cleaned, 64); err == nil {
+			return f, nil
+		}
+		return token, nil
+	}
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	multiline := p.consume(`"""`)
+	if !multiline {
+		p.pos++
+	} else if p.peek() == '\n' {
+		p.line++
+		p.pos++
+	}
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		if multiline && p.consume(`"""`) {
+			return sb.String(), nil
+		}
+		if !multiline && c == '"' {
+			p.pos++
+			return sb.String(), nil
+		}
+		if c == '\n' {
+			if !multiline {
+				return "", p.errorf("newline in string")
+			}
+			p.line++
+		}
+		if c == '\\' {
+			p.pos++
+			escaped := p.peek()
+			p.pos++
+			switch escaped {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			case '"', '\\':
+				sb.WriteRune(escaped)
+			case 'u', 'U':
+				size := 4
+				if escaped == 'U' {
+					size = 8
+				}
+				if p.pos+size > len(p.input) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(string(p.input[p.pos:p.pos+size]), 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				sb.WriteRune(rune(code))
+				p.pos += size
+			case '\n', ' ', '\t', '\r':
+				// Line-ending backslash trims whitespace up to the next non-blank character
+				if escaped == '\n' {
+					p.line++
+				}
+				for !p.eof() && strings.ContainsRune(" \t\r\n", p.peek()) {
+					if p.peek() == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+			default:
+				sb.WriteRune('\\')
+				sb.WriteRune(escaped)
+			}
+			continue
+		}
+		sb.WriteRune(c)
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	multiline := p.consume(`'''`)
+	if !multiline {
+		p.pos++
+	} else if p.peek() == '\n' {
+		p.line++
+		p.pos++
+	}
+
+	start := p.pos
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		if multiline && p.consume(`'''`) {
+			return string(p.input[start : p.pos-3]), nil
+		}
+		if !multiline && p.peek() == '\'' {
+			p.pos++
+			return string(p.input[start : p.pos-1]), nil
+		}
+		if p.peek() == '\n' {
+			if !multiline {
+				return "", p.errorf("newline in string")
+			}
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	array := []interface{}{}
+	for {
+		p.skipWhitespaceAndComments(true)
+		if p.peek() == ']' {
+			p.pos++
+			return array, nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+
+		p.skipWhitespaceAndComments(true)
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.peek() != ']' {
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+	for {
+		p.skipWhitespaceAndComments(false)
+		if p.peek() == '}' {
+			p.pos++
+			return table, nil
+		}
+		path, err := p.parseKeyPath()
+		if err != nil {
+			return nil, err
+		}
+		p.skipWhitespaceAndComments(false)
+		if !p.consume("=") {
+			return nil, p.errorf("expected '=' in inline table")
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		parent, err := tomlResolveTable(table, path[:len(path)-1], false)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		parent[path[len(path)-1]] = value
+
+		p.skipWhitespaceAndComments(false)
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.peek() != '}' {
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package grabitsh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	content := `# Cargo.lock style
+version = 3
+name = "demo" # trailing comment
+literal = 'C:\path'
+escaped = "tab\tunicode\u00e9"
+multi = """
+first
+second"""
+big = 1_000
+ratio = 0.25
+enabled = true
+released = 2024-01-02
+list = [1, 2,
+  3,]
+inline = { path = "../b", features = ["x"] }
+site."google.com" = "dotted"
+
+[tool.poetry]
+name = "pkg"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+
+[[package]]
+name = "a"
+version = "1.0.0"
+
+[[package]]
+name = "b"
+version = "2.0.0"
+
+[package.source]
+type = "git"
+`
+	got, err := parseTOML(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"version":  int64(3),
+		"name":     "demo",
+		"literal":  `C:\path`,
+		"escaped":  "tab\tunicodeé",
+		"multi":    "first\nsecond",
+		"big":      int64(1000),
+		"ratio":    0.25,
+		"enabled":  true,
+		"released": "2024-01-02",
+		"list":     []interface{}{int64(1), int64(2), int64(3)},
+		"inline":   map[string]interface{}{"path": "../b", "features": []interface{}{"x"}},
+		"site":     map[string]interface{}{"google.com": "dotted"},
+		"tool": map[string]interface{}{"poetry": map[string]interface{}{
+			"name":         "pkg",
+			"dependencies": map[string]interface{}{"python": "^3.11"},
+		}},
+		"package": []interface{}{
+			map[string]interface{}{"name": "a", "version": "1.0.0"},
+			map[string]interface{}{"name": "b", "version": "2.0.0", "source": map[string]interface{}{"type": "git"}},
+		},
+	}
+	for key, value := range want {
+		if !reflect.DeepEqual(got[key], value) {
+			t.Errorf("%s = %#v, want %#v", key, got[key], value)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d top-level keys, want %d", len(got), len(want))
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, content := range []string{
+		"name \"missing equals\"\n",
+		"[table\nkey = 1\n",
+		"a = 1\n[a]\nb = 2\n",
+		"a = \"unterminated\n",
+	} {
+		if _, err := parseTOML(content); err == nil {
+			t.Errorf("parseTOML(%q) succeeded, want an error", content)
+		}
+	}
+}
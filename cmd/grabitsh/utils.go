@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -82,15 +84,26 @@ func parseYAMLFile(filename string, buffer *bytes.Buffer) {
 		return
 	}
 
-	var parsed map[string]interface{}
-	if err := yaml.Unmarshal(fileContent, &parsed); err != nil {
+	documents, err := decodeYAMLDocuments(string(fileContent))
+	if err != nil {
 		buffer.WriteString(fmt.Sprintf("Error parsing %s: %v\n", filename, err))
 		return
 	}
 
 	buffer.WriteString(fmt.Sprintf("\nParsed %s YAML:\n", filename))
-	for key, value := range parsed {
-		buffer.WriteString(fmt.Sprintf("  %s: %v\n", key, value))
+	for i, document := range documents {
+		if len(documents) > 1 {
+			buffer.WriteString(fmt.Sprintf("  Document %d:\n", i+1))
+		}
+		parsed := yamlMap(document)
+		keys := make([]string, 0, len(parsed))
+		for key := range parsed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			buffer.WriteString(fmt.Sprintf("  %s: %v\n", key, parsed[key]))
+		}
 	}
 }
 
@@ -151,64 +164,23 @@ func parseGithubActionsWorkflows(directory string, buffer *bytes.Buffer) {
 	}
 }
 
-func parseDockerfile(filename string, buffer *bytes.Buffer) {
-	fileContent, err := os.ReadFile(filename)
-	if err != nil {
-		buffer.WriteString(fmt.Sprintf("Error reading %s: %v\n", filename, err))
-		return
-	}
-	lines := strings.Split(string(fileContent), "\n")
-	buffer.WriteString("Dockerfile (first 10 lines):\n")
-	for i := 0; i < 10 && i < len(lines); i++ {
-		buffer.WriteString(fmt.Sprintf("%s\n", lines[i]))
-	}
-}
-
 func parseDockerDir(directory string, buffer *bytes.Buffer) {
 	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, "Dockerfile") {
-			buffer.WriteString(fmt.Sprintf("\nDocker-related file found: %s\n", path))
-			parseYAMLFile(path, buffer)
-		}
-		return nil
-	})
-	if err != nil {
-		buffer.WriteString(fmt.Sprintf("Error walking through Docker directory: %v\n", err))
-	}
-}
-
-func parseK8sFiles(directory string, buffer *bytes.Buffer) {
-	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-			buffer.WriteString(fmt.Sprintf("\nKubernetes file found: %s\n", path))
-			parseYAMLFile(path, buffer)
-		}
-		return nil
-	})
-	if err != nil {
-		buffer.WriteString(fmt.Sprintf("Error walking through Kubernetes directory: %v\n", err))
-	}
-}
-
-func parseHelmFiles(directory string, buffer *bytes.Buffer) {
-	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+		if isComposeFileName(info.Name()) {
+			return nil
 		}
+		// Dockerfiles are covered by the Dockerfile analysis section
 		if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-			buffer.WriteString(fmt.Sprintf("\nHelm chart file found: %s\n", path))
+			buffer.WriteString(fmt.Sprintf("\nDocker-related file found: %s\n", path))
 			parseYAMLFile(path, buffer)
 		}
 		return nil
 	})
 	if err != nil {
-		buffer.WriteString(fmt.Sprintf("Error walking through Helm directory: %v\n", err))
+		buffer.WriteString(fmt.Sprintf("Error walking through Docker directory: %v\n", err))
 	}
 }
 
@@ -256,3 +228,239 @@ func parsePackageJSON(filename string, buffer *bytes.Buffer) error {
 	}
 	return nil
 }
+
+func runCommandOutput(name string, arg ...string) (string, error) {
+	out, err := exec.Command(name, arg...).Output()
+	return string(out), err
+}
+
+// Convert a gitignore-style glob (supporting ** and trailing /) into a regular expression.
+// Patterns without a slash match against the base name anywhere in the tree.
+func globToRegexp(pattern string) *regexp.Regexp {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("(?:^|/)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				if i+2 < len(pattern) && pattern[i+2] == '/' {
+					expr.WriteString("(?:.*/)?")
+					i += 2
+				} else {
+					expr.WriteString(".*")
+					i++
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dirOnly {
+		expr.WriteString("/")
+	} else {
+		expr.WriteString("(?:$|/)")
+	}
+	return regexp.MustCompile(expr.String())
+}
+
+func matchesPathPattern(pattern, path string) bool {
+	return globToRegexp(pattern).MatchString(filepath.ToSlash(path))
+}
+
+// List files tracked by git, falling back to walking the tree outside a git repository
+func listRepositoryFiles() []string {
+	if out, err := runCommandOutput("git", "ls-files", "-z"); err == nil {
+		var files []string
+		for _, file := range strings.Split(out, "\x00") {
+			if file != "" && fileExists(file) {
+				files = append(files, file)
+			}
+		}
+		return files
+	}
+
+	var files []string
+	_ = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != "." && (info.Name() == ".git" || shouldExcludeDir(info.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, filepath.ToSlash(path))
+		return nil
+	})
+	return files
+}
+
+func isBinaryContent(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) != -1
+}
+
+// Convert the map[interface{}]interface{} values produced by yaml.v2 into map[string]interface{}
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[fmt.Sprintf("%v", key)] = normalizeYAML(item)
+		}
+		return normalized
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	}
+	return value
+}
+
+func yamlMap(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
+
+func yamlString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func yamlList(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
+
+// Keys of a map or items of a list, for fields that accept both forms
+func yamlKeysOrItems(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key := range v {
+			items = append(items, key)
+		}
+		sort.Strings(items)
+	case []interface{}:
+		for _, item := range v {
+			items = append(items, yamlString(item))
+		}
+	case string:
+		items = append(items, v)
+	}
+	return items
+}
+
+type yamlDocument struct {
+	// 1-based line of the first line of the document
+	Line    int
+	Content string
+}
+
+var yamlDocumentSeparator = regexp.MustCompile(`^---(\s.*)?$`)
+
+// Split a YAML stream on document separators, keeping track of where each document starts
+func splitYAMLDocuments(content string) []yamlDocument {
+	var documents []yamlDocument
+	var current []string
+	start := 1
+
+	flush := func() {
+		text := strings.Join(current, "\n")
+		if strings.TrimSpace(text) != "" {
+			documents = append(documents, yamlDocument{Line: start, Content: text})
+		}
+		current = nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		if yamlDocumentSeparator.MatchString(trimmed) || trimmed == "..." {
+			flush()
+			start = i + 2
+			// Content after "--- " on the same line belongs to the next document
+			if rest := strings.TrimSpace(strings.TrimPrefix(trimmed, "---")); trimmed != "..." && rest != "" && !strings.HasPrefix(rest, "#") {
+				current = append(current, rest)
+				start = i + 1
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return documents
+}
+
+// Decode every document of a YAML stream into normalized values
+func decodeYAMLDocuments(content string) ([]interface{}, error) {
+	var values []interface{}
+	for _, document := range splitYAMLDocuments(content) {
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(document.Content), &value); err != nil {
+			return values, fmt.Errorf("document at line %d: %w", document.Line, err)
+		}
+		if value != nil {
+			values = append(values, normalizeYAML(value))
+		}
+	}
+	return values, nil
+}
+
+// Walk the working tree, skipping .git and dependency directories, and return matching files sorted
+func findRepositoryFiles(match func(name string) bool) []string {
+	var files []string
+	_ = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != "." && (info.Name() == ".git" || shouldExcludeDir(info.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match(info.Name()) {
+			files = append(files, filepath.ToSlash(path))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
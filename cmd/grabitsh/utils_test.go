@@ -0,0 +1,60 @@
+package grabitsh
+
+import "testing"
+
+func TestMatchesPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.pem", "server.pem", true},
+		{"*.pem", "certs/server.pem", true},
+		{"*.pem", "server.pem.txt", false},
+		{"id_rsa", "home/.ssh/id_rsa", true},
+		{"id_rsa", "id_rsa.pub", false},
+		{".env.*", "app/.env.local", true},
+		{"docs/*.md", "docs/readme.md", true},
+		{"docs/*.md", "docs/api/readme.md", false},
+		{"docs/*.md", "site/docs/readme.md", false},
+		{"/build", "build/out.js", true},
+		{"/build", "src/build/out.js", false},
+		{"vendor/", "vendor/lib.go", true},
+		{"vendor/", "vendor", false},
+		{"src/**/test", "src/test", true},
+		{"src/**/test", "src/a/b/test/x.go", true},
+		{"a?c", "abc", true},
+		{"a?c", "a/c", false},
+		{"**/.kube/config", ".kube/config", true},
+		{"**/.kube/config", "deploy/.kube/config", true},
+		{".kube/config", "deploy/.kube/config", false},
+	}
+	for _, test := range tests {
+		if got := matchesPathPattern(test.pattern, test.path); got != test.want {
+			t.Errorf("matchesPathPattern(%q, %q) = %v, want %v", test.pattern, test.path, got, test.want)
+		}
+	}
+}
+
+func TestDefaultSensitiveFilePatterns(t *testing.T) {
+	tests := []struct {
+		path, category string
+	}{
+		{".env", "environment file"},
+		{"deploy/.kube/config", "kubeconfig"},
+		{"ops/.aws/credentials", "cloud credentials"},
+		{"infra/terraform.tfstate", "terraform state"},
+		{"README.md", ""},
+	}
+	for _, test := range tests {
+		category := ""
+		for _, p := range defaultSensitiveFilePatterns {
+			if matchesPathPattern(p.pattern, test.path) {
+				category = p.category
+				break
+			}
+		}
+		if category != test.category {
+			t.Errorf("%s: category %q, want %q", test.path, category, test.category)
+		}
+	}
+}
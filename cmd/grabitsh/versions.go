@@ -0,0 +1,137 @@
+package grabitsh
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PEP 440 post-release segment, as in 1.2.0.post1 or 1.2.0-post2
+var postReleaseRegex = regexp.MustCompile(`(?i)[._-]?post[._-]?(\d*)$`)
+
+// Compare two version strings across ecosystems (semver, PEP 440-ish, RubyGems, Maven).
+// Numeric components compare numerically, a pre-release sorts before its release and a post-release after it.
+func compareVersions(a, b string) int {
+	a, b = normalizeVersion(a), normalizeVersion(b)
+	if a == b {
+		return 0
+	}
+
+	a, postA := splitPostRelease(a)
+	b, postB := splitPostRelease(b)
+	releaseA, preA := splitPrerelease(a)
+	releaseB, preB := splitPrerelease(b)
+
+	if c := compareVersionParts(splitVersionParts(releaseA), splitVersionParts(releaseB)); c != 0 {
+		return c
+	}
+
+	switch {
+	case preA == "" && preB != "":
+		return 1
+	case preA != "" && preB == "":
+		return -1
+	case preA != "":
+		if c := compareVersionParts(splitVersionParts(preA), splitVersionParts(preB)); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case postA < postB:
+		return -1
+	case postA > postB:
+		return 1
+	}
+	return 0
+}
+
+// Split off a post-release segment; versions without one return -1 so they sort before any post-release
+func splitPostRelease(version string) (string, int) {
+	match := postReleaseRegex.FindStringSubmatchIndex(version)
+	if match == nil || match[0] == 0 {
+		return version, -1
+	}
+	number, _ := strconv.Atoi(version[match[2]:match[3]])
+	return version[:match[0]], number
+}
+
+func normalizeVersion(version string) string {
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	version = strings.TrimPrefix(version, "==")
+	// Build metadata never affects precedence
+	if i := strings.Index(version, "+"); i != -1 {
+		version = version[:i]
+	}
+	return version
+}
+
+// Split "1.2.3-beta.1" or "1.2.3rc1" into release and pre-release parts; post-releases are not pre-releases
+func splitPrerelease(version string) (string, string) {
+	version, _ = splitPostRelease(version)
+	if i := strings.Index(version, "-"); i != -1 {
+		return version[:i], version[i+1:]
+	}
+	for i, c := range version {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			if i > 0 && version[i-1] == '.' {
+				return strings.TrimSuffix(version[:i], "."), version[i:]
+			}
+			return version[:i], version[i:]
+		}
+	}
+	return version, ""
+}
+
+func splitVersionParts(version string) []string {
+	return strings.FieldsFunc(version, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	})
+}
+
+func compareVersionParts(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		partA, partB := "0", "0"
+		if i < len(a) {
+			partA = a[i]
+		}
+		if i < len(b) {
+			partB = b[i]
+		}
+		if c := compareVersionPart(partA, partB); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareVersionPart(a, b string) int {
+	numA, errA := strconv.ParseUint(a, 10, 64)
+	numB, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if numA < numB {
+			return -1
+		} else if numA > numB {
+			return 1
+		}
+		return 0
+	case errA == nil:
+		// Numeric identifiers sort before alphanumeric ones
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Major, minor and patch numbers of a version, missing components are 0
+func versionCore(version string) (int, int, int) {
+	release, _ := splitPrerelease(normalizeVersion(version))
+	parts := splitVersionParts(release)
+	numbers := make([]int, 3)
+	for i := 0; i < 3 && i < len(parts); i++ {
+		numbers[i], _ = strconv.Atoi(parts[i])
+	}
+	return numbers[0], numbers[1], numbers[2]
+}
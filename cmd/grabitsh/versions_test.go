@@ -0,0 +1,75 @@
+package grabitsh
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.3+build.5", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2.3", "1.2.4", -1},
+		{"2.0.0-rc.1", "2.0.0", -1},
+		{"2.0.0-alpha", "2.0.0-beta", -1},
+		{"2.0.0-alpha.2", "2.0.0-alpha.10", -1},
+		{"2.0.0-1", "2.0.0-alpha", -1},
+		{"1.0.0rc1", "1.0.0", -1},
+		{"1.0.0a1", "1.0.0b1", -1},
+		{"1.0.0.dev1", "1.0.0", -1},
+		{"==3.1", "3.1.0", 0},
+		{"1.2.0.post1", "1.2.0", 1},
+		{"1.2.0.post2", "1.2.0.post10", -1},
+		{"1.2.0.post1", "1.2.1", -1},
+		{"1.2.0rc1.post1", "1.2.0rc1", 1},
+		{"1.2.0rc1.post1", "1.2.0", -1},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := compareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestSplitPrerelease(t *testing.T) {
+	tests := []struct {
+		version, release, pre string
+	}{
+		{"1.2.3", "1.2.3", ""},
+		{"1.2.3-beta.1", "1.2.3", "beta.1"},
+		{"1.2.3rc1", "1.2.3", "rc1"},
+		{"1.2.3.dev0", "1.2.3", "dev0"},
+		{"1.2.3.post1", "1.2.3", ""},
+	}
+	for _, test := range tests {
+		release, pre := splitPrerelease(test.version)
+		if release != test.release || pre != test.pre {
+			t.Errorf("splitPrerelease(%q) = %q, %q, want %q, %q", test.version, release, pre, test.release, test.pre)
+		}
+	}
+}
+
+func TestVersionLag(t *testing.T) {
+	tests := []struct {
+		pinned, latest, want string
+	}{
+		{"1.2.3", "1.2.3", "current"},
+		{"1.2.3", "1.2.2", "current"},
+		{"1.2.3", "2.0.0", "major"},
+		{"1.2.3", "1.3.0", "minor"},
+		{"1.2.3", "1.2.4", "patch"},
+		{"1.2.0", "1.2.0.post1", "patch"},
+		{"1.2.0.post1", "1.2.0", "current"},
+	}
+	for _, test := range tests {
+		if got := versionLag(test.pinned, test.latest); got != test.want {
+			t.Errorf("versionLag(%q, %q) = %q, want %q", test.pinned, test.latest, got, test.want)
+		}
+	}
+}
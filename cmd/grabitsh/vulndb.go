@@ -0,0 +1,599 @@
+package grabitsh
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var vulnDBPath string
+
+const vulnDBFlagUsage = "Directory of the local OSV vulnerability database (default: $GRABITSH_VULNDB or the user cache directory)"
+
+var vulndbCmd = &cobra.Command{
+	Use:   "vulndb",
+	Short: "Manage the local OSV vulnerability database used for offline matching",
+}
+
+var vulndbImportCmd = &cobra.Command{
+	Use:   "import <file.json|file.zip|directory>...",
+	Short: "Import OSV advisories from JSON files, directories or zip dumps",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runVulnDBImport,
+}
+
+var vulndbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the advisories stored in the local database",
+	RunE:  runVulnDBStatus,
+}
+
+func init() {
+	vulndbCmd.PersistentFlags().StringVar(&vulnDBPath, "vulndb", "", vulnDBFlagUsage)
+	vulndbCmd.AddCommand(vulndbImportCmd)
+	vulndbCmd.AddCommand(vulndbStatusCmd)
+}
+
+type OSVEntry struct {
+	ID               string                 `json:"id"`
+	Modified         string                 `json:"modified"`
+	Aliases          []string               `json:"aliases,omitempty"`
+	Summary          string                 `json:"summary,omitempty"`
+	Severity         []OSVSeverity          `json:"severity,omitempty"`
+	Affected         []OSVAffected          `json:"affected"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+	Withdrawn        string                 `json:"withdrawn,omitempty"`
+}
+
+type OSVSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type OSVAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+		Purl      string `json:"purl,omitempty"`
+	} `json:"package"`
+	Ranges   []OSVRange `json:"ranges,omitempty"`
+	Versions []string   `json:"versions,omitempty"`
+}
+
+type OSVRange struct {
+	Type   string              `json:"type"`
+	Events []map[string]string `json:"events"`
+}
+
+type VulnerabilityMatch struct {
+	Dependency     Dependency `json:"dependency"`
+	ID             string     `json:"id"`
+	Aliases        []string   `json:"aliases"`
+	Summary        string     `json:"summary"`
+	Severity       string     `json:"severity"`
+	AffectedRanges []string   `json:"affected_ranges"`
+	FixedVersions  []string   `json:"fixed_versions"`
+}
+
+func defaultVulnDBDir() string {
+	if vulnDBPath != "" {
+		return vulnDBPath
+	}
+	if env := os.Getenv("GRABITSH_VULNDB"); env != "" {
+		return env
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(".grabitsh", "vulndb")
+	}
+	return filepath.Join(cacheDir, "grabitsh", "vulndb")
+}
+
+func runVulnDBImport(cmd *cobra.Command, args []string) error {
+	dir := defaultVulnDBDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", dir, err)
+	}
+
+	imported := make(map[string][]OSVEntry)
+	for _, source := range args {
+		if err := readOSVSource(source, func(entry OSVEntry) {
+			for _, ecosystem := range osvEntryEcosystems(entry) {
+				imported[ecosystem] = append(imported[ecosystem], entry)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	ecosystems := make([]string, 0, len(imported))
+	for ecosystem := range imported {
+		ecosystems = append(ecosystems, ecosystem)
+	}
+	sort.Strings(ecosystems)
+
+	for _, ecosystem := range ecosystems {
+		existing, err := loadOSVEcosystem(dir, ecosystem)
+		if err != nil {
+			return err
+		}
+		merged := mergeOSVEntries(existing, imported[ecosystem])
+		if err := saveOSVEcosystem(dir, ecosystem, merged); err != nil {
+			return err
+		}
+		color.Green("%s: imported %d advisories (%d stored)", ecosystem, len(imported[ecosystem]), len(merged))
+	}
+	if len(ecosystems) == 0 {
+		color.Yellow("No OSV advisories found in %s", strings.Join(args, ", "))
+	}
+	return nil
+}
+
+func runVulnDBStatus(cmd *cobra.Command, args []string) error {
+	dir := defaultVulnDBDir()
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) == 0 {
+		color.Yellow("No advisories stored in %s", dir)
+		return nil
+	}
+	for _, file := range files {
+		ecosystem := strings.TrimSuffix(filepath.Base(file), ".json")
+		entries, err := loadOSVFile(file)
+		if err != nil {
+			return err
+		}
+		color.Green("%s: %d advisories", ecosystem, len(entries))
+	}
+	return nil
+}
+
+func readOSVSource(source string, handle func(OSVEntry)) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			if strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".zip") {
+				return readOSVSource(path, handle)
+			}
+			return nil
+		})
+	}
+
+	if strings.HasSuffix(source, ".zip") {
+		archive, err := zip.OpenReader(source)
+		if err != nil {
+			return fmt.Errorf("error opening %s: %w", source, err)
+		}
+		defer archive.Close()
+		for _, file := range archive.File {
+			if !strings.HasSuffix(file.Name, ".json") {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				return fmt.Errorf("error reading %s in %s: %w", file.Name, source, err)
+			}
+			content, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				return fmt.Errorf("error reading %s in %s: %w", file.Name, source, err)
+			}
+			if err := decodeOSVDocument(content, handle); err != nil {
+				return fmt.Errorf("error parsing %s in %s: %w", file.Name, source, err)
+			}
+		}
+		return nil
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	if err := decodeOSVDocument(content, handle); err != nil {
+		return fmt.Errorf("error parsing %s: %w", source, err)
+	}
+	return nil
+}
+
+// An OSV document holds a single advisory or an array of them
+func decodeOSVDocument(content []byte, handle func(OSVEntry)) error {
+	content = bytes.TrimSpace(content)
+	if len(content) > 0 && content[0] == '[' {
+		var entries []OSVEntry
+		if err := json.Unmarshal(content, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.ID != "" {
+				handle(entry)
+			}
+		}
+		return nil
+	}
+
+	var entry OSVEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return err
+	}
+	if entry.ID != "" {
+		handle(entry)
+	}
+	return nil
+}
+
+func osvEntryEcosystems(entry OSVEntry) []string {
+	var ecosystems []string
+	for _, affected := range entry.Affected {
+		// Distribution ecosystems carry a release suffix such as "Debian:11"
+		ecosystem := strings.SplitN(affected.Package.Ecosystem, ":", 2)[0]
+		if ecosystem != "" {
+			ecosystems = appendUnique(ecosystems, ecosystem)
+		}
+	}
+	return ecosystems
+}
+
+func mergeOSVEntries(existing, imported []OSVEntry) []OSVEntry {
+	byID := make(map[string]OSVEntry)
+	for _, entry := range existing {
+		byID[entry.ID] = entry
+	}
+	for _, entry := range imported {
+		if current, ok := byID[entry.ID]; !ok || entry.Modified >= current.Modified {
+			byID[entry.ID] = entry
+		}
+	}
+
+	merged := make([]OSVEntry, 0, len(byID))
+	for _, entry := range byID {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ID < merged[j].ID })
+	return merged
+}
+
+var (
+	unsafeFileChars    = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+)
+
+func osvEcosystemFile(dir, ecosystem string) string {
+	return filepath.Join(dir, unsafeFileChars.ReplaceAllString(ecosystem, "_")+".json")
+}
+
+func loadOSVEcosystem(dir, ecosystem string) ([]OSVEntry, error) {
+	path := osvEcosystemFile(dir, ecosystem)
+	if !fileExists(path) {
+		return nil, nil
+	}
+	return loadOSVFile(path)
+}
+
+func loadOSVFile(path string) ([]OSVEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []OSVEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func saveOSVEcosystem(dir, ecosystem string, entries []OSVEntry) error {
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(osvEcosystemFile(dir, ecosystem), content, 0644)
+}
+
+// Match dependencies against the local OSV database. Returns the number of advisories loaded.
+func matchVulnerabilities(dependencies []Dependency) ([]VulnerabilityMatch, int, error) {
+	dir := defaultVulnDBDir()
+
+	index := make(map[string][]OSVEntry)
+	loaded := make(map[string]bool)
+	advisories := 0
+	for _, dep := range dependencies {
+		if loaded[dep.Ecosystem] {
+			continue
+		}
+		loaded[dep.Ecosystem] = true
+		entries, err := loadOSVEcosystem(dir, dep.Ecosystem)
+		if err != nil {
+			return nil, 0, err
+		}
+		advisories += len(entries)
+		for _, entry := range entries {
+			if entry.Withdrawn != "" {
+				continue
+			}
+			for _, affected := range entry.Affected {
+				if strings.SplitN(affected.Package.Ecosystem, ":", 2)[0] != dep.Ecosystem {
+					continue
+				}
+				key := dep.Ecosystem + "/" + normalizePackageName(dep.Ecosystem, affected.Package.Name)
+				index[key] = append(index[key], entry)
+			}
+		}
+	}
+
+	var matches []VulnerabilityMatch
+	seen := make(map[string]bool)
+	for _, dep := range dependencies {
+		if dep.Version == "" {
+			continue
+		}
+		key := dep.Ecosystem + "/" + normalizePackageName(dep.Ecosystem, dep.Name)
+		for _, entry := range index[key] {
+			matchKey := entry.ID + "|" + key + "|" + dep.Version + "|" + dep.Source
+			if seen[matchKey] {
+				continue
+			}
+			for _, affected := range entry.Affected {
+				if normalizePackageName(dep.Ecosystem, affected.Package.Name) != normalizePackageName(dep.Ecosystem, dep.Name) {
+					continue
+				}
+				if !osvAffectsVersion(affected, dep.Version) {
+					continue
+				}
+				seen[matchKey] = true
+				ranges, fixed := describeOSVRanges(affected)
+				matches = append(matches, VulnerabilityMatch{
+					Dependency:     dep,
+					ID:             entry.ID,
+					Aliases:        entry.Aliases,
+					Summary:        entry.Summary,
+					Severity:       osvSeverity(entry),
+					AffectedRanges: ranges,
+					FixedVersions:  fixed,
+				})
+				break
+			}
+		}
+	}
+
+	return matches, advisories, nil
+}
+
+func normalizePackageName(ecosystem, name string) string {
+	switch ecosystem {
+	case ecosystemPyPI:
+		return strings.ToLower(pypiNameSeparators.ReplaceAllString(name, "-"))
+	case ecosystemPackagist, ecosystemRubyGems:
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+func osvAffectsVersion(affected OSVAffected, version string) bool {
+	for _, v := range affected.Versions {
+		if normalizeVersion(v) == normalizeVersion(version) {
+			return true
+		}
+	}
+
+	for _, r := range affected.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+
+		// Walk the events in version order, toggling whether the version is inside an affected interval
+		events := append([]map[string]string{}, r.Events...)
+		sort.SliceStable(events, func(i, j int) bool {
+			return compareVersions(osvEventVersion(events[i]), osvEventVersion(events[j])) < 0
+		})
+		affectedNow := false
+		for _, event := range events {
+			if introduced, ok := event["introduced"]; ok && (introduced == "0" || compareVersions(version, introduced) >= 0) {
+				affectedNow = true
+			}
+			if fixed, ok := event["fixed"]; ok && compareVersions(version, fixed) >= 0 {
+				affectedNow = false
+			}
+			if last, ok := event["last_affected"]; ok && compareVersions(version, last) > 0 {
+				affectedNow = false
+			}
+			if limit, ok := event["limit"]; ok && compareVersions(version, limit) >= 0 {
+				affectedNow = false
+			}
+		}
+		if affectedNow {
+			return true
+		}
+	}
+
+	return false
+}
+
+func osvEventVersion(event map[string]string) string {
+	for _, key := range []string{"introduced", "fixed", "last_affected", "limit"} {
+		if version, ok := event[key]; ok {
+			return version
+		}
+	}
+	return ""
+}
+
+func describeOSVRanges(affected OSVAffected) ([]string, []string) {
+	var ranges, fixed []string
+	for _, r := range affected.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		var bounds []string
+		for _, event := range r.Events {
+			if v, ok := event["introduced"]; ok {
+				if len(bounds) > 0 {
+					ranges = append(ranges, strings.Join(bounds, ", "))
+				}
+				bounds = []string{">=" + v}
+			}
+			if v, ok := event["fixed"]; ok {
+				bounds = append(bounds, "<"+v)
+				fixed = appendUnique(fixed, v)
+			}
+			if v, ok := event["last_affected"]; ok {
+				bounds = append(bounds, "<="+v)
+			}
+		}
+		if len(bounds) > 0 {
+			ranges = append(ranges, strings.Join(bounds, ", "))
+		}
+	}
+	if len(ranges) == 0 && len(affected.Versions) > 0 {
+		ranges = append(ranges, "versions: "+strings.Join(affected.Versions, ", "))
+	}
+	return ranges, fixed
+}
+
+// Prefer the advisory database's own rating, otherwise derive one from the CVSS v3 vector
+func osvSeverity(entry OSVEntry) string {
+	if severity, ok := entry.DatabaseSpecific["severity"].(string); ok && severity != "" {
+		return strings.ToUpper(severity)
+	}
+	for _, severity := range entry.Severity {
+		if severity.Type == "CVSS_V3" {
+			if score, ok := cvss3BaseScore(severity.Score); ok {
+				return fmt.Sprintf("%s (%.1f)", cvssRating(score), score)
+			}
+			return severity.Score
+		}
+	}
+	return "UNKNOWN"
+}
+
+func cvss3BaseScore(vector string) (float64, bool) {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if key, value, found := strings.Cut(part, ":"); found {
+			metrics[key] = value
+		}
+	}
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	values := make(map[string]float64)
+	for metric, options := range weights {
+		value, ok := options[metrics[metric]]
+		if !ok {
+			return 0, false
+		}
+		values[metric] = value
+	}
+
+	scopeChanged := metrics["S"] == "C"
+	privileges := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if scopeChanged {
+		privileges = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+	pr, ok := privileges[metrics["PR"]]
+	if !ok {
+		return 0, false
+	}
+
+	iss := 1 - (1-values["C"])*(1-values["I"])*(1-values["A"])
+	impact := 6.42 * iss
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	exploitability := 8.22 * values["AV"] * values["AC"] * pr * values["UI"]
+
+	if impact <= 0 {
+		return 0, true
+	}
+	if scopeChanged {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+}
+
+func cvssRoundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return (math.Floor(float64(scaled)/10000) + 1) / 10
+}
+
+func cvssRating(score float64) string {
+	switch {
+	case score == 0:
+		return "NONE"
+	case score < 4:
+		return "LOW"
+	case score < 7:
+		return "MEDIUM"
+	case score < 9:
+		return "HIGH"
+	}
+	return "CRITICAL"
+}
+
+func writeVulnerabilityReport(buffer *bytes.Buffer) {
+	buffer.WriteString("Vulnerable dependencies (offline OSV matching):\n")
+
+	dependencies, errs := collectDependencies()
+	for _, err := range errs {
+		buffer.WriteString(fmt.Sprintf("  %v\n", err))
+	}
+	if len(dependencies) == 0 {
+		buffer.WriteString("  No lockfile dependencies found.\n")
+		return
+	}
+
+	matches, advisories, err := matchVulnerabilities(dependencies)
+	if err != nil {
+		buffer.WriteString(fmt.Sprintf("  Error reading vulnerability database: %v\n", err))
+		return
+	}
+	if advisories == 0 {
+		buffer.WriteString(fmt.Sprintf("  No advisories for these ecosystems in %s. Import an OSV dump with `grabitsh vulndb import <path>`.\n", defaultVulnDBDir()))
+		return
+	}
+
+	buffer.WriteString(fmt.Sprintf("  Checked %d dependencies against %d advisories.\n", len(dependencies), advisories))
+	if len(matches) == 0 {
+		buffer.WriteString("  No known vulnerabilities found.\n")
+		return
+	}
+	for _, match := range matches {
+		ids := append([]string{match.ID}, match.Aliases...)
+		buffer.WriteString(fmt.Sprintf("  [%s] %s %s@%s (%s): %s\n", match.Severity, match.Dependency.Ecosystem,
+			match.Dependency.Name, match.Dependency.Version, match.Dependency.Source, strings.Join(ids, " / ")))
+		if match.Summary != "" {
+			buffer.WriteString(fmt.Sprintf("      %s\n", match.Summary))
+		}
+		buffer.WriteString(fmt.Sprintf("      affected: %s\n", strings.Join(match.AffectedRanges, "; ")))
+		if len(match.FixedVersions) > 0 {
+			buffer.WriteString(fmt.Sprintf("      fixed in: %s\n", strings.Join(match.FixedVersions, ", ")))
+		} else {
+			buffer.WriteString("      no fixed version available\n")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package grabitsh
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestOSVAffectsVersion(t *testing.T) {
+	semver := func(events ...map[string]string) OSVAffected {
+		return OSVAffected{Ranges: []OSVRange{{Type: "SEMVER", Events: events}}}
+	}
+	tests := []struct {
+		name     string
+		affected OSVAffected
+		version  string
+		want     bool
+	}{
+		{"introduced zero, before fix", semver(map[string]string{"introduced": "0"}, map[string]string{"fixed": "1.2.0"}), "1.1.9", true},
+		{"at fixed version", semver(map[string]string{"introduced": "0"}, map[string]string{"fixed": "1.2.0"}), "1.2.0", false},
+		{"before introduced", semver(map[string]string{"introduced": "1.0.0"}, map[string]string{"fixed": "1.2.0"}), "0.9.0", false},
+		{"at introduced", semver(map[string]string{"introduced": "1.0.0"}, map[string]string{"fixed": "1.2.0"}), "1.0.0", true},
+		{"prerelease of fix", semver(map[string]string{"introduced": "0"}, map[string]string{"fixed": "2.0.0"}), "2.0.0-rc.1", true},
+		{"last affected inclusive", semver(map[string]string{"introduced": "0"}, map[string]string{"last_affected": "1.4.0"}), "1.4.0", true},
+		{"after last affected", semver(map[string]string{"introduced": "0"}, map[string]string{"last_affected": "1.4.0"}), "1.4.1", false},
+		{"second interval", semver(map[string]string{"introduced": "1.0.0"}, map[string]string{"fixed": "1.1.0"},
+			map[string]string{"introduced": "2.0.0"}, map[string]string{"fixed": "2.3.0"}), "2.1.0", true},
+		{"between intervals", semver(map[string]string{"introduced": "1.0.0"}, map[string]string{"fixed": "1.1.0"},
+			map[string]string{"introduced": "2.0.0"}, map[string]string{"fixed": "2.3.0"}), "1.5.0", false},
+		{"unsorted events", semver(map[string]string{"fixed": "1.2.0"}, map[string]string{"introduced": "1.0.0"}), "1.1.0", true},
+		{"limit", semver(map[string]string{"introduced": "0"}, map[string]string{"limit": "3.0.0"}), "3.0.0", false},
+		{"post-release of fix", OSVAffected{Ranges: []OSVRange{{Type: "ECOSYSTEM", Events: []map[string]string{
+			{"introduced": "0"}, {"fixed": "1.2.0"}}}}}, "1.2.0.post1", false},
+		{"explicit versions", OSVAffected{Versions: []string{"1.0.0", "1.0.1"}}, "v1.0.1", true},
+		{"git ranges ignored", OSVAffected{Ranges: []OSVRange{{Type: "GIT", Events: []map[string]string{{"introduced": "0"}}}}}, "1.0.0", false},
+	}
+	for _, test := range tests {
+		if got := osvAffectsVersion(test.affected, test.version); got != test.want {
+			t.Errorf("%s: osvAffectsVersion(%q) = %v, want %v", test.name, test.version, got, test.want)
+		}
+	}
+}
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		score  float64
+		ok     bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 5.5, true},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"CVSS:3.1/AV:N/AC:L", 0, false},
+	}
+	for _, test := range tests {
+		score, ok := cvss3BaseScore(test.vector)
+		if ok != test.ok || score != test.score {
+			t.Errorf("cvss3BaseScore(%q) = %.1f, %v, want %.1f, %v", test.vector, score, ok, test.score, test.ok)
+		}
+	}
+}
+
+func TestOSVSeverity(t *testing.T) {
+	tests := []struct {
+		entry OSVEntry
+		want  string
+	}{
+		{OSVEntry{DatabaseSpecific: map[string]interface{}{"severity": "moderate"}}, "MODERATE"},
+		{OSVEntry{Severity: []OSVSeverity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}}, "CRITICAL (9.8)"},
+		{OSVEntry{}, "UNKNOWN"},
+	}
+	for _, test := range tests {
+		if got := osvSeverity(test.entry); got != test.want {
+			t.Errorf("osvSeverity() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestNormalizePackageName(t *testing.T) {
+	tests := []struct {
+		ecosystem, name, want string
+	}{
+		{ecosystemPyPI, "Django_REST.framework", "django-rest-framework"},
+		{ecosystemPackagist, "Laravel/Framework", "laravel/framework"},
+		{ecosystemNpm, "@Scope/Pkg", "@Scope/Pkg"},
+	}
+	for _, test := range tests {
+		if got := normalizePackageName(test.ecosystem, test.name); got != test.want {
+			t.Errorf("normalizePackageName(%q, %q) = %q, want %q", test.ecosystem, test.name, got, test.want)
+		}
+	}
+}
+
+func TestVulnDBFlagScope(t *testing.T) {
+	for _, cmd := range []*cobra.Command{rootCmd, vulndbImportCmd, vulndbStatusCmd} {
+		if cmd.Flag("vulndb") == nil {
+			t.Errorf("%s has no --vulndb flag", cmd.Name())
+		}
+	}
+	for _, cmd := range []*cobra.Command{sbomCmd, depgraphCmd, outdatedCmd, secretsCmd, serveCmd} {
+		if cmd.Flag("vulndb") != nil {
+			t.Errorf("%s accepts --vulndb, which it ignores", cmd.Name())
+		}
+	}
+}
@@ -7,6 +7,7 @@ require (
 	github.com/fatih/color v1.17.0
 	github.com/labstack/echo/v4 v4.12.0
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/mod v0.20.0
 )
 
 require (